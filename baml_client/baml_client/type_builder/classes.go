@@ -15,6 +15,46 @@ package type_builder
 
 import baml "github.com/boundaryml/baml/engine/language_client_go/pkg"
 
+type InjectionVerdictClassView struct {
+	inner baml.ClassBuilder
+}
+
+func (t *InjectionVerdictClassView) ListProperties() ([]ClassPropertyView, error) {
+	result, err := t.inner.ListProperties()
+	if err != nil {
+		return nil, err
+	}
+	builders := make([]ClassPropertyView, len(result))
+	for i, p := range result {
+		builders[i] = p
+	}
+	return builders, nil
+}
+
+func (t *InjectionVerdictClassView) PropertySuspicious() (ClassPropertyView, error) {
+	return t.inner.Property("suspicious")
+}
+
+func (t *InjectionVerdictClassView) PropertyReason() (ClassPropertyView, error) {
+	return t.inner.Property("reason")
+}
+
+func (t *InjectionVerdictClassView) PropertyExcerpts() (ClassPropertyView, error) {
+	return t.inner.Property("excerpts")
+}
+
+func (t *TypeBuilder) InjectionVerdict() (*InjectionVerdictClassView, error) {
+	bld, err := t.inner.Class("InjectionVerdict")
+	if err != nil {
+		return nil, err
+	}
+	return &InjectionVerdictClassView{inner: bld}, nil
+}
+
+func (t *InjectionVerdictClassView) Type() (baml.Type, error) {
+	return t.inner.Type()
+}
+
 type KeyTermsClassView struct {
 	inner baml.ClassBuilder
 }
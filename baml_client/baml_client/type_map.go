@@ -21,8 +21,10 @@ import (
 )
 
 var typeMap = map[string]reflect.Type{
-	"TYPES.KeyTerms":             reflect.TypeOf(types.KeyTerms{}),
-	"STREAM_TYPES.KeyTerms":      reflect.TypeOf(stream_types.KeyTerms{}),
-	"TYPES.TweakAnalysis":        reflect.TypeOf(types.TweakAnalysis{}),
-	"STREAM_TYPES.TweakAnalysis": reflect.TypeOf(stream_types.TweakAnalysis{}),
+	"TYPES.InjectionVerdict":        reflect.TypeOf(types.InjectionVerdict{}),
+	"STREAM_TYPES.InjectionVerdict": reflect.TypeOf(stream_types.InjectionVerdict{}),
+	"TYPES.KeyTerms":                reflect.TypeOf(types.KeyTerms{}),
+	"STREAM_TYPES.KeyTerms":         reflect.TypeOf(stream_types.KeyTerms{}),
+	"TYPES.TweakAnalysis":           reflect.TypeOf(types.TweakAnalysis{}),
+	"STREAM_TYPES.TweakAnalysis":    reflect.TypeOf(stream_types.TweakAnalysis{}),
 }
@@ -20,6 +20,67 @@ import (
 	"github.com/boundaryml/baml/engine/language_client_go/pkg/cffi"
 )
 
+type InjectionVerdict struct {
+	Suspicious bool     `json:"suspicious"`
+	Reason     string   `json:"reason"`
+	Excerpts   []string `json:"excerpts"`
+}
+
+func (c *InjectionVerdict) Decode(holder *cffi.CFFIValueClass, typeMap baml.TypeMap) {
+	typeName := holder.Name
+	if typeName.Namespace != cffi.CFFITypeNamespace_TYPES {
+		panic(fmt.Sprintf("expected cffi.CFFITypeNamespace_TYPES, got %s", string(typeName.Namespace.String())))
+	}
+	if typeName.Name != "InjectionVerdict" {
+		panic(fmt.Sprintf("expected InjectionVerdict, got %s", typeName.Name))
+	}
+
+	for _, field := range holder.Fields {
+		key := field.Key
+		valueHolder := field.Value
+		switch key {
+
+		case "suspicious":
+			c.Suspicious = baml.Decode(valueHolder).Interface().(bool)
+
+		case "reason":
+			c.Reason = baml.Decode(valueHolder).Interface().(string)
+
+		case "excerpts":
+			c.Excerpts = baml.Decode(valueHolder).Interface().([]string)
+
+		default:
+
+			panic(fmt.Sprintf("unexpected field: %s in class InjectionVerdict", key))
+
+		}
+	}
+
+}
+
+func (c InjectionVerdict) Encode() (*cffi.CFFIValueHolder, error) {
+	fields := map[string]any{}
+
+	fields["suspicious"] = c.Suspicious
+
+	fields["reason"] = c.Reason
+
+	fields["excerpts"] = c.Excerpts
+
+	return baml.EncodeClass(c.BamlEncodeName, fields, nil)
+}
+
+func (c InjectionVerdict) BamlTypeName() string {
+	return "InjectionVerdict"
+}
+
+func (u InjectionVerdict) BamlEncodeName() *cffi.CFFITypeName {
+	return &cffi.CFFITypeName{
+		Namespace: cffi.CFFITypeNamespace_TYPES,
+		Name:      "InjectionVerdict",
+	}
+}
+
 type KeyTerms struct {
 	Technical_skills []string `json:"technical_skills"`
 	Soft_skills      []string `json:"soft_skills"`
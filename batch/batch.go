@@ -0,0 +1,417 @@
+// Package batch tweaks one base resume against many job descriptions in the
+// background.
+//
+// A batch is a tweak_batches record holding the base resume, with one
+// tweak_batch_items record per job description. Run works through the items
+// a few at a time; each becomes an ordinary tweak job whose result is saved
+// to resumes, and the item links to it. Watchers are woken on every item
+// change (see Watch), so a progress page can re-render as items finish.
+package batch
+
+import (
+	"errors"
+	"log"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Collections holding batches and their items
+const (
+	Collection     = "tweak_batches"
+	ItemCollection = "tweak_batch_items"
+)
+
+// Batch statuses. A finished batch is done when every item succeeded,
+// failed when none did, and partial otherwise.
+const (
+	StatusRunning     = "running"
+	StatusDone        = "done"
+	StatusFailed      = "failed"
+	StatusPartial     = "partial"
+	StatusInterrupted = "interrupted"
+)
+
+// statuses are the values of the batch status field
+var statuses = []string{StatusRunning, StatusDone, StatusFailed, StatusPartial, StatusInterrupted}
+
+// Item statuses
+const (
+	ItemQueued      = "queued"
+	ItemRunning     = "running"
+	ItemDone        = "done"
+	ItemFailed      = "failed"
+	ItemInterrupted = "interrupted"
+)
+
+// maxTextChars bounds a stored resume or job description
+const maxTextChars = 200000
+
+// ErrNotFound is returned for unknown batches and batches owned by another
+// user
+var ErrNotFound = errors.New("batch not found")
+
+// Batch is one base resume tweaked against several job descriptions
+type Batch struct {
+	ID      string
+	UserID  string
+	Name    string
+	Resume  string
+	Status  string
+	Created time.Time
+	Items   []Item
+}
+
+// Item is one job description in a batch
+type Item struct {
+	ID       string
+	Position int
+	Entry
+	Status string
+	// Note says what a running item is doing
+	Note string
+	// Error is a user-facing message for a failed item
+	Error string
+	// JobID and ResumeID link the tweak job and its saved resumes record
+	JobID    string
+	ResumeID string
+}
+
+// Finished reports whether the item will not change any more
+func (it Item) Finished() bool {
+	return it.Status == ItemDone || it.Status == ItemFailed || it.Status == ItemInterrupted
+}
+
+// Counts tallies a batch's items by status
+type Counts struct {
+	Total, Done, Failed, Pending int
+}
+
+// Counts tallies the batch's items
+func (b Batch) Counts() Counts {
+	c := Counts{Total: len(b.Items)}
+	for _, it := range b.Items {
+		switch {
+		case it.Status == ItemDone:
+			c.Done++
+		case it.Finished():
+			c.Failed++
+		default:
+			c.Pending++
+		}
+	}
+	return c
+}
+
+// Create stores a new running batch with its items queued, in order
+func Create(app core.App, userID, name, resume string, entries []Entry) (Batch, error) {
+	if len(entries) == 0 {
+		return Batch{}, ErrNoEntries
+	}
+	collection, err := app.FindCollectionByNameOrId(Collection)
+	if err != nil {
+		return Batch{}, err
+	}
+	itemCollection, err := app.FindCollectionByNameOrId(ItemCollection)
+	if err != nil {
+		return Batch{}, err
+	}
+
+	var b Batch
+	err = app.RunInTransaction(func(tx core.App) error {
+		record := core.NewRecord(collection)
+		record.Set("user", userID)
+		record.Set("name", name)
+		record.Set("resume", resume)
+		record.Set("status", StatusRunning)
+		if err := tx.Save(record); err != nil {
+			return err
+		}
+		b = fromRecord(record)
+
+		for i, entry := range entries {
+			item := core.NewRecord(itemCollection)
+			item.Set("batch", record.Id)
+			item.Set("user", userID)
+			item.Set("position", i+1)
+			item.Set("status", ItemQueued)
+			setEntry(item, entry)
+			if err := tx.Save(item); err != nil {
+				return err
+			}
+			b.Items = append(b.Items, itemFromRecord(item))
+		}
+		return nil
+	})
+	if err != nil {
+		return Batch{}, err
+	}
+	return b, nil
+}
+
+// Requeue queues a batch's failed and interrupted items again and marks the
+// batch running, so they count as awaiting jobs before Run starts them
+func Requeue(app core.App, b Batch) (Batch, error) {
+	err := app.RunInTransaction(func(tx core.App) error {
+		for i, it := range b.Items {
+			if it.Status == ItemDone || !it.Finished() {
+				continue
+			}
+			it.Status, it.Note, it.Error = ItemQueued, "", ""
+			if err := saveItem(tx, it); err != nil {
+				return err
+			}
+			b.Items[i] = it
+		}
+		record, err := tx.FindRecordById(Collection, b.ID)
+		if err != nil {
+			return err
+		}
+		record.Set("status", StatusRunning)
+		return tx.Save(record)
+	})
+	if err != nil {
+		return Batch{}, err
+	}
+	b.Status = StatusRunning
+	return b, nil
+}
+
+// Get loads one of a user's batches with its items
+func Get(app core.App, userID, id string) (Batch, error) {
+	record, err := app.FindRecordById(Collection, id)
+	if err != nil || record.GetString("user") != userID {
+		return Batch{}, ErrNotFound
+	}
+	b := fromRecord(record)
+
+	items, err := app.FindRecordsByFilter(ItemCollection, "batch = {:batch}", "position", 0, 0,
+		dbx.Params{"batch": id})
+	if err != nil {
+		return Batch{}, err
+	}
+	for _, r := range items {
+		b.Items = append(b.Items, itemFromRecord(r))
+	}
+	return b, nil
+}
+
+// AwaitingJobs counts a user's items, across all their batches, that
+// haven't started their tweak job yet: queued ones, and running ones still
+// fetching their posting
+func AwaitingJobs(app core.App, userID string) (int, error) {
+	var n int
+	err := app.DB().Select("count(*)").From(ItemCollection).
+		Where(dbx.HashExp{"user": userID}).
+		AndWhere(dbx.Or(
+			dbx.HashExp{"status": ItemQueued},
+			dbx.HashExp{"status": ItemRunning, "job_id": ""},
+		)).
+		Row(&n)
+	return n, err
+}
+
+// List returns a user's batches, newest first, without their items
+func List(app core.App, userID string, limit int) ([]Batch, error) {
+	records, err := app.FindRecordsByFilter(Collection, "user = {:user}", "-created", limit, 0,
+		dbx.Params{"user": userID})
+	if err != nil {
+		return nil, err
+	}
+	batches := make([]Batch, 0, len(records))
+	for _, r := range records {
+		batches = append(batches, fromRecord(r))
+	}
+	return batches, nil
+}
+
+func fromRecord(r *core.Record) Batch {
+	return Batch{
+		ID:      r.Id,
+		UserID:  r.GetString("user"),
+		Name:    r.GetString("name"),
+		Resume:  r.GetString("resume"),
+		Status:  r.GetString("status"),
+		Created: r.GetDateTime("created").Time(),
+	}
+}
+
+func itemFromRecord(r *core.Record) Item {
+	return Item{
+		ID:       r.Id,
+		Position: r.GetInt("position"),
+		Entry: Entry{
+			Title:          r.GetString("title"),
+			Company:        r.GetString("company"),
+			URL:            r.GetString("url"),
+			JobDescription: r.GetString("job_description"),
+		},
+		Status:   r.GetString("status"),
+		Note:     r.GetString("note"),
+		Error:    r.GetString("error"),
+		JobID:    r.GetString("job_id"),
+		ResumeID: r.GetString("resume_id"),
+	}
+}
+
+func setEntry(r *core.Record, e Entry) {
+	description := e.JobDescription
+	if utf8.RuneCountInString(description) > maxTextChars {
+		description = string([]rune(description)[:maxTextChars])
+	}
+	r.Set("title", e.Title)
+	r.Set("company", e.Company)
+	r.Set("url", e.URL)
+	r.Set("job_description", description)
+}
+
+// saveItem writes an item's progress to its record
+func saveItem(app core.App, it Item) error {
+	record, err := app.FindRecordById(ItemCollection, it.ID)
+	if err != nil {
+		return err
+	}
+	setEntry(record, it.Entry)
+	record.Set("status", it.Status)
+	record.Set("note", it.Note)
+	record.Set("error", it.Error)
+	record.Set("job_id", it.JobID)
+	record.Set("resume_id", it.ResumeID)
+	return app.Save(record)
+}
+
+// setStatus writes a batch's status
+func setStatus(app core.App, id, status string) {
+	record, err := app.FindRecordById(Collection, id)
+	if err == nil {
+		record.Set("status", status)
+		err = app.Save(record)
+	}
+	if err != nil {
+		log.Printf("[Batch] Warning: failed to mark batch %s %s: %v", id, status, err)
+	}
+}
+
+// SetupCollections creates the batch collections if they don't exist,
+// adding statuses introduced since, and marks batches left running by a
+// previous process as interrupted
+func SetupCollections(app core.App) error {
+	if collection, err := app.FindCollectionByNameOrId(Collection); err != nil {
+		if err := createCollections(app); err != nil {
+			return err
+		}
+	} else if status, ok := collection.Fields.GetByName("status").(*core.SelectField); ok && !slices.Equal(status.Values, statuses) {
+		log.Printf("[Setup] Updating %s statuses...", Collection)
+		status.Values = slices.Clone(statuses)
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+	}
+
+	// Nothing survives a restart, so anything unfinished was cut off
+	if _, err := app.DB().Update(ItemCollection,
+		dbx.Params{"status": ItemInterrupted, "note": ""},
+		dbx.In("status", ItemQueued, ItemRunning),
+	).Execute(); err != nil {
+		return err
+	}
+	_, err := app.DB().Update(Collection,
+		dbx.Params{"status": StatusInterrupted},
+		dbx.HashExp{"status": StatusRunning},
+	).Execute()
+	return err
+}
+
+func createCollections(app core.App) error {
+	log.Printf("[Setup] Creating %s and %s collections...", Collection, ItemCollection)
+
+	usersCollection, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		return err
+	}
+	userField := func() *core.RelationField {
+		return &core.RelationField{
+			Name:          "user",
+			Required:      true,
+			CollectionId:  usersCollection.Id,
+			MaxSelect:     1,
+			CascadeDelete: true,
+		}
+	}
+	ownRule := types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+
+	collection := core.NewBaseCollection(Collection)
+	collection.Fields.Add(userField())
+	collection.Fields.Add(&core.TextField{Name: "name"})
+	collection.Fields.Add(&core.TextField{
+		Name:     "resume",
+		Required: true,
+		Max:      maxTextChars,
+	})
+	collection.Fields.Add(&core.SelectField{
+		Name:      "status",
+		Required:  true,
+		MaxSelect: 1,
+		Values:    slices.Clone(statuses),
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		OnCreate: true,
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	collection.AddIndex("idx_tweak_batches_user_created", false, "user, created", "")
+
+	// Users can read and remove their own batches; only the server writes them
+	collection.ListRule = ownRule
+	collection.ViewRule = ownRule
+	collection.DeleteRule = ownRule
+	if err := app.Save(collection); err != nil {
+		return err
+	}
+
+	items := core.NewBaseCollection(ItemCollection)
+	items.Fields.Add(&core.RelationField{
+		Name:          "batch",
+		Required:      true,
+		CollectionId:  collection.Id,
+		MaxSelect:     1,
+		CascadeDelete: true,
+	})
+	items.Fields.Add(userField())
+	items.Fields.Add(&core.NumberField{Name: "position", OnlyInt: true})
+	for _, name := range []string{"title", "company", "url", "note", "error", "job_id", "resume_id"} {
+		items.Fields.Add(&core.TextField{Name: name})
+	}
+	items.Fields.Add(&core.TextField{
+		Name: "job_description",
+		Max:  maxTextChars,
+	})
+	items.Fields.Add(&core.SelectField{
+		Name:      "status",
+		Required:  true,
+		MaxSelect: 1,
+		Values:    []string{ItemQueued, ItemRunning, ItemDone, ItemFailed, ItemInterrupted},
+	})
+	items.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		OnCreate: true,
+	})
+	items.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	items.AddIndex("idx_tweak_batch_items_batch", false, "batch, position", "")
+
+	items.ListRule = ownRule
+	items.ViewRule = ownRule
+	return app.Save(items)
+}
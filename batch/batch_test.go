@@ -0,0 +1,81 @@
+package batch
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/johnhkchen/resume-tweaker/testapp"
+)
+
+func TestCreateCutsLongDescriptionsOnRunes(t *testing.T) {
+	app := testapp.New(t, SetupCollections)
+	userID := testapp.User(t, app).Id
+
+	// One ASCII byte first, so a cut by bytes lands inside an "é"
+	long := "x" + strings.Repeat("é", maxTextChars)
+	b, err := Create(app, userID, "Long", "A base resume", []Entry{{JobDescription: long}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = Get(app, userID, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := b.Items[0].JobDescription
+	if !utf8.ValidString(got) {
+		t.Fatal("stored description isn't valid UTF-8")
+	}
+	if n := utf8.RuneCountInString(got); n != maxTextChars {
+		t.Fatalf("stored description has %d characters, want %d", n, maxTextChars)
+	}
+	if !strings.HasPrefix(long, got) {
+		t.Fatal("stored description isn't the start of the original")
+	}
+}
+
+func TestRequeue(t *testing.T) {
+	app := testapp.New(t, SetupCollections)
+	userID := testapp.User(t, app).Id
+	b := createBatch(t, app, userID, 4)
+
+	// One of each finished status, and one still queued
+	for i, status := range []string{ItemDone, ItemFailed, ItemInterrupted} {
+		it := b.Items[i]
+		it.Status, it.Error = status, "it broke"
+		if err := saveItem(app, it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setStatus(app, b.ID, StatusPartial)
+	b, err := Get(app, userID, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = Requeue(app, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := Get(app, userID, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range []Batch{b, stored} {
+		if got.Status != StatusRunning {
+			t.Errorf("batch status %q, want running", got.Status)
+		}
+		var statuses []string
+		for _, it := range got.Items {
+			statuses = append(statuses, it.Status)
+			if it.Status == ItemQueued && it.Error != "" {
+				t.Errorf("requeued item %d kept its error", it.Position)
+			}
+		}
+		if want := []string{ItemDone, ItemQueued, ItemQueued, ItemQueued}; !slices.Equal(statuses, want) {
+			t.Errorf("item statuses %v, want %v", statuses, want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrNoEntries is returned when the input has no job descriptions in it
+	ErrNoEntries = errors.New("no job descriptions")
+	// ErrNoColumns is returned for a CSV without a description or URL column
+	ErrNoColumns = errors.New("CSV has no description or URL column")
+)
+
+// Entry is a job description to tweak against. An entry with only a URL
+// has its posting fetched when its turn comes.
+type Entry struct {
+	Title          string
+	Company        string
+	URL            string
+	JobDescription string
+}
+
+// Name is a short label for the entry, e.g. "Backend Engineer at Acme"
+func (e Entry) Name() string {
+	switch {
+	case e.Title != "" && e.Company != "":
+		return e.Title + " at " + e.Company
+	case e.Title != "":
+		return e.Title
+	case e.Company != "":
+		return e.Company
+	default:
+		return e.URL
+	}
+}
+
+// csvColumns maps each entry field to the header names accepted for it,
+// lower-cased
+var csvColumns = map[string][]string{
+	"title":       {"title", "job title", "job_title", "role", "position"},
+	"company":     {"company", "company name", "company_name", "employer", "organization"},
+	"url":         {"url", "link", "job url", "job_url", "posting url", "posting_url"},
+	"description": {"description", "job description", "job_description", "jd", "text"},
+}
+
+// ParseCSV reads job descriptions from a CSV with a header row. Columns are
+// matched by name (title, company, url, description and common variants);
+// rows with neither a description nor a URL are skipped.
+func ParseCSV(data []byte) ([]Entry, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrNoEntries
+	}
+
+	index := map[string]int{}
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for field, names := range csvColumns {
+			if _, seen := index[field]; !seen && contains(names, name) {
+				index[field] = i
+			}
+		}
+	}
+	_, hasDescription := index["description"]
+	_, hasURL := index["url"]
+	if !hasDescription && !hasURL {
+		return nil, ErrNoColumns
+	}
+
+	var entries []Entry
+	for _, rec := range records[1:] {
+		get := func(field string) string {
+			if i, ok := index[field]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		e := Entry{
+			Title:          get("title"),
+			Company:        get("company"),
+			URL:            get("url"),
+			JobDescription: get("description"),
+		}
+		if e.JobDescription == "" && e.URL == "" {
+			continue
+		}
+		if e.Title == "" {
+			e.Title = guessTitle(e.JobDescription)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) == 0 {
+		return nil, ErrNoEntries
+	}
+	return entries, nil
+}
+
+// separatorRe matches a line of three or more dashes or equals signs
+var separatorRe = regexp.MustCompile(`(?m)^[ \t]*(?:-{3,}|={3,})[ \t]*$`)
+
+// SplitPasted splits pasted text into job descriptions at separator lines
+// ("---" or "===")
+func SplitPasted(text string) []Entry {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var entries []Entry
+	for _, part := range separatorRe.Split(text, -1) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		entries = append(entries, Entry{Title: guessTitle(part), JobDescription: part})
+	}
+	return entries
+}
+
+// maxTitleChars caps a title taken from a description's first line
+const maxTitleChars = 100
+
+// guessTitle takes the first line of a description as its title
+func guessTitle(description string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(description), "\n")
+	line = strings.TrimSpace(strings.TrimLeft(line, "#*- \t"))
+	line = strings.TrimRight(line, "*: ")
+	if utf8.RuneCountInString(line) > maxTitleChars {
+		runes := []rune(line)
+		line = strings.TrimSpace(string(runes[:maxTitleChars])) + "…"
+	}
+	return line
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
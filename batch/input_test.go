@@ -0,0 +1,142 @@
+package batch
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want []Entry
+	}{
+		{
+			name: "named columns in any order",
+			csv:  "Company,Job Title,Description,URL\nAcme,Backend Engineer,Build the billing platform.,https://acme.example/jobs/1\n",
+			want: []Entry{{Title: "Backend Engineer", Company: "Acme", URL: "https://acme.example/jobs/1", JobDescription: "Build the billing platform."}},
+		},
+		{
+			name: "byte order mark",
+			csv:  "\ufefftitle,description\nSRE,Keep our systems running.\n",
+			want: []Entry{{Title: "SRE", JobDescription: "Keep our systems running."}},
+		},
+		{
+			name: "quoted newlines",
+			csv:  "description,company\n\"Staff Engineer\n\nLead the platform team.\nWork with Go.\",Initech\n",
+			want: []Entry{{Title: "Staff Engineer", Company: "Initech", JobDescription: "Staff Engineer\n\nLead the platform team.\nWork with Go."}},
+		},
+		{
+			name: "blank rows",
+			csv:  "title,description\n\nFirst,One description\n,\n  ,  \nSecond,Another description\n\n",
+			want: []Entry{
+				{Title: "First", JobDescription: "One description"},
+				{Title: "Second", JobDescription: "Another description"},
+			},
+		},
+		{
+			name: "CRLF and short rows",
+			csv:  "url,title,company\r\nhttps://jobs.example/1\r\nhttps://jobs.example/2,Recruiter,Acme\r\n",
+			want: []Entry{
+				{URL: "https://jobs.example/1"},
+				{Title: "Recruiter", Company: "Acme", URL: "https://jobs.example/2"},
+			},
+		},
+		{
+			name: "first matching column wins",
+			csv:  "text,description\nFrom text,From description\n",
+			want: []Entry{{Title: "From text", JobDescription: "From text"}},
+		},
+		{
+			name: "stray quote",
+			csv:  "title,description\nEngineer,Work on \"fast\" systems\n",
+			want: []Entry{{Title: "Engineer", JobDescription: "Work on \"fast\" systems"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCSV([]byte(tt.csv))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCSV =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want error
+	}{
+		{"empty", "", ErrNoEntries},
+		{"only a BOM", "\ufeff", ErrNoEntries},
+		{"header only", "title,description\n", ErrNoEntries},
+		{"only blank rows", "title,url\n,\n\n", ErrNoEntries},
+		{"no description or url column", "title,company\nEngineer,Acme\n", ErrNoColumns},
+	}
+	for _, tt := range tests {
+		if _, err := ParseCSV([]byte(tt.csv)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: ParseCSV error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSplitPasted(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Entry
+	}{
+		{"empty", "", nil},
+		{"only separators", "---\n\n===\n", nil},
+		{
+			name: "one description",
+			text: "Backend Engineer\nBuild things.",
+			want: []Entry{{Title: "Backend Engineer", JobDescription: "Backend Engineer\nBuild things."}},
+		},
+		{
+			name: "separators with blank parts",
+			text: "---\nFirst\n\n---\n\n---\n  ====  \nSecond\n---",
+			want: []Entry{
+				{Title: "First", JobDescription: "First"},
+				{Title: "Second", JobDescription: "Second"},
+			},
+		},
+		{
+			name: "CRLF",
+			text: "First\r\nline two\r\n---\r\nSecond\r\n",
+			want: []Entry{
+				{Title: "First", JobDescription: "First\nline two"},
+				{Title: "Second", JobDescription: "Second"},
+			},
+		},
+		{
+			name: "dashes inside a line don't split",
+			text: "Engineer -- remote\n-- not a separator\n--\nStill the same",
+			want: []Entry{{Title: "Engineer -- remote", JobDescription: "Engineer -- remote\n-- not a separator\n--\nStill the same"}},
+		},
+		{
+			name: "markdown title",
+			text: "## **Senior Designer:**\nDesign things.",
+			want: []Entry{{Title: "Senior Designer", JobDescription: "## **Senior Designer:**\nDesign things."}},
+		},
+	}
+	for _, tt := range tests {
+		if got := SplitPasted(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: SplitPasted =\n%+v\nwant\n%+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGuessTitleTruncates(t *testing.T) {
+	got := guessTitle(strings.Repeat("word ", 30) + "\nrest")
+	if []rune(got)[len([]rune(got))-1] != '…' || len([]rune(got)) > maxTitleChars+1 {
+		t.Errorf("guessTitle = %q, want at most %d characters and an ellipsis", got, maxTitleChars)
+	}
+}
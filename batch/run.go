@@ -0,0 +1,171 @@
+package batch
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// TweakFunc tweaks resume against one item, setting the item's JobID and
+// ResumeID. It may fill in the item's description and title, e.g. after
+// fetching its posting, and report what it is doing through note.
+type TweakFunc func(ctx context.Context, resume string, item *Item, note func(string)) error
+
+// MessageFunc turns a TweakFunc's error into the message shown to the user
+type MessageFunc func(error) string
+
+// watchers holds, per batch, a channel closed on the batch's next change
+var watchers = struct {
+	sync.Mutex
+	changed map[string]chan struct{}
+	// running is the set of batches with a Run in progress
+	running map[string]bool
+}{changed: map[string]chan struct{}{}, running: map[string]bool{}}
+
+// Watch returns a channel that is closed the next time an item of batch id
+// changes, or already closed if the batch isn't running. Take it before
+// loading the batch so no change is missed.
+func Watch(id string) <-chan struct{} {
+	watchers.Lock()
+	defer watchers.Unlock()
+	if !watchers.running[id] {
+		ch := make(chan struct{})
+		close(ch)
+		return ch
+	}
+	ch, ok := watchers.changed[id]
+	if !ok {
+		ch = make(chan struct{})
+		watchers.changed[id] = ch
+	}
+	return ch
+}
+
+// Running reports whether batch id is being worked through by this process
+func Running(id string) bool {
+	watchers.Lock()
+	defer watchers.Unlock()
+	return watchers.running[id]
+}
+
+// notify wakes everyone watching batch id
+func notify(id string) {
+	watchers.Lock()
+	defer watchers.Unlock()
+	if ch, ok := watchers.changed[id]; ok {
+		close(ch)
+		delete(watchers.changed, id)
+	}
+}
+
+// Run works through the batch's unfinished items in the background, at most
+// concurrency at a time, and sets the batch's final status once they are
+// all finished. Items run in order of position; a failed item's Error is
+// message of its error. It reports false if the batch is already running.
+func Run(app core.App, b Batch, concurrency int, tweak TweakFunc, message MessageFunc) bool {
+	watchers.Lock()
+	if watchers.running[b.ID] {
+		watchers.Unlock()
+		return false
+	}
+	watchers.running[b.ID] = true
+	watchers.Unlock()
+
+	var pending []Item
+	for _, it := range b.Items {
+		if it.Status != ItemDone {
+			it.Status, it.Note, it.Error = ItemQueued, "", ""
+			pending = append(pending, it)
+		}
+	}
+	if b.Status != StatusRunning {
+		setStatus(app, b.ID, StatusRunning)
+	}
+	for _, it := range pending {
+		if err := saveItem(app, it); err != nil {
+			log.Printf("[Batch] Warning: failed to queue item %s: %v", it.ID, err)
+		}
+	}
+	notify(b.ID)
+
+	go func() {
+		// Items done before this run count towards the outcome
+		var mu sync.Mutex
+		done, failed := len(b.Items)-len(pending), 0
+		defer func() {
+			setStatus(app, b.ID, finalStatus(done, failed))
+			watchers.Lock()
+			delete(watchers.running, b.ID)
+			watchers.Unlock()
+			notify(b.ID)
+		}()
+
+		queue := make(chan Item)
+		var wg sync.WaitGroup
+		for range max(concurrency, 1) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for it := range queue {
+					ok := runItem(app, b, it, tweak, message)
+					mu.Lock()
+					if ok {
+						done++
+					} else {
+						failed++
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+		for _, it := range pending {
+			queue <- it
+		}
+		close(queue)
+		wg.Wait()
+	}()
+	return true
+}
+
+// finalStatus is a finished batch's status given how many items succeeded
+// and failed
+func finalStatus(done, failed int) string {
+	switch {
+	case failed == 0:
+		return StatusDone
+	case done == 0:
+		return StatusFailed
+	default:
+		return StatusPartial
+	}
+}
+
+// runItem tweaks one item, recording its progress and outcome, and reports
+// whether it succeeded
+func runItem(app core.App, b Batch, it Item, tweak TweakFunc, message MessageFunc) bool {
+	update := func() {
+		if err := saveItem(app, it); err != nil {
+			log.Printf("[Batch] Warning: failed to save item %s: %v", it.ID, err)
+		}
+		notify(b.ID)
+	}
+
+	it.Status = ItemRunning
+	update()
+
+	err := tweak(context.Background(), b.Resume, &it, func(note string) {
+		it.Note = note
+		update()
+	})
+	it.Note = ""
+	if err != nil {
+		log.Printf("[Batch] Item %s of batch %s failed: %v", it.ID, b.ID, err)
+		it.Status, it.Error = ItemFailed, message(err)
+	} else {
+		it.Status = ItemDone
+	}
+	update()
+	return err == nil
+}
@@ -0,0 +1,305 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/johnhkchen/resume-tweaker/testapp"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// createBatch creates a batch of n job descriptions
+func createBatch(t *testing.T, app core.App, userID string, n int) Batch {
+	t.Helper()
+	var entries []Entry
+	for i := range n {
+		entries = append(entries, Entry{Title: fmt.Sprintf("Job %d", i+1), JobDescription: "A job description"})
+	}
+	b, err := Create(app, userID, "Test batch", "A base resume", entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// wait blocks until Run has finished batch id
+func wait(t *testing.T, id string) {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for Running(id) {
+		select {
+		case <-Watch(id):
+		case <-timeout:
+			t.Fatal("batch still running")
+		}
+	}
+}
+
+func message(err error) string {
+	return "Shown: " + err.Error()
+}
+
+func TestRunRespectsConcurrency(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	app := testapp.New(t, SetupCollections)
+	userID := testapp.User(t, app).Id
+	b := createBatch(t, app, userID, 7)
+
+	const concurrency = 3
+	var mu sync.Mutex
+	active, peak := 0, 0
+	var order []int
+	release := make(chan struct{})
+	tweak := func(ctx context.Context, resume string, it *Item, note func(string)) error {
+		mu.Lock()
+		active++
+		peak = max(peak, active)
+		order = append(order, it.Position)
+		mu.Unlock()
+
+		<-release
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		it.JobID, it.ResumeID = "job"+it.ID, "resume"+it.ID
+		return nil
+	}
+	if !Run(app, b, concurrency, tweak, message) {
+		t.Fatal("Run refused a new batch")
+	}
+	if Run(app, b, concurrency, tweak, message) {
+		t.Fatal("Run started a batch that is already running")
+	}
+
+	// Let the workers fill up before releasing them one at a time
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := active
+		mu.Unlock()
+		if n == concurrency {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d items running, want %d", n, concurrency)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	for range len(b.Items) {
+		release <- struct{}{}
+	}
+	wait(t, b.ID)
+
+	if peak != concurrency {
+		t.Errorf("at most %d items ran at once, want %d", peak, concurrency)
+	}
+	// Workers take items in order of position
+	if order[0] > concurrency || order[len(order)-1] <= len(order)-concurrency {
+		t.Errorf("items ran in order %v", order)
+	}
+
+	got, err := Get(app, userID, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusDone {
+		t.Errorf("batch status = %q, want %q", got.Status, StatusDone)
+	}
+	for _, it := range got.Items {
+		if it.Status != ItemDone || it.ResumeID != "resume"+it.ID {
+			t.Errorf("item %d = %+v, want done with its resume", it.Position, it)
+		}
+	}
+}
+
+func TestRunRequeuesUnfinishedItems(t *testing.T) {
+	app := testapp.New(t, SetupCollections)
+	userID := testapp.User(t, app).Id
+	b := createBatch(t, app, userID, 4)
+
+	// A batch cut off part way: one item done, one failed, one interrupted
+	// while running, one never started
+	b.Items[0].Status, b.Items[0].ResumeID = ItemDone, "kept"
+	b.Items[1].Status, b.Items[1].Error = ItemFailed, "Shown: old failure"
+	b.Items[2].Status, b.Items[2].Note = ItemInterrupted, "Tailoring the resume"
+	for _, it := range b.Items[:3] {
+		if err := saveItem(app, it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setStatus(app, b.ID, StatusInterrupted)
+	b.Status = StatusInterrupted
+
+	var mu sync.Mutex
+	var ran []int
+	tweak := func(ctx context.Context, resume string, it *Item, note func(string)) error {
+		mu.Lock()
+		ran = append(ran, it.Position)
+		mu.Unlock()
+		if it.Error != "" || it.Note != "" {
+			t.Errorf("item %d not reset before running: %+v", it.Position, it)
+		}
+		if resume != "A base resume" {
+			t.Errorf("tweaked resume %q", resume)
+		}
+		note("Working on it")
+		it.ResumeID = "new"
+		return nil
+	}
+	if !Run(app, b, 1, tweak, message) {
+		t.Fatal("Run refused an interrupted batch")
+	}
+	wait(t, b.ID)
+
+	if !slices.Equal(ran, []int{2, 3, 4}) {
+		t.Errorf("ran items %v, want 2, 3 and 4", ran)
+	}
+	got, err := Get(app, userID, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusDone {
+		t.Errorf("batch status = %q, want %q", got.Status, StatusDone)
+	}
+	if it := got.Items[0]; it.Status != ItemDone || it.ResumeID != "kept" {
+		t.Errorf("done item was rerun: %+v", it)
+	}
+	for _, it := range got.Items[1:] {
+		if it.Status != ItemDone || it.ResumeID != "new" || it.Error != "" || it.Note != "" {
+			t.Errorf("item %d = %+v, want done again", it.Position, it)
+		}
+	}
+}
+
+func TestRunFinalStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		fail   []bool
+		status string
+	}{
+		{"all succeed", []bool{false, false}, StatusDone},
+		{"all fail", []bool{true, true}, StatusFailed},
+		{"some fail", []bool{false, true}, StatusPartial},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := testapp.New(t, SetupCollections)
+			userID := testapp.User(t, app).Id
+			b := createBatch(t, app, userID, len(tt.fail))
+			tweak := func(ctx context.Context, resume string, it *Item, note func(string)) error {
+				if tt.fail[it.Position-1] {
+					return errors.New("posting not found")
+				}
+				return nil
+			}
+			Run(app, b, 2, tweak, message)
+			wait(t, b.ID)
+
+			got, err := Get(app, userID, b.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.status {
+				t.Errorf("batch status = %q, want %q", got.Status, tt.status)
+			}
+			for i, it := range got.Items {
+				if tt.fail[i] && (it.Status != ItemFailed || it.Error != "Shown: posting not found") {
+					t.Errorf("failed item = %+v, want its message", it)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryAfterFailureIsPartial(t *testing.T) {
+	app := testapp.New(t, SetupCollections)
+	userID := testapp.User(t, app).Id
+	b := createBatch(t, app, userID, 2)
+	b.Items[0].Status = ItemDone
+	if err := saveItem(app, b.Items[0]); err != nil {
+		t.Fatal(err)
+	}
+	Run(app, b, 1, func(context.Context, string, *Item, func(string)) error {
+		return errors.New("still broken")
+	}, message)
+	wait(t, b.ID)
+
+	got, _ := Get(app, userID, b.ID)
+	if got.Status != StatusPartial {
+		t.Errorf("batch status = %q, want %q", got.Status, StatusPartial)
+	}
+}
+
+func TestAwaitingJobs(t *testing.T) {
+	app := testapp.New(t, SetupCollections)
+	userID := testapp.User(t, app).Id
+	first := createBatch(t, app, userID, 3)
+	createBatch(t, app, userID, 2)
+
+	first.Items[0].Status = ItemDone
+	first.Items[1].Status = ItemRunning // still fetching its posting
+	first.Items[2].Status, first.Items[2].JobID = ItemRunning, "job"
+	for _, it := range first.Items {
+		if err := saveItem(app, it); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := AwaitingJobs(app, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("AwaitingJobs = %d, want 3", n)
+	}
+}
+
+func TestSetupUpdatesStatuses(t *testing.T) {
+	app := testapp.New(t, SetupCollections)
+	userID := testapp.User(t, app).Id
+	b := createBatch(t, app, userID, 1)
+
+	// A collection from before the failed and partial statuses
+	collection, _ := app.FindCollectionByNameOrId(Collection)
+	collection.Fields.GetByName("status").(*core.SelectField).Values = []string{StatusRunning, StatusDone, StatusInterrupted}
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetupCollections(app); err != nil {
+		t.Fatal(err)
+	}
+	setStatus(app, b.ID, StatusPartial)
+	got, err := Get(app, userID, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusPartial {
+		t.Errorf("status = %q after setup, want %q", got.Status, StatusPartial)
+	}
+}
+
+func TestSetupInterruptsRunningBatches(t *testing.T) {
+	app := testapp.New(t, SetupCollections)
+	userID := testapp.User(t, app).Id
+	b := createBatch(t, app, userID, 2)
+	b.Items[0].Status = ItemDone
+	if err := saveItem(app, b.Items[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetupCollections(app); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := Get(app, userID, b.ID)
+	if got.Status != StatusInterrupted || got.Items[0].Status != ItemDone || got.Items[1].Status != ItemInterrupted {
+		t.Errorf("after restart: batch %q, items %q and %q", got.Status, got.Items[0].Status, got.Items[1].Status)
+	}
+}
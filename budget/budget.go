@@ -0,0 +1,226 @@
+// Package budget keeps LLM inputs within a token budget.
+//
+// Token counts are estimated per model from character and word counts,
+// which is close enough to decide what to trim without calling a tokenizer.
+// When the inputs are over budget, Fit applies progressively more
+// aggressive reductions and reports every one of them so the user knows
+// exactly what the model did not see.
+package budget
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultMaxInputTokens is the combined resume + job description budget
+const defaultMaxInputTokens = 12000
+
+// charsPerToken is the average characters per token for each BAML client.
+// Claude models average ~3.5 characters per token on English prose.
+var charsPerToken = map[string]float64{
+	"ClaudeSonnet": 3.5,
+	"ClaudeHaiku":  3.5,
+}
+
+// Trim describes one reduction applied to an input
+type Trim struct {
+	// Source is "resume" or "job_description"
+	Source      string `json:"source"`
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	TokensSaved int    `json:"tokens_saved"`
+}
+
+// Kinds of trims, in the order they are applied. An older role is
+// summarized when a Summarizer is given and succeeds, and cut otherwise.
+const (
+	KindWhitespace  = "whitespace"
+	KindBoilerplate = "boilerplate"
+	KindSummarized  = "summarized_experience"
+	KindCutRole     = "cut_experience"
+	KindTruncated   = "truncated"
+)
+
+// Result is the outcome of fitting inputs into the budget
+type Result struct {
+	Resume         string `json:"-"`
+	JobDescription string `json:"-"`
+	Tokens         int    `json:"tokens"`
+	Budget         int    `json:"budget"`
+	Trims          []Trim `json:"trims"`
+}
+
+// Summarizer condenses an older role's bullets into one short line. The
+// bullets are the role's lines, one string per top-level bullet with its
+// nested lines, markers included.
+type Summarizer func(heading string, bullets []string) (string, error)
+
+// MaxInputTokens returns the configured budget from LLM_MAX_INPUT_TOKENS
+func MaxInputTokens() int {
+	raw := os.Getenv("LLM_MAX_INPUT_TOKENS")
+	if raw == "" {
+		return defaultMaxInputTokens
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1000 {
+		log.Printf("[Budget] Invalid LLM_MAX_INPUT_TOKENS %q, using %d", raw, defaultMaxInputTokens)
+		return defaultMaxInputTokens
+	}
+	return n
+}
+
+// Estimate returns the approximate token count of text for a BAML client
+func Estimate(client, text string) int {
+	if text == "" {
+		return 0
+	}
+	ratio, ok := charsPerToken[client]
+	if !ok {
+		ratio = 3.5
+	}
+	byChars := float64(utf8.RuneCountInString(text)) / ratio
+	// Dense text (code, URLs, numbers) tokenizes worse than prose; never
+	// estimate fewer tokens than ~1.3 per word
+	byWords := float64(len(strings.Fields(text))) * 1.3
+	return int(math.Ceil(math.Max(byChars, byWords)))
+}
+
+// Fit reduces resume and jobDesc until together they fit in maxTokens for
+// the given client. Reductions, in order:
+//  1. collapse redundant whitespace
+//  2. drop boilerplate job description sections (EEO, benefits, about us...)
+//  3. summarize the oldest resume experience entries into one bullet with
+//     summarize, or when it is nil or fails, cut them to their first
+//     bullet; current roles, and the newest when none is current, are
+//     kept whole
+//  4. truncate the job description, then the resume
+func Fit(client, resume, jobDesc string, maxTokens int, summarize Summarizer) Result {
+	r := Result{Resume: resume, JobDescription: jobDesc, Budget: maxTokens}
+	total := func() int {
+		return Estimate(client, r.Resume) + Estimate(client, r.JobDescription)
+	}
+	record := func(source, kind, desc string, before, after string) {
+		saved := Estimate(client, before) - Estimate(client, after)
+		// A truncation is always reported, even one that only swapped a
+		// short input for the marker
+		if saved <= 0 && (kind != KindTruncated || before == after) {
+			return
+		}
+		r.Trims = append(r.Trims, Trim{Source: source, Kind: kind, Description: desc, TokensSaved: max(saved, 0)})
+	}
+
+	if r.Tokens = total(); r.Tokens <= maxTokens {
+		return r
+	}
+
+	// 1. Whitespace
+	for _, in := range []struct {
+		source string
+		text   *string
+	}{{"resume", &r.Resume}, {"job_description", &r.JobDescription}} {
+		before := *in.text
+		*in.text = collapseWhitespace(before)
+		record(in.source, KindWhitespace, "Collapsed extra blank lines and spacing", before, *in.text)
+	}
+
+	// 2. Job description boilerplate
+	if total() > maxTokens {
+		kept, dropped := dropBoilerplate(r.JobDescription)
+		for _, section := range dropped {
+			r.Trims = append(r.Trims, Trim{
+				Source:      "job_description",
+				Kind:        KindBoilerplate,
+				Description: fmt.Sprintf("Removed %s section (%q)", section.label, section.heading),
+				TokensSaved: Estimate(client, section.text),
+			})
+		}
+		r.JobDescription = kept
+	}
+
+	// 3. Oldest experience first
+	for total() > maxTokens {
+		lines := strings.Split(r.Resume, "\n")
+		role, ok := oldestRole(lines)
+		if !ok {
+			break
+		}
+		before := r.Resume
+		heading := strings.TrimSpace(lines[role.start])
+		trim := Trim{Source: "resume", Kind: KindSummarized, Description: fmt.Sprintf("Summarized older role %q in one bullet", heading)}
+		if summary, ok := summarizeRole(client, summarize, lines, role); ok {
+			r.Resume = replaceBullets(lines, role, summary)
+		} else {
+			r.Resume = cutRole(lines, role)
+			trim.Kind, trim.Description = KindCutRole, fmt.Sprintf("Cut older role %q to its first bullet", heading)
+		}
+		trim.TokensSaved = max(Estimate(client, before)-Estimate(client, r.Resume), 0)
+		r.Trims = append(r.Trims, trim)
+	}
+
+	// 4. Hard truncation as a last resort, job description first
+	if over := total() - maxTokens; over > 0 {
+		before := r.JobDescription
+		r.JobDescription = truncateTokens(client, before, Estimate(client, before)-over)
+		record("job_description", KindTruncated, "Cut the end of the job description", before, r.JobDescription)
+	}
+	if over := total() - maxTokens; over > 0 {
+		before := r.Resume
+		r.Resume = truncateTokens(client, before, Estimate(client, before)-over)
+		record("resume", KindTruncated, "Cut the end of the resume", before, r.Resume)
+	}
+
+	r.Tokens = total()
+	return r
+}
+
+var blankLines = regexp.MustCompile(`\n[ \t]*\n(?:[ \t]*\n)+`)
+var runsOfSpaces = regexp.MustCompile(`[ \t]{2,}`)
+
+func collapseWhitespace(s string) string {
+	s = runsOfSpaces.ReplaceAllString(s, " ")
+	s = blankLines.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// truncateTokens cuts text to roughly limit tokens, keeping whole lines
+// and then as many words of the next line as fit
+func truncateTokens(client, text string, limit int) string {
+	const marker = "\n[...truncated to fit the input limit]"
+
+	joinLine := func(kept, line string) string {
+		if kept == "" {
+			return line
+		}
+		return kept + "\n" + line
+	}
+
+	kept := ""
+	for _, line := range strings.Split(text, "\n") {
+		candidate := joinLine(kept, line)
+		if Estimate(client, candidate+marker) <= limit {
+			kept = candidate
+			continue
+		}
+		words := strings.Fields(line)
+		lo, hi := 0, len(words)
+		for lo < hi {
+			mid := (lo + hi + 1) / 2
+			if Estimate(client, joinLine(kept, strings.Join(words[:mid], " "))+marker) <= limit {
+				lo = mid
+			} else {
+				hi = mid - 1
+			}
+		}
+		if lo > 0 {
+			kept = joinLine(kept, strings.Join(words[:lo], " "))
+		}
+		break
+	}
+	return strings.TrimLeft(kept+marker, "\n")
+}
@@ -0,0 +1,294 @@
+package budget
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const client = "ClaudeHaiku"
+
+func TestEstimate(t *testing.T) {
+	if got := Estimate(client, ""); got != 0 {
+		t.Fatalf("Estimate(\"\") = %d", got)
+	}
+	// 35 characters of prose is ~10 tokens
+	if got := Estimate(client, strings.Repeat("abcdefg ", 5)[:35]); got != 10 {
+		t.Fatalf("Estimate(prose) = %d, want 10", got)
+	}
+	// Many short words estimate by word count instead
+	if got := Estimate(client, strings.Repeat("a ", 100)); got != 130 {
+		t.Fatalf("Estimate(words) = %d, want 130", got)
+	}
+}
+
+func TestIsHeading(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"## Benefits", true},
+		{"# About the role", true},
+		{"BENEFITS", true},
+		{"WHAT WE OFFER:", true},
+		{"Benefits:", true},
+		{"**Benefits**", true},
+		{"**Equal Opportunity Employer:**", true},
+		{"What you'll do:", true},
+		// Sentences that merely start with a capital are not headings
+		{"Build benefits enrollment features", false},
+		{"Benefits", false},
+		{"Own the billing pipeline", false},
+		{"Requirements for this project include:", false},
+		{"**Strong communicator**", false},
+		{"- BENEFITS", false},
+		{"", false},
+		{"2019", false},
+	}
+	for _, tt := range tests {
+		if got := isHeading(tt.line); got != tt.want {
+			t.Errorf("isHeading(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestDropBoilerplate(t *testing.T) {
+	jd := `Senior Engineer
+
+Build benefits enrollment features for HR teams.
+Own the payroll integration.
+
+## Benefits
+Health, dental and 401k.
+
+EQUAL OPPORTUNITY
+We are an equal opportunity employer.`
+
+	kept, dropped := dropBoilerplate(jd)
+	if !strings.Contains(kept, "Build benefits enrollment features") || !strings.Contains(kept, "Own the payroll integration.") {
+		t.Fatalf("dropped job content:\n%s", kept)
+	}
+	if strings.Contains(kept, "401k") || strings.Contains(kept, "equal opportunity employer") {
+		t.Fatalf("kept boilerplate:\n%s", kept)
+	}
+	var labels []string
+	for _, s := range dropped {
+		labels = append(labels, s.label)
+	}
+	if strings.Join(labels, ",") != "benefits,equal opportunity" {
+		t.Fatalf("dropped %v, want benefits and equal opportunity", labels)
+	}
+}
+
+const resume = `Jane Doe
+
+Staff Engineer, Acme 2021 - Present
+- Led the billing rewrite
+- Cut costs 30%
+
+Senior Engineer, Initech 2017 - 2021
+- Built the payments API
+- Migrated to Kubernetes
+  - Wrote the Helm charts
+- Mentored four engineers
+  across two teams
+
+Engineer, Globex 2014 - 2017
+- Shipped the first mobile app
+- Ran the on-call rotation`
+
+// cutOldestRole cuts the oldest role that can be cut to its first bullet
+func cutOldestRole(resume string) (string, string, bool) {
+	lines := strings.Split(resume, "\n")
+	role, ok := oldestRole(lines)
+	if !ok {
+		return resume, "", false
+	}
+	return cutRole(lines, role), strings.TrimSpace(lines[role.start]), true
+}
+
+func TestCutOldestRole(t *testing.T) {
+	got, heading, ok := cutOldestRole(resume)
+	if !ok || heading != "Engineer, Globex 2014 - 2017" {
+		t.Fatalf("cutOldestRole = %q, %v; want the Globex role", heading, ok)
+	}
+	if !strings.Contains(got, "- Shipped the first mobile app\n- "+cutMarker) || strings.Contains(got, "on-call") {
+		t.Fatalf("Globex role not cut to its first bullet:\n%s", got)
+	}
+
+	// Next the Initech role, dropping nested bullets and wrapped lines with
+	// their parent bullet
+	got, heading, ok = cutOldestRole(got)
+	if !ok || heading != "Senior Engineer, Initech 2017 - 2021" {
+		t.Fatalf("second cutOldestRole = %q, %v; want the Initech role", heading, ok)
+	}
+	for _, gone := range []string{"Kubernetes", "Helm", "Mentored", "across two teams"} {
+		if strings.Contains(got, gone) {
+			t.Errorf("%q survived cutting the Initech role:\n%s", gone, got)
+		}
+	}
+	if !strings.Contains(got, "- Built the payments API\n- "+cutMarker+"\n") {
+		t.Fatalf("Initech role not cut to its first bullet:\n%s", got)
+	}
+
+	// The current role is never cut
+	if after, _, ok := cutOldestRole(got); ok {
+		t.Fatalf("cut the current role:\n%s", after)
+	}
+}
+
+func TestCutOldestRoleKeepsNestedFirstBullet(t *testing.T) {
+	in := "Acme 2022 - Present\n- Now\n- Also\n\nInitech 2015 - 2018\n- Built it\n  - in Go\n- Ran it"
+	got, _, ok := cutOldestRole(in)
+	if !ok {
+		t.Fatal("nothing cut")
+	}
+	want := "Initech 2015 - 2018\n- Built it\n  - in Go\n- " + cutMarker
+	if !strings.HasSuffix(got, want) {
+		t.Fatalf("cutOldestRole =\n%s\nwant suffix\n%s", got, want)
+	}
+}
+
+func TestOldestRoleKeepsEveryCurrentRole(t *testing.T) {
+	in := "Founder, Side Project 2020 - Present\n- Built it\n- Sold it\n\n" +
+		"Staff Engineer, Acme 2019 - Current\n- Led billing\n- Cut costs\n\n" +
+		"Engineer, Initech 2015 - 2019\n- Built the API\n- Ran on-call"
+	got, heading, ok := cutOldestRole(in)
+	if !ok || heading != "Engineer, Initech 2015 - 2019" {
+		t.Fatalf("cutOldestRole = %q, %v; want the Initech role", heading, ok)
+	}
+	if after, heading, ok := cutOldestRole(got); ok {
+		t.Fatalf("cut current role %q:\n%s", heading, after)
+	}
+
+	// With no current role, the newest is kept whole, ties included
+	in = "Lead, Acme 2018 - 2021\n- One\n- Two\n\nEngineer, Globex 2019 - 2021\n- Three\n- Four\n\nIntern, Initech 2015\n- Five\n- Six"
+	got, heading, ok = cutOldestRole(in)
+	if !ok || heading != "Intern, Initech 2015" {
+		t.Fatalf("cutOldestRole = %q, %v; want the Initech role", heading, ok)
+	}
+	if _, heading, ok := cutOldestRole(got); ok {
+		t.Fatalf("cut newest role %q", heading)
+	}
+}
+
+func TestFitSummarizesOlderRoles(t *testing.T) {
+	var asked []string
+	summarize := func(heading string, bullets []string) (string, error) {
+		asked = append(asked, heading)
+		if heading == "Engineer, Globex 2014 - 2017" {
+			want := []string{"- Shipped the first mobile app", "- Ran the on-call rotation"}
+			if strings.Join(bullets, "|") != strings.Join(want, "|") {
+				t.Errorf("bullets = %q, want %q", bullets, want)
+			}
+		}
+		return "- Shipped Globex's first mobile app\nand ran on-call.", nil
+	}
+	// Over budget by more than summarizing one role saves
+	r := Fit(client, resume, "Go engineer", Estimate(client, resume)-12, summarize)
+
+	if strings.Join(asked, "|") != "Engineer, Globex 2014 - 2017|Senior Engineer, Initech 2017 - 2021" {
+		t.Fatalf("summarized %q, want Globex then Initech", asked)
+	}
+	if kinds := trimKinds(r.Trims); kinds != "whitespace,summarized_experience,summarized_experience" {
+		t.Fatalf("trims %s, want both older roles summarized", kinds)
+	}
+	if want := "Engineer, Globex 2014 - 2017\n- Shipped Globex's first mobile app and ran on-call."; !strings.HasSuffix(r.Resume, want) {
+		t.Fatalf("Globex role not summarized in one bullet:\n%s", r.Resume)
+	}
+	if strings.Contains(r.Resume, "Kubernetes") || strings.Contains(r.Resume, cutMarker) {
+		t.Fatalf("Initech role not summarized:\n%s", r.Resume)
+	}
+	if !strings.Contains(r.Resume, "- Led the billing rewrite\n- Cut costs 30%") {
+		t.Fatalf("current role changed:\n%s", r.Resume)
+	}
+	if !strings.Contains(r.Trims[1].Description, "Summarized older role") || r.Trims[1].TokensSaved <= 0 {
+		t.Errorf("trim = %+v", r.Trims[1])
+	}
+}
+
+func TestFitCutsWhenSummarizingFails(t *testing.T) {
+	for name, summarize := range map[string]Summarizer{
+		"error": func(string, []string) (string, error) { return "", errors.New("model unavailable") },
+		"empty": func(string, []string) (string, error) { return " - ", nil },
+		"longer": func(_ string, bullets []string) (string, error) {
+			return strings.Repeat(strings.Join(bullets, " "), 2), nil
+		},
+	} {
+		r := Fit(client, resume, "Go engineer", Estimate(client, resume)-2, summarize)
+		if kinds := trimKinds(r.Trims); !strings.HasPrefix(kinds, "whitespace,cut_experience") || strings.Contains(kinds, "summarized") {
+			t.Errorf("%s: trims %s, want the role cut", name, kinds)
+		}
+		if !strings.Contains(r.Resume, "- Shipped the first mobile app\n- "+cutMarker) {
+			t.Errorf("%s: Globex role not cut to its first bullet:\n%s", name, r.Resume)
+		}
+	}
+}
+
+func TestFitUnderBudget(t *testing.T) {
+	r := Fit(client, resume, "Go engineer", 10000, nil)
+	if len(r.Trims) != 0 || r.Resume != resume {
+		t.Fatalf("Fit trimmed input that fits: %+v", r.Trims)
+	}
+	if r.Tokens != Estimate(client, resume)+Estimate(client, "Go engineer") || r.Budget != 10000 {
+		t.Fatalf("Fit tokens %d / budget %d", r.Tokens, r.Budget)
+	}
+}
+
+func TestFitAppliesTrimsInOrder(t *testing.T) {
+	jd := "Senior Engineer\n\n\n\nBuild billing.\n\n## Benefits\nHealth, dental and 401k.\nUnlimited PTO and parental leave."
+	total := Estimate(client, resume) + Estimate(client, jd)
+
+	// Just over budget: whitespace and boilerplate are enough
+	r := Fit(client, resume, jd, total-12, nil)
+	kinds := trimKinds(r.Trims)
+	if kinds != "whitespace,boilerplate" {
+		t.Fatalf("trims %s, want whitespace,boilerplate", kinds)
+	}
+	if r.Tokens > r.Budget {
+		t.Fatalf("Fit left %d tokens over a %d budget", r.Tokens, r.Budget)
+	}
+
+	// Tighter: older roles are cut next, oldest first
+	r = Fit(client, resume, jd, total-40, nil)
+	if kinds := trimKinds(r.Trims); !strings.HasPrefix(kinds, "whitespace,boilerplate,cut_experience") || strings.Contains(kinds, "truncated") {
+		t.Fatalf("trims %s, want older roles cut without truncation", kinds)
+	}
+	if !strings.Contains(r.Trims[2].Description, "Globex") {
+		t.Fatalf("first role cut was %q, want Globex", r.Trims[2].Description)
+	}
+	if !strings.Contains(r.Resume, "Cut costs 30%") {
+		t.Fatalf("current role was cut:\n%s", r.Resume)
+	}
+
+	// Far too small: everything, ending in truncation
+	r = Fit(client, resume, jd, 40, nil)
+	if kinds := trimKinds(r.Trims); !strings.HasSuffix(kinds, "truncated,truncated") {
+		t.Fatalf("trims %s, want both inputs truncated last", kinds)
+	}
+	if r.Tokens > r.Budget {
+		t.Fatalf("Fit left %d tokens over a %d budget", r.Tokens, r.Budget)
+	}
+	if r.Trims[0].TokensSaved <= 0 || r.Trims[1].TokensSaved <= 0 {
+		t.Errorf("whitespace and boilerplate trims saved nothing: %+v", r.Trims[:2])
+	}
+}
+
+func TestTruncateTokens(t *testing.T) {
+	text := "line one\nline two\nline three has several more words in it"
+	got := truncateTokens(client, text, 20)
+	if Estimate(client, got) > 20 {
+		t.Fatalf("truncateTokens over limit: %d tokens", Estimate(client, got))
+	}
+	if !strings.HasPrefix(got, "line one\nline two\nline three") || !strings.HasSuffix(got, "[...truncated to fit the input limit]") {
+		t.Fatalf("truncateTokens = %q", got)
+	}
+}
+
+func trimKinds(trims []Trim) string {
+	var kinds []string
+	for _, t := range trims {
+		kinds = append(kinds, t.Kind)
+	}
+	return strings.Join(kinds, ",")
+}
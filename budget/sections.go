@@ -0,0 +1,303 @@
+package budget
+
+import (
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// section is a heading plus the text under it
+type section struct {
+	heading string
+	label   string
+	text    string
+}
+
+// boilerplate matches job description sections that don't help tailoring
+var boilerplate = []struct {
+	label   string
+	heading *regexp.Regexp
+	body    *regexp.Regexp
+}{
+	{
+		label:   "equal opportunity",
+		heading: regexp.MustCompile(`(?i)\b(equal (employment )?opportunity|EEO|diversity|non-?discrimination|accommodations?)\b`),
+		body:    regexp.MustCompile(`(?i)(equal opportunity employer|without regard to (race|religion|color|sex|age)|reasonable accommodation|protected veteran status|E-Verify)`),
+	},
+	{
+		label:   "benefits",
+		heading: regexp.MustCompile(`(?i)\b(benefits|perks|what we offer|compensation (and|&) benefits|why you'?ll love|total rewards)\b`),
+		body:    regexp.MustCompile(`(?i)(401\(?k\)?|health,? dental|paid time off|parental leave|wellness stipend|unlimited pto)`),
+	},
+	{
+		label:   "about the company",
+		heading: regexp.MustCompile(`(?i)^\W*(about (us|the company|the team)|who we are|our (story|mission|values))\W*$`),
+	},
+	{
+		label:   "application instructions",
+		heading: regexp.MustCompile(`(?i)\b(how to apply|application process|privacy notice|recruitment fraud|applicant privacy)\b`),
+		body:    regexp.MustCompile(`(?i)(recruiters? will never ask|data privacy notice|applicant privacy policy)`),
+	},
+}
+
+var (
+	// markdownHeading is a "# Heading" line
+	markdownHeading = regexp.MustCompile(`^\s*#{1,6}\s+\S`)
+	// knownHeading matches the text of common job description section
+	// headings, for lines that are only marked by a colon or bold
+	knownHeading = regexp.MustCompile(`(?i)^(about (us|the company|the team|the role|you)|who we are|our (story|mission|values|culture)|` +
+		`benefits|perks( (and|&) benefits)?|what we offer|compensation( (and|&) benefits)?|total rewards|why you'?ll love [^.!?]*|` +
+		`equal (employment )?opportunity[^.!?]*|eeo[^.!?]*|diversity[^.!?]*|accommodations?|` +
+		`how to apply|application process|(applicant )?privacy notice|recruitment fraud[^.!?]*|` +
+		`(the )?role|overview|summary|responsibilities|requirements|((minimum|basic|preferred) )?qualifications|` +
+		`what you'?ll (do|bring)|what we'?re looking for|nice to have|bonus points|skills)$`)
+)
+
+// maxHeadingChars caps how long a heading line can be
+const maxHeadingChars = 60
+
+// isHeading reports whether line is a section heading: a markdown heading,
+// an all-caps line, or a known heading ending in ":" or set in bold
+func isHeading(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "-") {
+		return false
+	}
+	if markdownHeading.MatchString(line) {
+		return true
+	}
+	if len(line) > maxHeadingChars {
+		return false
+	}
+	if strings.ToUpper(line) == line && strings.ToLower(line) != line {
+		return true
+	}
+	bold := strings.HasPrefix(line, "**") && strings.HasSuffix(strings.TrimSuffix(line, ":"), "**")
+	text := strings.TrimSpace(strings.Trim(line, "*"))
+	if !bold && !strings.HasSuffix(text, ":") {
+		return false
+	}
+	return knownHeading.MatchString(strings.TrimSpace(strings.Trim(text, "*:")))
+}
+
+// splitSections splits text at heading-like lines. Paragraph breaks also
+// start a new section so an untitled EEO footer can be dropped on its own.
+func splitSections(text string) []section {
+	var sections []section
+	var cur strings.Builder
+	heading := ""
+	flush := func() {
+		if strings.TrimSpace(cur.String()) != "" {
+			sections = append(sections, section{heading: heading, text: cur.String()})
+		}
+		cur.Reset()
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		paragraphBreak := strings.TrimSpace(line) == "" && i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" && !isHeading(lines[i+1])
+		if isHeading(line) {
+			flush()
+			heading = strings.Trim(strings.TrimSpace(line), "#*: ")
+		} else if paragraphBreak && !strings.HasSuffix(strings.TrimSpace(cur.String()), ":") {
+			flush()
+			heading = ""
+		}
+		cur.WriteString(line)
+		cur.WriteString("\n")
+	}
+	flush()
+	return sections
+}
+
+// dropBoilerplate removes boilerplate sections, returning what is kept and
+// what was dropped
+func dropBoilerplate(text string) (string, []section) {
+	var kept strings.Builder
+	var dropped []section
+	for _, s := range splitSections(text) {
+		label := ""
+		for _, b := range boilerplate {
+			if (s.heading != "" && b.heading.MatchString(s.heading)) || (b.body != nil && b.body.MatchString(s.text)) {
+				label = b.label
+				break
+			}
+		}
+		if label == "" {
+			kept.WriteString(s.text)
+			continue
+		}
+		s.label = label
+		if s.heading == "" {
+			s.heading = firstWords(s.text, 6)
+		}
+		dropped = append(dropped, s)
+	}
+	return collapseWhitespace(kept.String()), dropped
+}
+
+var (
+	yearPattern   = regexp.MustCompile(`\b(19[5-9]\d|20\d\d)\b`)
+	bulletPattern = regexp.MustCompile(`^\s*([-*•]|\d+[.)])\s+`)
+	// presentPattern marks a current role as the newest
+	presentPattern = regexp.MustCompile(`(?i)\b(present|current|now|today)\b`)
+	// cutMarker tags entries that have already been cut to one bullet
+	cutMarker = "(earlier bullets omitted)"
+)
+
+// currentYear sorts a role marked present after every dated one
+const currentYear = 9999
+
+// experience is a role entry: a dated heading line followed by bullets
+type experience struct {
+	start, end int // line range [start, end)
+	year       int
+	// bullets holds each top-level bullet's lines: the bullet itself plus
+	// its nested bullets and indented continuation lines
+	bullets [][]int
+}
+
+// oldestRole finds the oldest role that still has more than one bullet.
+// Current roles, and the newest role when none is current, are never
+// picked: they are what recruiters read first.
+func oldestRole(lines []string) (experience, bool) {
+	var entries []experience
+	newest := 0
+	for i := 0; i < len(lines); i++ {
+		if bulletPattern.MatchString(lines[i]) {
+			continue
+		}
+		years := yearPattern.FindAllString(lines[i], -1)
+		if len(years) == 0 {
+			continue
+		}
+		e := experience{start: i, end: i + 1, year: latestYear(years)}
+		if presentPattern.MatchString(lines[i]) {
+			e.year = currentYear
+		}
+		topIndent := 0
+	scan:
+		for j := i + 1; j < len(lines); j++ {
+			line := lines[j]
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			indent := len(line) - len(strings.TrimLeft(line, " \t"))
+			switch {
+			case bulletPattern.MatchString(line) && (len(e.bullets) == 0 || indent <= topIndent):
+				if len(e.bullets) == 0 {
+					topIndent = indent
+				}
+				e.bullets = append(e.bullets, []int{j})
+			case len(e.bullets) > 0 && indent > topIndent:
+				// A nested bullet or a wrapped line of the bullet above
+				last := len(e.bullets) - 1
+				e.bullets[last] = append(e.bullets[last], j)
+			case len(e.bullets) == 0 && indent > 0:
+				// An indented detail line under the heading
+				continue
+			default:
+				break scan
+			}
+			e.end = j + 1
+		}
+		newest = max(newest, e.year)
+		if len(e.bullets) > 1 && !strings.Contains(lines[e.bullets[1][0]], cutMarker) {
+			entries = append(entries, e)
+		}
+	}
+
+	var oldest experience
+	found := false
+	for _, e := range entries {
+		if e.year == newest || e.year == currentYear {
+			continue
+		}
+		if !found || e.year < oldest.year {
+			oldest, found = e, true
+		}
+	}
+	return oldest, found
+}
+
+// summarizeRole asks summarize for a one-line summary of role's bullets,
+// reporting false when there is no summarizer, it fails, or its summary
+// saves nothing over the bullets
+func summarizeRole(client string, summarize Summarizer, lines []string, role experience) (string, bool) {
+	if summarize == nil {
+		return "", false
+	}
+	var bullets []string
+	for _, b := range role.bullets {
+		var text []string
+		for _, j := range b {
+			text = append(text, lines[j])
+		}
+		bullets = append(bullets, strings.Join(text, "\n"))
+	}
+	summary, err := summarize(strings.TrimSpace(lines[role.start]), bullets)
+	if err != nil {
+		log.Printf("[Budget] Summarizing a role failed, cutting it instead: %v", err)
+		return "", false
+	}
+	// One bullet, on one line
+	summary = strings.Join(strings.Fields(summary), " ")
+	summary = strings.TrimSpace(strings.TrimLeft(bulletPattern.ReplaceAllString(summary, ""), "-*• "))
+	if summary == "" || Estimate(client, summary) >= Estimate(client, strings.Join(bullets, "\n")) {
+		return "", false
+	}
+	return summary, true
+}
+
+// cutRole cuts role down to its first bullet and a marker line saying the
+// rest were omitted
+func cutRole(lines []string, role experience) string {
+	first := role.bullets[0]
+	kept := make([]string, 0, len(first)+1)
+	for _, j := range first {
+		kept = append(kept, lines[j])
+	}
+	kept = append(kept, bulletIndent(lines[first[0]])+"- "+cutMarker)
+	return spliceBullets(lines, role, kept)
+}
+
+// replaceBullets swaps role's bullets for a single summary bullet
+func replaceBullets(lines []string, role experience, summary string) string {
+	return spliceBullets(lines, role, []string{bulletIndent(lines[role.bullets[0][0]]) + "- " + summary})
+}
+
+// spliceBullets replaces the lines of role's bullets with kept, leaving
+// blank lines between them out
+func spliceBullets(lines []string, role experience, kept []string) string {
+	first := role.bullets[0][0]
+	last := role.bullets[len(role.bullets)-1]
+	end := last[len(last)-1] + 1
+	out := make([]string, 0, len(lines))
+	out = append(out, lines[:first]...)
+	out = append(out, kept...)
+	out = append(out, lines[end:]...)
+	return strings.Join(out, "\n")
+}
+
+func bulletIndent(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+func latestYear(years []string) int {
+	latest := 0
+	for _, y := range years {
+		if n, _ := strconv.Atoi(y); n > latest {
+			latest = n
+		}
+	}
+	return latest
+}
+
+func firstWords(s string, n int) string {
+	words := strings.Fields(s)
+	if len(words) > n {
+		return strings.Join(words[:n], " ") + "..."
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,219 @@
+// Package export renders a tweaked resume, written in the markdown subset
+// the models produce, to downloadable formats.
+//
+// Parse turns the markdown into a Document of blocks and inline spans; each
+// format walks that instead of re-parsing the source. Every format lays the
+// resume out in a single column of real text, so applicant tracking systems
+// read it in order.
+package export
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BlockKind is the type of a Block
+type BlockKind int
+
+const (
+	Heading BlockKind = iota
+	Paragraph
+	ListItem
+	Rule
+	Code
+)
+
+// Block is one block-level element of a resume
+type Block struct {
+	Kind BlockKind
+	// Level is the heading level (1-6) or the list nesting depth (0-based)
+	Level   int
+	Ordered bool
+	// Number is the position of an ordered list item
+	Number int
+	// Text is the block's markdown source; paragraph and code lines are
+	// separated by "\n"
+	Text string
+}
+
+// Spans splits the block's text into styled runs. Code blocks are a single
+// plain span.
+func (b Block) Spans() []Span {
+	if b.Kind == Code {
+		return []Span{{Text: b.Text, Code: true}}
+	}
+	return Spans(b.Text)
+}
+
+// Document is a parsed resume
+type Document struct {
+	// Title is the text of the first level-1 heading, usually the name
+	Title  string
+	Blocks []Block
+}
+
+var (
+	headingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	bulletRe  = regexp.MustCompile(`^(\s*)[-*+•]\s+(.*)$`)
+	orderedRe = regexp.MustCompile(`^(\s*)(\d{1,9})[.)]\s+(.*)$`)
+	ruleRe    = regexp.MustCompile(`^\s{0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	fenceRe   = regexp.MustCompile("^\\s*(```|~~~)")
+	quoteRe   = regexp.MustCompile(`^\s*>\s?(.*)$`)
+)
+
+// Parse reads markdown into a Document
+func Parse(src string) Document {
+	var doc Document
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	// indents holds the indentation of each open list level
+	var indents []int
+	para := -1
+
+	add := func(b Block) {
+		doc.Blocks = append(doc.Blocks, b)
+		para = -1
+		if b.Kind != ListItem {
+			indents = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if m := fenceRe.FindStringSubmatch(line); m != nil {
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), m[1]) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			add(Block{Kind: Code, Text: strings.Join(code, "\n")})
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			para = -1
+			continue
+		}
+
+		if ruleRe.MatchString(line) {
+			add(Block{Kind: Rule})
+			continue
+		}
+
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			add(Block{Kind: Heading, Level: len(m[1]), Text: m[2]})
+			if len(m[1]) == 1 && doc.Title == "" {
+				doc.Title = PlainText(m[2])
+			}
+			continue
+		}
+
+		if m := bulletRe.FindStringSubmatch(line); m != nil {
+			indents = nestList(indents, len(m[1]))
+			add(Block{Kind: ListItem, Level: len(indents) - 1, Text: m[2]})
+			continue
+		}
+		if m := orderedRe.FindStringSubmatch(line); m != nil {
+			indents = nestList(indents, len(m[1]))
+			n, _ := strconv.Atoi(m[2])
+			add(Block{Kind: ListItem, Level: len(indents) - 1, Ordered: true, Number: n, Text: m[3]})
+			continue
+		}
+
+		// Indented continuation of a list item
+		if n := len(doc.Blocks); n > 0 && doc.Blocks[n-1].Kind == ListItem && para < 0 && strings.HasPrefix(line, " ") {
+			doc.Blocks[n-1].Text += " " + strings.TrimSpace(line)
+			continue
+		}
+
+		if m := quoteRe.FindStringSubmatch(line); m != nil {
+			line = m[1]
+		}
+		if para >= 0 {
+			doc.Blocks[para].Text += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		add(Block{Kind: Paragraph, Text: strings.TrimSpace(line)})
+		para = len(doc.Blocks) - 1
+	}
+	return doc
+}
+
+// nestList returns the open list indents after an item at indent
+func nestList(indents []int, indent int) []int {
+	for len(indents) > 0 && indents[len(indents)-1] > indent {
+		indents = indents[:len(indents)-1]
+	}
+	if len(indents) == 0 || indents[len(indents)-1] < indent {
+		indents = append(indents, indent)
+	}
+	return indents
+}
+
+// Span is a run of text with one style
+type Span struct {
+	Text   string
+	Bold   bool
+	Italic bool
+	Code   bool
+	// URL is set for links
+	URL string
+}
+
+var spanRe = regexp.MustCompile("`([^`]+)`" +
+	`|\[([^\]]+)\]\(([^()\s]+)\)` +
+	`|\*\*\*(\S(?:.*?\S)?)\*\*\*` +
+	`|\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__` +
+	`|\*(\S(?:.*?\S)?)\*|\b_(\S(?:.*?\S)?)_\b`)
+
+// Spans splits inline markdown into styled runs
+func Spans(text string) []Span {
+	var spans []Span
+	plain := func(s string) {
+		if s != "" {
+			spans = append(spans, Span{Text: s})
+		}
+	}
+
+	last := 0
+	for _, m := range spanRe.FindAllStringSubmatchIndex(text, -1) {
+		plain(text[last:m[0]])
+		last = m[1]
+		group := func(i int) string {
+			if m[2*i] < 0 {
+				return ""
+			}
+			return text[m[2*i]:m[2*i+1]]
+		}
+
+		switch {
+		case m[2] >= 0:
+			spans = append(spans, Span{Text: group(1), Code: true})
+		case m[4] >= 0:
+			for _, s := range Spans(group(2)) {
+				s.URL = group(3)
+				spans = append(spans, s)
+			}
+		case m[8] >= 0:
+			spans = append(spans, Span{Text: group(4), Bold: true, Italic: true})
+		case m[10] >= 0 || m[12] >= 0:
+			spans = append(spans, Span{Text: group(5) + group(6), Bold: true})
+		default:
+			spans = append(spans, Span{Text: group(7) + group(8), Italic: true})
+		}
+	}
+	plain(text[last:])
+	return spans
+}
+
+// PlainText strips inline markdown from text
+func PlainText(text string) string {
+	var b strings.Builder
+	for _, s := range Spans(text) {
+		b.WriteString(s.Text)
+	}
+	return b.String()
+}
@@ -0,0 +1,352 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/johnhkchen/resume-tweaker/markdown"
+)
+
+// Fonts for DOCX output. Word documents reference fonts by name rather than
+// embedding them, so these are ones every Word and LibreOffice install has.
+const (
+	docxSerif = "Times New Roman"
+	docxSans  = "Arial"
+	docxMono  = "Courier New"
+)
+
+// docxMargin is the page margin in twentieths of a point (0.75in)
+const docxMargin = pdfMargin * 20
+
+// Numbering ids: bullets share one list; each ordered list gets its own
+// w:num from docxFirstOrdered up, so its numbering restarts
+const (
+	docxBulletNum    = 1
+	docxFirstOrdered = 2
+)
+
+const (
+	nsW   = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	nsR   = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	nsRel = "http://schemas.openxmlformats.org/package/2006/relationships"
+	// Relationship types
+	relDocument  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"
+	relCore      = "http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties"
+	relStyles    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles"
+	relNumbering = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering"
+	relHyperlink = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+)
+
+// docxWriter builds the body of a Word document
+type docxWriter struct {
+	body strings.Builder
+	// links maps each URL to its relationship id, in order of use
+	links   map[string]string
+	linkIDs []string
+	// lists holds the level and first number of each ordered list
+	lists []orderedList
+}
+
+type orderedList struct {
+	level, start int
+}
+
+// DOCX renders a resume to a Word document. Headings use Word's built-in
+// Title and Heading styles (# is Title, ## is Heading 1 and so on) and lists
+// use real numbering, so the resume stays editable in Word. The template sets
+// the fonts, sizes and accent color of those styles.
+func DOCX(doc Document, tmpl Template, size PageSize) ([]byte, error) {
+	w := &docxWriter{links: map[string]string{}}
+	prev := Block{Kind: -1}
+	for _, b := range doc.Blocks {
+		w.block(b, prev)
+		prev = b
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxPackageRels},
+		{"docProps/core.xml", docxCore(doc.Title)},
+		{"word/_rels/document.xml.rels", w.rels()},
+		{"word/document.xml", w.document(size)},
+		{"word/styles.xml", docxStyles(tmpl)},
+		{"word/numbering.xml", w.numbering()},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write([]byte(xml.Header + p.content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (w *docxWriter) block(b Block, prev Block) {
+	out := &w.body
+	switch b.Kind {
+	case Heading:
+		styleID := "Title"
+		if b.Level > 1 {
+			styleID = fmt.Sprintf("Heading%d", b.Level-1)
+		}
+		fmt.Fprintf(out, `<w:p><w:pPr><w:pStyle w:val="%s"/></w:pPr>`, styleID)
+		w.spans(b.Spans())
+		out.WriteString(`</w:p>`)
+
+	case Paragraph:
+		out.WriteString(`<w:p>`)
+		for i, line := range strings.Split(b.Text, "\n") {
+			if i > 0 {
+				out.WriteString(`<w:r><w:br/></w:r>`)
+			}
+			w.spans(Spans(line))
+		}
+		out.WriteString(`</w:p>`)
+
+	case ListItem:
+		num := docxBulletNum
+		if b.Ordered {
+			// An ordered item starts a new list unless it continues one
+			// at the same or a deeper level
+			if !(prev.Kind == ListItem && prev.Ordered && prev.Level >= b.Level) {
+				w.lists = append(w.lists, orderedList{level: min(b.Level, 8), start: b.Number})
+			}
+			num = docxFirstOrdered + len(w.lists) - 1
+		}
+		fmt.Fprintf(out, `<w:p><w:pPr><w:pStyle w:val="ListParagraph"/><w:numPr><w:ilvl w:val="%d"/><w:numId w:val="%d"/></w:numPr></w:pPr>`,
+			min(b.Level, 8), num)
+		w.spans(b.Spans())
+		out.WriteString(`</w:p>`)
+
+	case Rule:
+		out.WriteString(`<w:p><w:pPr><w:pBdr><w:bottom w:val="single" w:sz="4" w:space="1" w:color="B0B0B0"/></w:pBdr></w:pPr></w:p>`)
+
+	case Code:
+		out.WriteString(`<w:p><w:pPr><w:pStyle w:val="Code"/></w:pPr>`)
+		for i, line := range strings.Split(b.Text, "\n") {
+			if i > 0 {
+				out.WriteString(`<w:r><w:br/></w:r>`)
+			}
+			w.run(Span{Text: line}, false)
+		}
+		out.WriteString(`</w:p>`)
+	}
+}
+
+// spans writes styled runs; links become w:hyperlink elements
+func (w *docxWriter) spans(spans []Span) {
+	for _, s := range spans {
+		if s.URL != "" && markdown.SafeURL(s.URL) {
+			fmt.Fprintf(&w.body, `<w:hyperlink r:id="%s" w:history="1">`, w.link(strings.TrimSpace(s.URL)))
+			w.run(s, true)
+			w.body.WriteString(`</w:hyperlink>`)
+			continue
+		}
+		w.run(s, false)
+	}
+}
+
+func (w *docxWriter) run(s Span, link bool) {
+	out := &w.body
+	out.WriteString(`<w:r>`)
+	if link || s.Bold || s.Italic || s.Code {
+		out.WriteString(`<w:rPr>`)
+		if link {
+			out.WriteString(`<w:rStyle w:val="Hyperlink"/>`)
+		}
+		if s.Code {
+			fmt.Fprintf(out, `<w:rFonts w:ascii="%[1]s" w:hAnsi="%[1]s" w:cs="%[1]s"/>`, docxMono)
+		}
+		if s.Bold {
+			out.WriteString(`<w:b/>`)
+		}
+		if s.Italic {
+			out.WriteString(`<w:i/>`)
+		}
+		out.WriteString(`</w:rPr>`)
+	}
+	out.WriteString(`<w:t xml:space="preserve">`)
+	xml.EscapeText(out, []byte(s.Text))
+	out.WriteString(`</w:t></w:r>`)
+}
+
+// link returns the relationship id for url, adding it on first use
+func (w *docxWriter) link(url string) string {
+	if id, ok := w.links[url]; ok {
+		return id
+	}
+	id := fmt.Sprintf("rIdLink%d", len(w.linkIDs)+1)
+	w.links[url] = id
+	w.linkIDs = append(w.linkIDs, url)
+	return id
+}
+
+func (w *docxWriter) document(size PageSize) string {
+	return fmt.Sprintf(`<w:document xmlns:w="%s" xmlns:r="%s"><w:body>%s`+
+		`<w:sectPr><w:pgSz w:w="%d" w:h="%d"/>`+
+		`<w:pgMar w:top="%[6]d" w:right="%[6]d" w:bottom="%[6]d" w:left="%[6]d" w:header="720" w:footer="720" w:gutter="0"/>`+
+		`</w:sectPr></w:body></w:document>`,
+		nsW, nsR, w.body.String(), twips(size.Width), twips(size.Height), docxMargin)
+}
+
+func (w *docxWriter) rels() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<Relationships xmlns="%s">`, nsRel)
+	fmt.Fprintf(&b, `<Relationship Id="rIdStyles" Type="%s" Target="styles.xml"/>`, relStyles)
+	fmt.Fprintf(&b, `<Relationship Id="rIdNumbering" Type="%s" Target="numbering.xml"/>`, relNumbering)
+	for _, url := range w.linkIDs {
+		fmt.Fprintf(&b, `<Relationship Id="%s" Type="%s" Target="%s" TargetMode="External"/>`,
+			w.links[url], relHyperlink, escapeAttr(url))
+	}
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+// numbering defines a bulleted list and a numbered list format, then one
+// list instance per ordered list in the document
+func (w *docxWriter) numbering() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<w:numbering xmlns:w="%s">`, nsW)
+	bullets := []string{"•", "◦", "▪"}
+	for abstract, ordered := range []bool{false, true} {
+		fmt.Fprintf(&b, `<w:abstractNum w:abstractNumId="%d"><w:multiLevelType w:val="hybridMultilevel"/>`, abstract)
+		for lvl := 0; lvl < 9; lvl++ {
+			format, text := "bullet", bullets[lvl%len(bullets)]
+			if ordered {
+				format, text = "decimal", fmt.Sprintf("%%%d.", lvl+1)
+			}
+			fmt.Fprintf(&b, `<w:lvl w:ilvl="%d"><w:start w:val="1"/><w:numFmt w:val="%s"/><w:lvlText w:val="%s"/><w:lvlJc w:val="left"/>`+
+				`<w:pPr><w:ind w:left="%d" w:hanging="360"/></w:pPr></w:lvl>`,
+				lvl, format, text, 360*(lvl+1))
+		}
+		b.WriteString(`</w:abstractNum>`)
+	}
+	fmt.Fprintf(&b, `<w:num w:numId="%d"><w:abstractNumId w:val="0"/></w:num>`, docxBulletNum)
+	for i, list := range w.lists {
+		fmt.Fprintf(&b, `<w:num w:numId="%d"><w:abstractNumId w:val="1"/>`+
+			`<w:lvlOverride w:ilvl="%d"><w:startOverride w:val="%d"/></w:lvlOverride></w:num>`,
+			docxFirstOrdered+i, list.level, max(list.start, 1))
+	}
+	b.WriteString(`</w:numbering>`)
+	return b.String()
+}
+
+// docxStyles maps a template onto Word's built-in styles
+func docxStyles(tmpl Template) string {
+	font := docxSans
+	if tmpl.Serif {
+		font = docxSerif
+	}
+	accent := fmt.Sprintf("%02X%02X%02X", tmpl.Accent[0], tmpl.Accent[1], tmpl.Accent[2])
+	line := int(math.Round(tmpl.LineHeight * 240))
+	body := halfPoints(tmpl.BodySize)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<w:styles xmlns:w="%s">`, nsW)
+	fmt.Fprintf(&b, `<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="%[1]s" w:hAnsi="%[1]s" w:eastAsia="%[1]s" w:cs="%[1]s"/>`+
+		`<w:sz w:val="%[2]d"/><w:szCs w:val="%[2]d"/><w:lang w:val="en-US"/></w:rPr></w:rPrDefault>`+
+		`<w:pPrDefault><w:pPr><w:spacing w:after="120" w:line="%[3]d" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>`,
+		font, body, line)
+
+	b.WriteString(`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/></w:style>`)
+
+	jc := ""
+	if tmpl.CenterName {
+		jc = `<w:jc w:val="center"/>`
+	}
+	fmt.Fprintf(&b, `<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>`+
+		`<w:pPr><w:keepNext/><w:spacing w:after="120"/>%s<w:outlineLvl w:val="0"/></w:pPr>`+
+		`<w:rPr><w:b/><w:color w:val="%s"/><w:sz w:val="%d"/><w:szCs w:val="%[3]d"/></w:rPr></w:style>`,
+		jc, accent, halfPoints(tmpl.NameSize))
+
+	for level := 1; level <= 5; level++ {
+		var ppr, rpr string
+		size := tmpl.BodySize * 1.05
+		if level == 1 {
+			// Section headings
+			size = tmpl.BodySize * 1.25
+			if tmpl.UppercaseSections {
+				size = tmpl.BodySize * 1.1
+				rpr = `<w:caps/>`
+			}
+			rpr += fmt.Sprintf(`<w:color w:val="%s"/>`, accent)
+			// Word rejects pPr children out of schema order: pBdr
+			// comes before spacing
+			if tmpl.SectionRule {
+				ppr = fmt.Sprintf(`<w:pBdr><w:bottom w:val="single" w:sz="6" w:space="1" w:color="%s"/></w:pBdr>`, accent)
+			}
+			ppr += `<w:spacing w:before="240" w:after="80"/>`
+		} else {
+			ppr = `<w:spacing w:before="120" w:after="40"/>`
+		}
+		fmt.Fprintf(&b, `<w:style w:type="paragraph" w:styleId="Heading%[1]d"><w:name w:val="heading %[1]d"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:uiPriority w:val="9"/><w:qFormat/>`+
+			`<w:pPr><w:keepNext/>%[2]s<w:outlineLvl w:val="%[3]d"/></w:pPr>`+
+			`<w:rPr><w:b/>%[4]s<w:sz w:val="%[5]d"/><w:szCs w:val="%[5]d"/></w:rPr></w:style>`,
+			level, ppr, level-1, rpr, halfPoints(size))
+	}
+
+	b.WriteString(`<w:style w:type="paragraph" w:styleId="ListParagraph"><w:name w:val="List Paragraph"/><w:basedOn w:val="Normal"/><w:uiPriority w:val="34"/><w:qFormat/>` +
+		`<w:pPr><w:spacing w:after="40"/><w:contextualSpacing/></w:pPr></w:style>`)
+	fmt.Fprintf(&b, `<w:style w:type="paragraph" w:styleId="Code"><w:name w:val="Code"/><w:basedOn w:val="Normal"/>`+
+		`<w:pPr><w:spacing w:line="240" w:lineRule="auto"/></w:pPr>`+
+		`<w:rPr><w:rFonts w:ascii="%[1]s" w:hAnsi="%[1]s" w:cs="%[1]s"/><w:sz w:val="%[2]d"/><w:szCs w:val="%[2]d"/></w:rPr></w:style>`,
+		docxMono, halfPoints(tmpl.BodySize*0.9))
+	b.WriteString(`<w:style w:type="character" w:styleId="Hyperlink"><w:name w:val="Hyperlink"/><w:uiPriority w:val="99"/>` +
+		`<w:rPr><w:color w:val="1F4E79"/><w:u w:val="single"/></w:rPr></w:style>`)
+	b.WriteString(`</w:styles>`)
+	return b.String()
+}
+
+func docxCore(title string) string {
+	var b strings.Builder
+	b.WriteString(`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">`)
+	if title != "" {
+		b.WriteString(`<dc:title>`)
+		xml.EscapeText(&b, []byte(title))
+		b.WriteString(`</dc:title>`)
+	}
+	b.WriteString(`<dc:creator>Resume Tweaker</dc:creator></cp:coreProperties>`)
+	return b.String()
+}
+
+const docxContentTypes = `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`<Override PartName="/word/numbering.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"/>` +
+	`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+	`</Types>`
+
+const docxPackageRels = `<Relationships xmlns="` + nsRel + `">` +
+	`<Relationship Id="rIdDocument" Type="` + relDocument + `" Target="word/document.xml"/>` +
+	`<Relationship Id="rIdCore" Type="` + relCore + `" Target="docProps/core.xml"/>` +
+	`</Relationships>`
+
+// twips converts points to twentieths of a point
+func twips(points float64) int {
+	return int(math.Round(points * 20))
+}
+
+// halfPoints converts a font size to the half-points Word uses
+func halfPoints(points float64) int {
+	return int(math.Round(points * 2))
+}
+
+func escapeAttr(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,222 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// xmlNode is a generic XML element, enough to walk a DOCX part
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []xmlNode  `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+// attr returns the attribute with the given local name
+func (n xmlNode) attr(local string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// find returns every descendant element with the given local name
+func (n xmlNode) find(local string) []xmlNode {
+	var found []xmlNode
+	for _, c := range n.Nodes {
+		if c.XMLName.Local == local {
+			found = append(found, c)
+		}
+		found = append(found, c.find(local)...)
+	}
+	return found
+}
+
+// text joins the w:t runs under n
+func (n xmlNode) text() string {
+	var b strings.Builder
+	for _, t := range n.find("t") {
+		b.WriteString(t.Text)
+	}
+	return b.String()
+}
+
+// renderDOCX renders src and parses every part of the package
+func renderDOCX(t *testing.T, src string, size PageSize) map[string]xmlNode {
+	t.Helper()
+	out, err := DOCX(Parse(src), Templates[0], size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string]xmlNode{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var root xmlNode
+		if err := xml.Unmarshal(data, &root); err != nil {
+			t.Fatalf("%s isn't well-formed: %v", f.Name, err)
+		}
+		parts[f.Name] = root
+	}
+	return parts
+}
+
+func TestDOCXHyperlinks(t *testing.T) {
+	src := `# Jane Doe
+
+[GitHub](https://github.com/janedoe) | [Portfolio](https://example.com/?a=1&b=2)
+
+## Projects
+
+- Billing, see [GitHub](https://github.com/janedoe) and [a trap](javascript:alert)
+`
+	parts := renderDOCX(t, src, PageSizes[0])
+
+	rels := map[string]xmlNode{}
+	for _, r := range parts["word/_rels/document.xml.rels"].find("Relationship") {
+		rels[r.attr("Id")] = r
+	}
+	type link struct{ text, url string }
+	var got []link
+	ids := map[string]string{}
+	for _, h := range parts["word/document.xml"].find("hyperlink") {
+		rel, ok := rels[h.attr("id")]
+		if !ok {
+			t.Fatalf("hyperlink %q has no relationship", h.attr("id"))
+		}
+		if rel.attr("Type") != relHyperlink || rel.attr("TargetMode") != "External" {
+			t.Errorf("relationship %s = %+v, want an external hyperlink", h.attr("id"), rel.Attrs)
+		}
+		got = append(got, link{h.text(), rel.attr("Target")})
+		if id, seen := ids[rel.attr("Target")]; seen && id != h.attr("id") {
+			t.Errorf("%s has relationships %s and %s, want one", rel.attr("Target"), id, h.attr("id"))
+		}
+		ids[rel.attr("Target")] = h.attr("id")
+	}
+
+	want := []link{
+		{"GitHub", "https://github.com/janedoe"},
+		{"Portfolio", "https://example.com/?a=1&b=2"},
+		{"GitHub", "https://github.com/janedoe"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("hyperlinks = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("hyperlink %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if body := parts["word/document.xml"].text(); !strings.Contains(body, "a trap") {
+		t.Errorf("unsafe link lost its text: %q", body)
+	}
+}
+
+func TestDOCXListNumbering(t *testing.T) {
+	src := `# Jane Doe
+
+- Built billing
+  - On Go
+- Ran on-call
+
+1. First
+2. Second
+
+Between the lists
+
+3. Third
+`
+	parts := renderDOCX(t, src, PageSizes[0])
+
+	type item struct {
+		text       string
+		ilvl, num  int
+		listStyled bool
+	}
+	var got []item
+	for _, p := range parts["word/document.xml"].find("p") {
+		numPr := p.find("numPr")
+		if len(numPr) == 0 {
+			continue
+		}
+		ilvl, _ := strconv.Atoi(numPr[0].find("ilvl")[0].attr("val"))
+		num, _ := strconv.Atoi(numPr[0].find("numId")[0].attr("val"))
+		style := p.find("pStyle")
+		got = append(got, item{p.text(), ilvl, num, len(style) == 1 && style[0].attr("val") == "ListParagraph"})
+	}
+	want := []item{
+		{"Built billing", 0, docxBulletNum, true},
+		{"On Go", 1, docxBulletNum, true},
+		{"Ran on-call", 0, docxBulletNum, true},
+		{"First", 0, docxFirstOrdered, true},
+		{"Second", 0, docxFirstOrdered, true},
+		{"Third", 0, docxFirstOrdered + 1, true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("list paragraphs = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("list paragraph %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// Each w:num points at the bullet or decimal format; the second
+	// ordered list restarts at its own first number
+	formats := map[string]string{}
+	for _, a := range parts["word/numbering.xml"].find("abstractNum") {
+		formats[a.attr("abstractNumId")] = a.find("numFmt")[0].attr("val")
+	}
+	nums := map[int]xmlNode{}
+	for _, n := range parts["word/numbering.xml"].find("num") {
+		id, _ := strconv.Atoi(n.attr("numId"))
+		nums[id] = n
+	}
+	for id, format := range map[int]string{docxBulletNum: "bullet", docxFirstOrdered: "decimal", docxFirstOrdered + 1: "decimal"} {
+		n, ok := nums[id]
+		if !ok {
+			t.Fatalf("numbering has no w:num %d", id)
+		}
+		if got := formats[n.find("abstractNumId")[0].attr("val")]; got != format {
+			t.Errorf("w:num %d is %s, want %s", id, got, format)
+		}
+	}
+	if start := nums[docxFirstOrdered+1].find("startOverride"); len(start) != 1 || start[0].attr("val") != "3" {
+		t.Errorf("second ordered list doesn't restart at 3: %+v", nums[docxFirstOrdered+1])
+	}
+}
+
+func TestDOCXPageSize(t *testing.T) {
+	for _, size := range PageSizes {
+		t.Run(size.Name, func(t *testing.T) {
+			parts := renderDOCX(t, pdfResume, size)
+			pgSz := parts["word/document.xml"].find("pgSz")
+			if len(pgSz) != 1 {
+				t.Fatalf("document has %d w:pgSz, want 1", len(pgSz))
+			}
+			w, h := pgSz[0].attr("w"), pgSz[0].attr("h")
+			if w != strconv.Itoa(twips(size.Width)) || h != strconv.Itoa(twips(size.Height)) {
+				t.Errorf("page is %s x %s twips, want %d x %d", w, h, twips(size.Width), twips(size.Height))
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"embed"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/johnhkchen/resume-tweaker/markdown"
+)
+
+//go:embed latex
+var latexFiles embed.FS
+
+// LaTeXTemplate is a text/template producing a .tex file, optionally with a
+// document class of its own
+type LaTeXTemplate struct {
+	Name  string
+	Label string
+	// Class names a .cls file in latex/ the template's \documentclass
+	// loads; "" for standard classes
+	Class string
+	file  string
+}
+
+// LaTeXTemplates lists the available LaTeX templates; the first is the
+// default
+var LaTeXTemplates = []LaTeXTemplate{
+	{Name: "moderncv", Label: "ModernCV style", Class: "tweakcv", file: "moderncv.tex.tmpl"},
+	{Name: "article", Label: "Simple article", file: "article.tex.tmpl"},
+}
+
+// LaTeXTemplateByName looks up a LaTeX template, returning the default for ""
+func LaTeXTemplateByName(name string) (LaTeXTemplate, bool) {
+	if name == "" {
+		return LaTeXTemplates[0], true
+	}
+	for _, t := range LaTeXTemplates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return LaTeXTemplate{}, false
+}
+
+// latexTemplates are parsed once. The delimiters are << >> because LaTeX is
+// full of braces.
+var latexTemplates = template.Must(template.New("").Delims("<<", ">>").ParseFS(latexFiles, "latex/*.tmpl"))
+
+// latexData is what the templates see. Every string is already LaTeX.
+type latexData struct {
+	// Title is the name from the level-1 heading
+	Title string
+	// Header holds the lines between the name and the first section,
+	// usually contact details
+	Header   []string
+	Sections []latexSection
+	// Paper is a standard class option such as "a4paper"
+	Paper string
+	Class string
+	// ClassSource is the class file, set when it's written out by the
+	// .tex itself (filecontents) rather than shipped alongside it
+	ClassSource string
+}
+
+type latexSection struct {
+	Title string
+	Body  string
+}
+
+// LaTeX renders a resume to a single .tex file. A template with its own
+// class writes the class out from the .tex (filecontents), so the file
+// compiles on its own, e.g. on Overleaf.
+func LaTeX(doc Document, tmpl LaTeXTemplate, size PageSize) ([]byte, error) {
+	data := latexDocument(doc, size)
+	if tmpl.Class != "" {
+		class, err := latexFiles.ReadFile("latex/" + tmpl.Class + ".cls")
+		if err != nil {
+			return nil, err
+		}
+		data.Class, data.ClassSource = tmpl.Class, string(class)
+	}
+	return executeLaTeX(tmpl, data)
+}
+
+// LaTeXZip packages the .tex, named texName, with the template's class file
+func LaTeXZip(doc Document, tmpl LaTeXTemplate, size PageSize, texName string) ([]byte, error) {
+	data := latexDocument(doc, size)
+	data.Class = tmpl.Class
+	tex, err := executeLaTeX(tmpl, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string][]byte{texName: tex}
+	if tmpl.Class != "" {
+		class, err := latexFiles.ReadFile("latex/" + tmpl.Class + ".cls")
+		if err != nil {
+			return nil, err
+		}
+		files[tmpl.Class+".cls"] = class
+	}
+	for _, name := range []string{texName, tmpl.Class + ".cls"} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		f, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func executeLaTeX(tmpl LaTeXTemplate, data latexData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := latexTemplates.ExecuteTemplate(&buf, tmpl.file, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// latexPapers maps page sizes to standard class options
+var latexPapers = map[string]string{
+	"letter": "letterpaper",
+	"a4":     "a4paper",
+	"legal":  "legalpaper",
+}
+
+// latexDocument splits the document into header and sections and renders
+// their content as LaTeX
+func latexDocument(doc Document, size PageSize) latexData {
+	data := latexData{Title: latexEscape(doc.Title), Paper: latexPapers[size.Name]}
+	if data.Paper == "" {
+		data.Paper = "letterpaper"
+	}
+
+	// header collects the blocks before the first section
+	var header, current []Block
+	titleSeen := false
+	for _, b := range doc.Blocks {
+		switch {
+		case b.Kind == Heading && b.Level == 1 && !titleSeen && PlainText(b.Text) == doc.Title:
+			titleSeen = true
+		case b.Kind == Heading && b.Level <= 2:
+			if len(data.Sections) == 0 {
+				header = current
+			} else {
+				data.Sections[len(data.Sections)-1].Body = latexBlocks(current)
+			}
+			current = nil
+			data.Sections = append(data.Sections, latexSection{Title: latexSpans(b.Spans())})
+		default:
+			current = append(current, b)
+		}
+	}
+	if len(data.Sections) == 0 {
+		// No sections: everything is body text under the name
+		data.Sections = append(data.Sections, latexSection{})
+	}
+	data.Sections[len(data.Sections)-1].Body = latexBlocks(current)
+
+	for _, b := range header {
+		if b.Kind == Paragraph || b.Kind == ListItem {
+			for _, line := range strings.Split(b.Text, "\n") {
+				data.Header = append(data.Header, latexLine(Spans(line)))
+			}
+		}
+	}
+	return data
+}
+
+// latexBlocks renders a section's blocks with standard LaTeX; templates
+// style them through the preamble or class
+func latexBlocks(blocks []Block) string {
+	var b strings.Builder
+	// lists holds the environment of each open list level
+	var lists []string
+	closeLists := func(depth int) {
+		for len(lists) > depth {
+			fmt.Fprintf(&b, "\\end{%s}\n", lists[len(lists)-1])
+			lists = lists[:len(lists)-1]
+		}
+	}
+
+	for _, block := range blocks {
+		if block.Kind != ListItem {
+			closeLists(0)
+		}
+		switch block.Kind {
+		case Heading:
+			command := "subsection"
+			if block.Level > 3 {
+				command = "subsubsection"
+			}
+			fmt.Fprintf(&b, "\\%s*{%s}\n", command, latexSpans(block.Spans()))
+
+		case Paragraph:
+			var lines []string
+			for _, line := range strings.Split(block.Text, "\n") {
+				lines = append(lines, latexLine(Spans(line)))
+			}
+			b.WriteString(strings.Join(lines, "\\\\\n") + "\n\n")
+
+		case ListItem:
+			env := "itemize"
+			if block.Ordered {
+				env = "enumerate"
+			}
+			closeLists(block.Level + 1)
+			if len(lists) == block.Level+1 && lists[block.Level] != env {
+				closeLists(block.Level)
+			}
+			for len(lists) <= block.Level {
+				fmt.Fprintf(&b, "\\begin{%s}\n", env)
+				lists = append(lists, env)
+			}
+			if block.Ordered {
+				// Keep the numbers as written
+				fmt.Fprintf(&b, "  \\item[%d.] %s\n", block.Number, latexLine(block.Spans()))
+			} else {
+				fmt.Fprintf(&b, "  \\item %s\n", latexLine(block.Spans()))
+			}
+
+		case Rule:
+			b.WriteString("\\noindent\\rule{\\linewidth}{0.4pt}\n\n")
+
+		case Code:
+			// verbatim ends at the first \end{verbatim}, so that can't
+			// appear inside
+			text := strings.ReplaceAll(block.Text, `\end{verbatim}`, `\end {verbatim}`)
+			b.WriteString("\\begin{verbatim}\n" + text + "\n\\end{verbatim}\n\n")
+		}
+	}
+	closeLists(0)
+	return strings.TrimSpace(b.String())
+}
+
+// latexSpans renders styled runs; links become \href
+func latexSpans(spans []Span) string {
+	var b strings.Builder
+	for _, s := range spans {
+		text := latexEscape(s.Text)
+		if s.Code {
+			text = `\texttt{` + text + `}`
+		}
+		if s.Italic {
+			text = `\textit{` + text + `}`
+		}
+		if s.Bold {
+			text = `\textbf{` + text + `}`
+		}
+		if s.URL != "" && markdown.SafeURL(s.URL) {
+			text = `\href{` + latexURL(strings.TrimSpace(s.URL)) + `}{` + text + `}`
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+// latexLine renders a line that may follow \item or \\, which would take a
+// leading [ or * as part of the command
+func latexLine(spans []Span) string {
+	s := latexSpans(spans)
+	if strings.HasPrefix(s, "[") || strings.HasPrefix(s, "*") {
+		return "{}" + s
+	}
+	return s
+}
+
+// latexEscaper escapes the characters LaTeX treats specially in text
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`$`, `\$`,
+	`&`, `\&`,
+	`#`, `\#`,
+	`%`, `\%`,
+	`_`, `\_`,
+	`^`, `\textasciicircum{}`,
+	`~`, `\textasciitilde{}`,
+	`<`, `\textless{}`,
+	`>`, `\textgreater{}`,
+	`|`, `\textbar{}`,
+	"\x00", "",
+)
+
+func latexEscape(s string) string {
+	return latexEscaper.Replace(s)
+}
+
+// latexURLEscaper escapes a URL for \href, which takes most characters
+// verbatim but not these
+var latexURLEscaper = strings.NewReplacer(
+	`\`, `%5C`,
+	`{`, `%7B`,
+	`}`, `%7D`,
+	`%`, `\%`,
+	`#`, `\#`,
+)
+
+func latexURL(url string) string {
+	return latexURLEscaper.Replace(url)
+}
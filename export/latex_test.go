@@ -0,0 +1,221 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// renderLaTeX renders src with the named template on letter paper
+func renderLaTeX(t *testing.T, src, name string) string {
+	t.Helper()
+	tmpl, ok := LaTeXTemplateByName(name)
+	if !ok {
+		t.Fatalf("no LaTeX template %q", name)
+	}
+	out, err := LaTeX(Parse(src), tmpl, PageSizes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestLaTeXEscapesSpecialCharacters(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`Cut costs by 30% & more`, `Cut costs by 30\% \& more`},
+		{`Issue #42 for $5`, `Issue \#42 for \$5`},
+		{`snake_case and {braces}`, `snake\_case and \{braces\}`},
+		{`~home and x^2`, `\textasciitilde{}home and x\textasciicircum{}2`},
+		{`C:\Users\jane`, `C:\textbackslash{}Users\textbackslash{}jane`},
+		{`a < b > c | d`, `a \textless{} b \textgreater{} c \textbar{} d`},
+		{"all: # $ % & _ { } ~ ^ \\", `all: \# \$ \% \& \_ \{ \} \textasciitilde{} \textasciicircum{} \textbackslash{}`},
+		{"nul\x00byte", `nulbyte`},
+	}
+	for _, tt := range tests {
+		for _, tmpl := range LaTeXTemplates {
+			out := renderLaTeX(t, "# Jane Doe\n\n## Notes\n\n"+tt.in+"\n", tmpl.Name)
+			if !strings.Contains(out, "\n"+tt.want+"\n") {
+				t.Errorf("%s: %q rendered without %q:\n%s", tmpl.Name, tt.in, tt.want, out)
+			}
+		}
+	}
+}
+
+func TestLaTeXEscapesTitle(t *testing.T) {
+	out := renderLaTeX(t, "# Jane_Doe & {Co}\n\n## Skills\n\nGo\n", "moderncv")
+	for _, want := range []string{`\name{Jane\_Doe \& \{Co\}}`, `pdftitle={Jane\_Doe \& \{Co\}}`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("title rendered without %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestLaTeXEscapesURLs(t *testing.T) {
+	tests := []struct {
+		link, want string
+	}{
+		{`[site](https://example.com/a%20b)`, `\href{https://example.com/a\%20b}{site}`},
+		{`[anchor](https://example.com/page#work)`, `\href{https://example.com/page\#work}{anchor}`},
+		{`[both](https://example.com/?q=a%2Fb#top)`, `\href{https://example.com/?q=a\%2Fb\#top}{both}`},
+		{`[slash](https://example.com/a\b)`, `\href{https://example.com/a%5Cb}{slash}`},
+		{`[braces](https://example.com/{id})`, `\href{https://example.com/%7Bid%7D}{braces}`},
+		{`[under_score](https://example.com/a_b)`, `\href{https://example.com/a_b}{under\_score}`},
+		{`[mail](mailto:jane@example.com)`, `\href{mailto:jane@example.com}{mail}`},
+		// Unsafe links keep their text and lose the link
+		{`[click](javascript:alert)`, `click`},
+	}
+	for _, tt := range tests {
+		out := renderLaTeX(t, "# Jane Doe\n\n## Links\n\n"+tt.link+"\n", "article")
+		if !strings.Contains(out, "\n"+tt.want+"\n") {
+			t.Errorf("%s rendered without %q:\n%s", tt.link, tt.want, out)
+		}
+	}
+}
+
+// awkwardResume exercises every block type and special character that
+// could unbalance the output
+const awkwardResume = `# Jane {Doe}
+
+jane@example.com | [github.com/jane](https://github.com/jane%7E#top)
+[Portfolio] } {
+
+## Experience
+
+### Staff Engineer } Acme {
+
+- Cut costs 30% & shipped {v2}
+  - Nested ~ ^ \ item
+1. Ordered [first
+2. Ordered second }
+
+**Bold {** and *italic }* and ` + "`code {`" + `
+
+---
+
+## Skills } {
+
+Go, Rust, C++ {
+`
+
+// checkBalanced fails unless every brace group and environment in tex is
+// closed. Escaped characters and comments are skipped.
+func checkBalanced(t *testing.T, name, tex string) {
+	t.Helper()
+	depth := 0
+	for i := 0; i < len(tex); i++ {
+		switch tex[i] {
+		case '\\':
+			i++
+		case '%':
+			for i < len(tex) && tex[i] != '\n' {
+				i++
+			}
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth < 0 {
+				t.Fatalf("%s: unmatched } at byte %d:\n%s", name, i, tex)
+			}
+		}
+	}
+	if depth != 0 {
+		t.Fatalf("%s: %d unclosed {:\n%s", name, depth, tex)
+	}
+
+	var envs []string
+	for _, m := range environmentRe.FindAllStringSubmatch(tex, -1) {
+		if m[1] == "begin" {
+			envs = append(envs, m[2])
+			continue
+		}
+		if len(envs) == 0 || envs[len(envs)-1] != m[2] {
+			t.Fatalf("%s: \\end{%s} doesn't match open environments %v:\n%s", name, m[2], envs, tex)
+		}
+		envs = envs[:len(envs)-1]
+	}
+	if len(envs) > 0 {
+		t.Fatalf("%s: unclosed environments %v:\n%s", name, envs, tex)
+	}
+}
+
+var environmentRe = regexp.MustCompile(`\\(begin|end)\{([^}]*)\}`)
+
+func TestLaTeXTemplatesAreBalanced(t *testing.T) {
+	for _, tmpl := range LaTeXTemplates {
+		for _, src := range []string{awkwardResume, "", "Just a line { with no headings"} {
+			for _, size := range PageSizes {
+				out, err := LaTeX(Parse(src), tmpl, size)
+				if err != nil {
+					t.Fatal(err)
+				}
+				checkBalanced(t, tmpl.Name+"/"+size.Name, string(out))
+			}
+		}
+	}
+}
+
+func TestLaTeXClassIsBalanced(t *testing.T) {
+	for _, tmpl := range LaTeXTemplates {
+		if tmpl.Class == "" {
+			continue
+		}
+		class, err := latexFiles.ReadFile("latex/" + tmpl.Class + ".cls")
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkBalanced(t, tmpl.Class+".cls", string(class))
+	}
+}
+
+func TestLaTeXPaperSize(t *testing.T) {
+	for _, size := range PageSizes {
+		for _, tmpl := range LaTeXTemplates {
+			out, err := LaTeX(Parse("# Jane Doe\n"), tmpl, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := `\documentclass[11pt,` + latexPapers[size.Name] + `]`
+			if !strings.Contains(string(out), want) {
+				t.Errorf("%s on %s: no %q in\n%s", tmpl.Name, size.Name, want, out)
+			}
+		}
+	}
+}
+
+func TestLaTeXZipShipsClass(t *testing.T) {
+	tmpl, _ := LaTeXTemplateByName("moderncv")
+	data, err := LaTeXZip(Parse(awkwardResume), tmpl, PageSizes[0], "resume.tex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Name == "resume.tex" && strings.Contains(string(content), "filecontents") {
+			t.Error("zipped .tex writes out the class it ships alongside")
+		}
+		checkBalanced(t, f.Name, string(content))
+	}
+	if strings.Join(names, ",") != "resume.tex,tweakcv.cls" {
+		t.Errorf("zip holds %v, want resume.tex and tweakcv.cls", names)
+	}
+}
@@ -0,0 +1,223 @@
+package export
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/johnhkchen/resume-tweaker/markdown"
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// pdfMargin is the page margin in points (0.75in)
+const pdfMargin = 54
+
+// pdfWriter lays out a Document with gofpdf
+type pdfWriter struct {
+	pdf  *gofpdf.Fpdf
+	tmpl Template
+	// body and mono are font families; encode converts text for them
+	body, mono string
+	encode     func(string) string
+	lineHeight float64
+}
+
+// PDF renders a resume to PDF. The built-in PDF fonts (Times, Helvetica,
+// Courier) are used when the text fits their Windows-1252 encoding; anything
+// else switches to the bundled Go fonts, embedded as Unicode TrueType.
+// Nothing is fetched over the network.
+func PDF(doc Document, tmpl Template, size PageSize) ([]byte, error) {
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		UnitStr: "pt",
+		Size:    gofpdf.SizeType{Wd: size.Width, Ht: size.Height},
+	})
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.SetCreator("Resume Tweaker", true)
+	if doc.Title != "" {
+		pdf.SetTitle(doc.Title, true)
+	}
+
+	w := &pdfWriter{pdf: pdf, tmpl: tmpl, lineHeight: tmpl.BodySize * tmpl.LineHeight}
+	if fitsWindows1252(doc) {
+		w.body, w.mono = "Helvetica", "Courier"
+		if tmpl.Serif {
+			w.body = "Times"
+		}
+		w.encode = pdf.UnicodeTranslatorFromDescriptor("")
+	} else {
+		pdf.AddUTF8FontFromBytes("go", "", goregular.TTF)
+		pdf.AddUTF8FontFromBytes("go", "B", gobold.TTF)
+		pdf.AddUTF8FontFromBytes("go", "I", goitalic.TTF)
+		pdf.AddUTF8FontFromBytes("go", "BI", gobolditalic.TTF)
+		pdf.AddUTF8FontFromBytes("gomono", "", gomono.TTF)
+		w.body, w.mono = "go", "gomono"
+		w.encode = func(s string) string { return s }
+	}
+
+	pdf.AddPage()
+	prev := Block{Kind: -1}
+	for _, b := range doc.Blocks {
+		w.block(b, prev)
+		prev = b
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (w *pdfWriter) block(b Block, prev Block) {
+	pdf := w.pdf
+	left, _, right, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	size := w.tmpl.BodySize
+
+	// Space between blocks; list items and paragraph lines stay tight
+	if prev.Kind >= 0 && !(b.Kind == ListItem && prev.Kind == ListItem) {
+		pdf.Ln(size * 0.6)
+	}
+
+	switch b.Kind {
+	case Heading:
+		w.heading(b, prev)
+
+	case Paragraph:
+		for i, line := range strings.Split(b.Text, "\n") {
+			if i > 0 {
+				pdf.Ln(w.lineHeight)
+			}
+			w.spans(Spans(line), size)
+		}
+		pdf.Ln(w.lineHeight)
+
+	case ListItem:
+		indent := left + float64(b.Level)*size*1.5
+		marker := "•"
+		if b.Ordered {
+			marker = strconv.Itoa(b.Number) + "."
+		}
+		pdf.SetFont(w.body, "", size)
+		markerWidth := size * 1.2
+		if b.Ordered {
+			markerWidth = size * 1.8
+		}
+		pdf.SetX(indent)
+		pdf.CellFormat(markerWidth, w.lineHeight, w.encode(marker), "", 0, "L", false, 0, "")
+		// Wrapped lines hang under the text, not the marker
+		pdf.SetLeftMargin(indent + markerWidth)
+		w.spans(b.Spans(), size)
+		pdf.SetLeftMargin(left)
+		pdf.Ln(w.lineHeight)
+
+	case Rule:
+		y := pdf.GetY() + size*0.3
+		pdf.SetDrawColor(0xB0, 0xB0, 0xB0)
+		pdf.SetLineWidth(0.5)
+		pdf.Line(left, y, pageWidth-right, y)
+		pdf.Ln(size * 0.6)
+
+	case Code:
+		pdf.SetFont(w.mono, "", size*0.9)
+		for _, line := range strings.Split(b.Text, "\n") {
+			pdf.MultiCell(0, w.lineHeight, w.encode(line), "", "L", false)
+		}
+	}
+}
+
+func (w *pdfWriter) heading(b Block, prev Block) {
+	pdf := w.pdf
+	left, _, right, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	text := PlainText(b.Text)
+	accent := w.tmpl.Accent
+
+	switch b.Level {
+	case 1:
+		pdf.SetFont(w.body, "B", w.tmpl.NameSize)
+		pdf.SetTextColor(accent[0], accent[1], accent[2])
+		align := "L"
+		if w.tmpl.CenterName {
+			align = "C"
+		}
+		pdf.MultiCell(0, w.tmpl.NameSize*1.2, w.encode(text), "", align, false)
+		pdf.SetTextColor(0, 0, 0)
+
+	case 2:
+		if prev.Kind >= 0 {
+			pdf.Ln(w.tmpl.BodySize * 0.4)
+		}
+		size := w.tmpl.BodySize * 1.25
+		if w.tmpl.UppercaseSections {
+			text = strings.ToUpper(text)
+			size = w.tmpl.BodySize * 1.1
+		}
+		pdf.SetFont(w.body, "B", size)
+		pdf.SetTextColor(accent[0], accent[1], accent[2])
+		pdf.MultiCell(0, size*1.3, w.encode(text), "", "L", false)
+		pdf.SetTextColor(0, 0, 0)
+		if w.tmpl.SectionRule {
+			y := pdf.GetY() + 1
+			pdf.SetDrawColor(accent[0], accent[1], accent[2])
+			pdf.SetLineWidth(0.75)
+			pdf.Line(left, y, pageWidth-right, y)
+			pdf.Ln(w.tmpl.BodySize * 0.4)
+		}
+
+	default:
+		size := w.tmpl.BodySize * 1.05
+		pdf.SetFont(w.body, "B", size)
+		pdf.MultiCell(0, size*w.tmpl.LineHeight, w.encode(text), "", "L", false)
+	}
+}
+
+// spans writes styled runs, wrapping at the right margin
+func (w *pdfWriter) spans(spans []Span, size float64) {
+	pdf := w.pdf
+	for _, s := range spans {
+		family, style := w.body, ""
+		if s.Bold {
+			style += "B"
+		}
+		if s.Italic {
+			style += "I"
+		}
+		if s.Code {
+			family, style = w.mono, ""
+		}
+		pdf.SetFont(family, style, size)
+
+		text := w.encode(s.Text)
+		if s.URL != "" && markdown.SafeURL(s.URL) {
+			pdf.SetTextColor(0x1F, 0x4E, 0x79)
+			pdf.WriteLinkString(w.lineHeight, text, s.URL)
+			pdf.SetTextColor(0, 0, 0)
+			continue
+		}
+		pdf.Write(w.lineHeight, text)
+	}
+}
+
+// fitsWindows1252 reports whether every character of the document can be
+// set in the built-in PDF fonts
+func fitsWindows1252(doc Document) bool {
+	for _, b := range doc.Blocks {
+		for _, r := range b.Text {
+			if r < 0x80 {
+				continue
+			}
+			if _, ok := charmap.Windows1252.EncodeRune(r); !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
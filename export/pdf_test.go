@@ -0,0 +1,161 @@
+package export
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// pdfResume exercises every kind of block and span the PDF writer sets
+const pdfResume = `# Jane Doe
+
+jane@example.com | [github.com/janedoe](https://github.com/janedoe)
+
+## Experience
+
+### Staff Engineer, Acme Corp
+
+*2020 - Present*
+
+- Built the **billing platform** in ` + "`Go`" + `
+- Cut costs by 30% & more
+
+## Skills
+
+Go, PostgreSQL, Kubernetes
+`
+
+// renderPDF renders src and opens the result with a PDF reader
+func renderPDF(t *testing.T, src string, tmpl Template, size PageSize) *pdf.Reader {
+	t.Helper()
+	out, err := PDF(Parse(src), tmpl, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := pdf.NewReader(bytes.NewReader(out), int64(len(out)))
+	if err != nil {
+		t.Fatalf("rendered PDF doesn't open: %v", err)
+	}
+	return r
+}
+
+// pageText joins the text runs of every page, one page per line
+func pageText(t *testing.T, r *pdf.Reader) string {
+	t.Helper()
+	var b strings.Builder
+	for i := 1; i <= r.NumPage(); i++ {
+		for _, run := range r.Page(i).Content().Text {
+			b.WriteString(run.S)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func TestPDFTextLayer(t *testing.T) {
+	for _, tmpl := range Templates {
+		t.Run(tmpl.Name, func(t *testing.T) {
+			text := pageText(t, renderPDF(t, pdfResume, tmpl, PageSizes[0]))
+			experience := "Experience"
+			if tmpl.UppercaseSections {
+				experience = "EXPERIENCE"
+			}
+			for _, want := range []string{
+				"Jane Doe",
+				"jane@example.com",
+				"github.com/janedoe",
+				experience,
+				"Staff Engineer, Acme Corp",
+				"2020 - Present",
+				"billing platform",
+				"Cut costs by 30% & more",
+				"Go, PostgreSQL, Kubernetes",
+			} {
+				if !strings.Contains(text, want) {
+					t.Errorf("text layer has no %q:\n%s", want, text)
+				}
+			}
+			if strings.Contains(text, "**") || strings.Contains(text, "](") {
+				t.Errorf("text layer has markdown syntax:\n%s", text)
+			}
+		})
+	}
+}
+
+func TestPDFTextLayerUnicode(t *testing.T) {
+	// Ł is outside Windows-1252, so the document is set in the embedded Go
+	// fonts. The reader applies a ToUnicode range by bumping only the last
+	// byte, so it misreads code points above U+00FF; check the rest.
+	src := "# Zoë Łukasiewicz\n\n## Experience\n\n- Led the Kraków team\n"
+	r := renderPDF(t, src, Templates[0], PageSizes[0])
+
+	fonts := r.Page(1).Resources().Key("Font")
+	for _, name := range fonts.Keys() {
+		font := fonts.Key(name)
+		if font.Key("Subtype").Name() != "Type0" || font.Key("ToUnicode").IsNull() {
+			t.Errorf("font %s is %s, want embedded Unicode with a ToUnicode map", font.Key("BaseFont").Name(), font.Key("Subtype").Name())
+		}
+	}
+	text := pageText(t, r)
+	for _, want := range []string{"Zoë", "ukasiewicz", "Led the Kraków team"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text layer has no %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestPDFLinks(t *testing.T) {
+	src := "# Jane Doe\n\n[github.com/janedoe](https://github.com/janedoe) and [a trap](javascript:alert)\n"
+	r := renderPDF(t, src, Templates[0], PageSizes[0])
+
+	var uris []string
+	annots := r.Page(1).V.Key("Annots")
+	for i := range annots.Len() {
+		if uri := annots.Index(i).Key("A").Key("URI"); !uri.IsNull() {
+			uris = append(uris, uri.RawString())
+		}
+	}
+	if len(uris) != 1 || uris[0] != "https://github.com/janedoe" {
+		t.Errorf("link annotations = %q, want only the https link", uris)
+	}
+	if text := pageText(t, r); !strings.Contains(text, "a trap") {
+		t.Errorf("unsafe link lost its text:\n%s", text)
+	}
+}
+
+// mediaBox returns a page's MediaBox, which it may inherit from its parents
+func mediaBox(p pdf.Page) pdf.Value {
+	for v := p.V; !v.IsNull(); v = v.Key("Parent") {
+		if box := v.Key("MediaBox"); !box.IsNull() {
+			return box
+		}
+	}
+	return pdf.Value{}
+}
+
+func TestPDFPageSize(t *testing.T) {
+	// Long enough to break onto further pages, which must keep the size
+	long := pdfResume + strings.Repeat("\n- Shipped another feature on time and under budget\n", 120)
+	for _, size := range PageSizes {
+		t.Run(size.Name, func(t *testing.T) {
+			r := renderPDF(t, long, Templates[0], size)
+			if r.NumPage() < 2 {
+				t.Fatalf("rendered %d pages, want the resume to break", r.NumPage())
+			}
+			for i := 1; i <= r.NumPage(); i++ {
+				box := mediaBox(r.Page(i))
+				if box.Len() != 4 {
+					t.Fatalf("page %d has no MediaBox", i)
+				}
+				width := box.Index(2).Float64() - box.Index(0).Float64()
+				height := box.Index(3).Float64() - box.Index(1).Float64()
+				if math.Abs(width-size.Width) > 0.01 || math.Abs(height-size.Height) > 0.01 {
+					t.Errorf("page %d is %.2f x %.2f, want %.2f x %.2f", i, width, height, size.Width, size.Height)
+				}
+			}
+		})
+	}
+}
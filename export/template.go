@@ -0,0 +1,94 @@
+package export
+
+// Template is a typographic style for an exported resume
+type Template struct {
+	Name  string
+	Label string
+	// Serif selects a serif typeface over a sans-serif one
+	Serif bool
+	// BodySize and NameSize are font sizes in points
+	BodySize float64
+	NameSize float64
+	// LineHeight is the line spacing as a multiple of BodySize
+	LineHeight float64
+	// Accent colors the name and section headings (RGB)
+	Accent [3]int
+	// CenterName centers the level-1 heading
+	CenterName bool
+	// SectionRule draws a line under each level-2 heading
+	SectionRule bool
+	// UppercaseSections sets level-2 headings in capitals
+	UppercaseSections bool
+}
+
+// Templates lists the available templates; the first is the default
+var Templates = []Template{
+	{
+		Name:              "classic",
+		Label:             "Classic (serif)",
+		Serif:             true,
+		BodySize:          11,
+		NameSize:          20,
+		LineHeight:        1.3,
+		CenterName:        true,
+		SectionRule:       true,
+		UppercaseSections: true,
+	},
+	{
+		Name:        "modern",
+		Label:       "Modern (sans-serif)",
+		BodySize:    10.5,
+		NameSize:    22,
+		LineHeight:  1.35,
+		Accent:      [3]int{0x6B, 0x90, 0x80},
+		SectionRule: true,
+	},
+	{
+		Name:       "compact",
+		Label:      "Compact (fits more on a page)",
+		BodySize:   9.5,
+		NameSize:   16,
+		LineHeight: 1.2,
+	},
+}
+
+// TemplateByName looks up a template, returning the default for ""
+func TemplateByName(name string) (Template, bool) {
+	if name == "" {
+		return Templates[0], true
+	}
+	for _, t := range Templates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// PageSize is a paper size, in points
+type PageSize struct {
+	Name   string
+	Label  string
+	Width  float64
+	Height float64
+}
+
+// PageSizes lists the available paper sizes; the first is the default
+var PageSizes = []PageSize{
+	{Name: "letter", Label: "US Letter", Width: 612, Height: 792},
+	{Name: "a4", Label: "A4", Width: 595.28, Height: 841.89},
+	{Name: "legal", Label: "US Legal", Width: 612, Height: 1008},
+}
+
+// PageSizeByName looks up a paper size, returning the default for ""
+func PageSizeByName(name string) (PageSize, bool) {
+	if name == "" {
+		return PageSizes[0], true
+	}
+	for _, s := range PageSizes {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return PageSize{}, false
+}
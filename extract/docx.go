@@ -0,0 +1,740 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxPartSize caps how much of any one part of a DOCX package is inflated,
+// so a zip bomb can't exhaust memory. A resume's document.xml is rarely
+// over a few hundred KB; images live in separate parts.
+const maxPartSize = 4 << 20
+
+// node is a generic XML element, used for the small parts (styles,
+// numbering, relationships) that are read whole. Names are matched on their
+// local part; WordprocessingML doesn't reuse local names across namespaces
+// in ways that matter here.
+type node struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []node     `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+func (n *node) attr(local string) string {
+	return attr(n.Attrs, local)
+}
+
+// child returns the first direct child with the given local name
+func (n *node) child(local string) *node {
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Local == local {
+			return &n.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// val returns the w:val of a child element, e.g. <w:pStyle w:val="Heading1"/>
+func (n *node) val(local string) (string, bool) {
+	if n == nil {
+		return "", false
+	}
+	c := n.child(local)
+	if c == nil {
+		return "", false
+	}
+	return c.attr("val"), true
+}
+
+func attr(attrs []xml.Attr, local string) string {
+	for _, a := range attrs {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// docx holds the parts of a package needed to convert its body
+type docx struct {
+	files     map[string]*zip.File
+	styles    map[string]*style
+	numbering map[string]map[int]string // numId -> level -> numFmt
+	links     map[string]string         // relationship id -> target URL
+	// counters tracks ordered list numbering per list and level
+	counters map[string][]int
+}
+
+type style struct {
+	basedOn string
+	// heading is 1 for Title, 2 for Heading 1 and so on; 0 if not a heading
+	heading int
+	numID   string
+	ilvl    int
+}
+
+// DOCX converts a Word document to markdown: headings become # lines,
+// numbered and bulleted paragraphs become nested lists, hyperlinks become
+// [text](url), and tables are flattened row by row. Header text (where
+// contact details often live) comes first.
+//
+// The document body and headers are converted as their XML is decoded, so
+// memory use doesn't grow with the document.
+func DOCX(data []byte) (Result, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrUnreadable, err)
+	}
+	d := &docx{
+		files:     map[string]*zip.File{},
+		styles:    map[string]*style{},
+		numbering: map[string]map[int]string{},
+		links:     map[string]string{},
+		counters:  map[string][]int{},
+	}
+	for _, f := range zr.File {
+		d.files[f.Name] = f
+	}
+	if d.files["word/document.xml"] == nil {
+		return Result{}, fmt.Errorf("%w: missing document body", ErrUnreadable)
+	}
+
+	if n, _ := d.readPart("word/styles.xml"); n != nil {
+		d.loadStyles(n)
+	}
+	if n, _ := d.readPart("word/numbering.xml"); n != nil {
+		d.loadNumbering(n)
+	}
+
+	var blocks []string
+	if n, _ := d.readPart("word/_rels/document.xml.rels"); n != nil {
+		headers := d.loadRels(n)
+		seen := map[string]bool{}
+		for _, target := range headers {
+			// Targets are relative to word/ unless absolute
+			name := strings.TrimPrefix(target, "/")
+			if !strings.HasPrefix(target, "/") {
+				name = path.Join("word", target)
+			}
+			h, err := d.streamPart(name, "hdr")
+			if err != nil {
+				continue
+			}
+			text := joinBlocks(h)
+			if strings.TrimSpace(text) != "" && !seen[text] {
+				seen[text] = true
+				blocks = append(blocks, text)
+			}
+		}
+	}
+
+	body, err := d.streamPart("word/document.xml", "body")
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrUnreadable, err)
+	}
+	blocks = append(blocks, body...)
+
+	text := joinBlocks(blocks)
+	if text == "" {
+		return Result{}, ErrNoText
+	}
+	return Result{Text: text}, nil
+}
+
+// openPart opens one part of the package, capped at maxPartSize, or returns
+// nil if it's absent
+func (d *docx) openPart(name string) (io.ReadCloser, error) {
+	f, ok := d.files[name]
+	if !ok {
+		return nil, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &cappedReader{ReadCloser: rc, name: name, left: maxPartSize}, nil
+}
+
+// readPart parses one small XML part whole, or returns nil if it's absent
+func (d *docx) readPart(name string) (*node, error) {
+	rc, err := d.openPart(name)
+	if rc == nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var n node
+	if err := xml.NewDecoder(rc).Decode(&n); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// streamPart converts the blocks inside the first container element of a
+// part (w:body in the document, w:hdr in a header) as they are decoded
+func (d *docx) streamPart(name, container string) ([]string, error) {
+	rc, err := d.openPart(name)
+	if rc == nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == container {
+			return d.blocks(dec)
+		}
+	}
+}
+
+// cappedReader fails once more than left bytes are read, rather than
+// quietly truncating the part
+type cappedReader struct {
+	io.ReadCloser
+	name string
+	left int64
+}
+
+func (r *cappedReader) Read(p []byte) (int, error) {
+	if r.left <= 0 {
+		// Allow a part of exactly the cap
+		if n, err := r.ReadCloser.Read(make([]byte, 1)); n == 0 && err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("%s is over %d MB", r.name, maxPartSize>>20)
+	}
+	if int64(len(p)) > r.left {
+		p = p[:r.left]
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.left -= int64(n)
+	return n, err
+}
+
+func (d *docx) loadStyles(root *node) {
+	for _, s := range root.Nodes {
+		if s.XMLName.Local != "style" || s.attr("type") != "paragraph" {
+			continue
+		}
+		st := &style{}
+		st.basedOn, _ = s.val("basedOn")
+		name, _ := s.val("name")
+		name = strings.ToLower(name)
+		switch {
+		case name == "title":
+			st.heading = 1
+		case strings.HasPrefix(name, "heading "):
+			if n, err := strconv.Atoi(strings.TrimPrefix(name, "heading ")); err == nil && n >= 1 {
+				st.heading = n + 1
+			}
+		}
+		if ppr := s.child("pPr"); ppr != nil {
+			if st.heading == 0 {
+				if lvl, ok := ppr.val("outlineLvl"); ok {
+					if n, err := strconv.Atoi(lvl); err == nil && n < 9 {
+						st.heading = n + 2
+					}
+				}
+			}
+			if numPr := ppr.child("numPr"); numPr != nil {
+				st.numID, _ = numPr.val("numId")
+				lvl, _ := numPr.val("ilvl")
+				st.ilvl, _ = strconv.Atoi(lvl)
+			}
+		}
+		d.styles[s.attr("styleId")] = st
+	}
+}
+
+func (d *docx) loadNumbering(root *node) {
+	abstract := map[string]map[int]string{}
+	for _, a := range root.Nodes {
+		if a.XMLName.Local != "abstractNum" {
+			continue
+		}
+		levels := map[int]string{}
+		for _, lvl := range a.Nodes {
+			if lvl.XMLName.Local != "lvl" {
+				continue
+			}
+			i, _ := strconv.Atoi(lvl.attr("ilvl"))
+			levels[i], _ = lvl.val("numFmt")
+		}
+		abstract[a.attr("abstractNumId")] = levels
+	}
+	for _, n := range root.Nodes {
+		if n.XMLName.Local != "num" {
+			continue
+		}
+		if id, ok := n.val("abstractNumId"); ok {
+			d.numbering[n.attr("numId")] = abstract[id]
+		}
+	}
+}
+
+// loadRels records hyperlink targets and returns the header parts in order
+func (d *docx) loadRels(root *node) []string {
+	var headers []string
+	for _, r := range root.Nodes {
+		kind := path.Base(r.attr("Type"))
+		switch kind {
+		case "hyperlink":
+			d.links[r.attr("Id")] = r.attr("Target")
+		case "header":
+			headers = append(headers, r.attr("Target"))
+		}
+	}
+	return headers
+}
+
+// styleOf resolves a paragraph style through its basedOn chain
+func (d *docx) styleOf(id string) style {
+	var out style
+	headingSet, numSet := false, false
+	for depth := 0; id != "" && depth < 10; depth++ {
+		st, ok := d.styles[id]
+		if !ok {
+			break
+		}
+		if !headingSet && st.heading > 0 {
+			out.heading, headingSet = st.heading, true
+		}
+		if !numSet && st.numID != "" {
+			out.numID, out.ilvl, numSet = st.numID, st.ilvl, true
+		}
+		id = st.basedOn
+	}
+	return out
+}
+
+// blocks converts the block-level elements up to the end of the enclosing
+// element. List items are returned with a leading marker so joinBlocks
+// keeps them together.
+func (d *docx) blocks(dec *xml.Decoder) ([]string, error) {
+	var out []string
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			return out, nil
+		case xml.StartElement:
+			var more []string
+			switch t.Name.Local {
+			case "p":
+				var b string
+				if b, err = d.paragraph(dec); b != "" {
+					more = []string{b}
+				}
+			case "tbl":
+				more, err = d.table(dec)
+			case "sdt", "sdtContent", "customXml", "ins", "smartTag":
+				// Wrappers; their properties (sdtPr and so on) are skipped
+				// as unknown blocks
+				more, err = d.blocks(dec)
+			default:
+				err = dec.Skip()
+			}
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, more...)
+		}
+	}
+}
+
+// listMarker flags a block as a list item for joinBlocks
+const listMarker = "\x00"
+
+// paraProps are the paragraph properties that shape its markdown
+type paraProps struct {
+	style, outlineLvl, numID, ilvl string
+	hasOutline, hasNumID, hasIlvl  bool
+}
+
+func (d *docx) paragraph(dec *xml.Decoder) (string, error) {
+	var props paraProps
+	var b strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		if _, ok := tok.(xml.EndElement); ok {
+			break
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local == "pPr" {
+			err = readParaProps(dec, &props)
+		} else {
+			err = d.inlineElement(dec, start, &b)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	st := d.styleOf(props.style)
+	heading := st.heading
+	if props.hasOutline {
+		if n, err := strconv.Atoi(props.outlineLvl); err == nil && n < 9 {
+			heading = n + 2
+		}
+	}
+	numID, ilvl := st.numID, st.ilvl
+	if props.hasNumID {
+		numID = props.numID
+	}
+	if props.hasIlvl {
+		ilvl, _ = strconv.Atoi(props.ilvl)
+	}
+
+	text := strings.TrimSpace(b.String())
+	if text == "" {
+		return "", nil
+	}
+
+	if heading > 0 {
+		// Headings are plain text; emphasis markers inside them are noise
+		text = strings.NewReplacer("**", "", "*", "").Replace(text)
+		return strings.Repeat("#", min(heading, 6)) + " " + strings.ReplaceAll(text, "\n", " "), nil
+	}
+
+	// numId 0 explicitly turns numbering off
+	if numID != "" && numID != "0" {
+		ilvl = max(0, min(ilvl, 8))
+		indent := strings.Repeat("  ", ilvl)
+		marker := "- "
+		if format := d.numbering[numID][ilvl]; format != "" && format != "bullet" && format != "none" {
+			marker = strconv.Itoa(d.count(numID, ilvl)) + ". "
+		}
+		return listMarker + indent + marker + strings.ReplaceAll(text, "\n", " "), nil
+	}
+	return text, nil
+}
+
+// readParaProps reads the style, outline level and numbering of a w:pPr
+func readParaProps(dec *xml.Decoder, props *paraProps) error {
+	vals, err := childVals(dec, func(start xml.StartElement) error {
+		if start.Name.Local != "numPr" {
+			return dec.Skip()
+		}
+		num, err := childVals(dec, nil)
+		if err != nil {
+			return err
+		}
+		props.numID, props.hasNumID = num["numId"]
+		props.ilvl, props.hasIlvl = num["ilvl"]
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	props.style = vals["pStyle"]
+	props.outlineLvl, props.hasOutline = vals["outlineLvl"]
+	return nil
+}
+
+// childVals reads the children of a properties element up to its end,
+// returning each child's w:val (empty for toggles like <w:b/>). Children
+// with children of their own are passed to nested, or skipped if it's nil.
+func childVals(dec *xml.Decoder, nested func(xml.StartElement) error) (map[string]string, error) {
+	vals := map[string]string{}
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			return vals, nil
+		case xml.StartElement:
+			vals[t.Name.Local] = attr(t.Attr, "val")
+			if nested != nil {
+				err = nested(t)
+			} else {
+				err = dec.Skip()
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// toggled reports whether a toggle property like <w:b/> is set
+func toggled(vals map[string]string, local string) bool {
+	v, ok := vals[local]
+	return ok && v != "0" && v != "false" && v != "none"
+}
+
+// count advances and returns the number of the next item in a list level,
+// restarting deeper levels
+func (d *docx) count(numID string, ilvl int) int {
+	c := d.counters[numID]
+	for len(c) <= ilvl {
+		c = append(c, 0)
+	}
+	c[ilvl]++
+	for i := ilvl + 1; i < len(c); i++ {
+		c[i] = 0
+	}
+	d.counters[numID] = c
+	return c[ilvl]
+}
+
+// inline renders the runs and links up to the end of the enclosing element
+func (d *docx) inline(dec *xml.Decoder) (string, error) {
+	var b strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			return b.String(), nil
+		case xml.StartElement:
+			if err := d.inlineElement(dec, t, &b); err != nil {
+				return "", err
+			}
+		}
+	}
+}
+
+// inlineElement renders one run, link or wrapper of a paragraph
+func (d *docx) inlineElement(dec *xml.Decoder, start xml.StartElement, b *strings.Builder) error {
+	switch start.Name.Local {
+	case "r":
+		text, err := run(dec)
+		b.WriteString(text)
+		return err
+	case "hyperlink":
+		label, err := d.inline(dec)
+		if err != nil {
+			return err
+		}
+		label = strings.TrimSpace(label)
+		target := d.links[attr(start.Attr, "id")]
+		switch {
+		case label == "":
+		case target == "" || !linkable(target):
+			b.WriteString(label)
+		case strings.TrimPrefix(target, "mailto:") == label || strings.TrimSuffix(target, "/") == strings.TrimSuffix(label, "/"):
+			b.WriteString(label)
+		default:
+			b.WriteString("[" + label + "](" + target + ")")
+		}
+		return nil
+	case "ins", "smartTag", "customXml", "fldSimple", "sdt", "sdtContent":
+		text, err := d.inline(dec)
+		b.WriteString(text)
+		return err
+	}
+	// Deleted text (w:del), field instructions and properties are skipped
+	return dec.Skip()
+}
+
+// run renders a text run, wrapping bold and italic text in markers with any
+// surrounding spaces kept outside them
+func run(dec *xml.Decoder) (string, error) {
+	var b strings.Builder
+	var rpr map[string]string
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		if _, ok := tok.(xml.EndElement); ok {
+			break
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "rPr":
+			rpr, err = childVals(dec, nil)
+		case "t":
+			err = readText(dec, &b)
+		case "tab":
+			b.WriteString(" ")
+			err = dec.Skip()
+		case "br", "cr":
+			b.WriteString("\n")
+			err = dec.Skip()
+		case "noBreakHyphen":
+			b.WriteString("-")
+			err = dec.Skip()
+		default:
+			err = dec.Skip()
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	text := b.String()
+	core := strings.TrimSpace(text)
+	if core == "" {
+		return text, nil
+	}
+
+	marker := ""
+	switch {
+	case toggled(rpr, "b") && toggled(rpr, "i"):
+		marker = "***"
+	case toggled(rpr, "b"):
+		marker = "**"
+	case toggled(rpr, "i"):
+		marker = "*"
+	}
+	if marker == "" || strings.Contains(core, "\n") {
+		return text, nil
+	}
+	lead := text[:strings.Index(text, core)]
+	trail := text[len(lead)+len(core):]
+	return lead + marker + core + marker + trail, nil
+}
+
+// readText appends the character data of an element up to its end
+func readText(dec *xml.Decoder, b *strings.Builder) error {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			b.Write(t)
+		case xml.StartElement:
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// table flattens a table. A row of single-line cells becomes one line with
+// the cells separated by " | " (typically "Company | Role | Dates"); cells
+// holding more than that are emitted block by block. Rows are separate
+// blocks, so they never line up into a markdown pipe table, which neither
+// the renderer nor the tweak prompt expects.
+func (d *docx) table(dec *xml.Decoder) ([]string, error) {
+	var out []string
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			return out, nil
+		case xml.StartElement:
+			if t.Name.Local != "tr" {
+				if err := dec.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			row, err := d.tableRow(dec)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, row...)
+		}
+	}
+}
+
+func (d *docx) tableRow(dec *xml.Decoder) ([]string, error) {
+	var cells [][]string
+	simple := true
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := tok.(xml.EndElement); ok {
+			break
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "tc" {
+			if err := dec.Skip(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		blocks, err := d.blocks(dec)
+		if err != nil {
+			return nil, err
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		if len(blocks) > 1 || strings.HasPrefix(blocks[0], listMarker) || strings.HasPrefix(blocks[0], "#") || strings.Contains(blocks[0], "\n") {
+			simple = false
+		}
+		cells = append(cells, blocks)
+	}
+
+	if simple && len(cells) > 0 {
+		parts := make([]string, len(cells))
+		for j, c := range cells {
+			parts[j] = c[0]
+		}
+		return []string{strings.Join(parts, " | ")}, nil
+	}
+	var out []string
+	for _, c := range cells {
+		out = append(out, c...)
+	}
+	return out, nil
+}
+
+// joinBlocks separates blocks with blank lines, except between consecutive
+// list items
+func joinBlocks(blocks []string) string {
+	var b strings.Builder
+	prevItem := false
+	for i, block := range blocks {
+		item := strings.HasPrefix(block, listMarker)
+		block = strings.TrimPrefix(block, listMarker)
+		if i > 0 {
+			if item && prevItem {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		b.WriteString(block)
+		prevItem = item
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// linkable reports whether a hyperlink target is worth keeping as a link
+func linkable(target string) bool {
+	t := strings.ToLower(target)
+	return strings.HasPrefix(t, "http://") || strings.HasPrefix(t, "https://") || strings.HasPrefix(t, "mailto:")
+}
@@ -0,0 +1,178 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/johnhkchen/resume-tweaker/markdown"
+)
+
+const docxNS = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+
+// buildDOCX zips parts into a package
+func buildDOCX(t testing.TB, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+var resumeDOCX = map[string]string{
+	"word/document.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document ` + docxNS + `><w:body>
+<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr><w:r><w:rPr><w:b/></w:rPr><w:t>Jane Doe</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Site: </w:t></w:r><w:hyperlink r:id="rId1"><w:r><w:t>my portfolio</w:t></w:r></w:hyperlink><w:r><w:t xml:space="preserve"> and </w:t></w:r><w:hyperlink r:id="rId2"><w:r><w:t>https://github.com/janedoe</w:t></w:r></w:hyperlink></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Experience</w:t></w:r></w:p>
+<w:tbl><w:tblPr/><w:tr><w:tc><w:p><w:r><w:t>Acme</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Staff Engineer</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>2020 - Present</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>Highlights</w:t></w:r></w:p><w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Led billing</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+<w:p><w:pPr><w:pStyle w:val="ListBullet"/></w:pPr><w:r><w:t xml:space="preserve">Built the </w:t></w:r><w:r><w:rPr><w:b/><w:i/></w:rPr><w:t xml:space="preserve">payments API </w:t></w:r><w:del><w:r><w:delText>removed</w:delText></w:r></w:del><w:ins><w:r><w:t>in Go</w:t></w:r></w:ins></w:p>
+<w:p><w:pPr><w:pStyle w:val="ListBullet"/><w:numPr><w:ilvl w:val="1"/></w:numPr></w:pPr><w:r><w:rPr><w:i w:val="0"/></w:rPr><w:t>Nested</w:t></w:r><w:r><w:tab/><w:t>item</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t>First</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t>Second</w:t></w:r></w:p>
+<w:sdt><w:sdtPr><w:alias w:val="Skills"/></w:sdtPr><w:sdtContent><w:p><w:pPr><w:outlineLvl w:val="1"/></w:pPr><w:r><w:t>Skills</w:t></w:r></w:p><w:p><w:r><w:t>Go, SQL</w:t></w:r><w:r><w:br/><w:t>Kubernetes</w:t></w:r></w:p></w:sdtContent></w:sdt>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="0"/></w:numPr></w:pPr><w:r><w:t>Not a list</w:t></w:r><w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText>PAGE</w:instrText></w:r></w:p>
+<w:p/>
+<w:sectPr/>
+</w:body></w:document>`,
+	"word/styles.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<w:styles ` + docxNS + `>
+<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/></w:style>
+<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/></w:style>
+<w:style w:type="paragraph" w:styleId="ListParagraph"><w:name w:val="List Paragraph"/><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr></w:style>
+<w:style w:type="paragraph" w:styleId="ListBullet"><w:name w:val="List Bullet"/><w:basedOn w:val="ListParagraph"/></w:style>
+</w:styles>`,
+	"word/numbering.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<w:numbering ` + docxNS + `>
+<w:abstractNum w:abstractNumId="10"><w:lvl w:ilvl="0"><w:numFmt w:val="bullet"/></w:lvl><w:lvl w:ilvl="1"><w:numFmt w:val="bullet"/></w:lvl></w:abstractNum>
+<w:abstractNum w:abstractNumId="20"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/></w:lvl></w:abstractNum>
+<w:num w:numId="1"><w:abstractNumId w:val="10"/></w:num>
+<w:num w:numId="2"><w:abstractNumId w:val="20"/></w:num>
+</w:numbering>`,
+	"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://jane.dev" TargetMode="External"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://github.com/janedoe/" TargetMode="External"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>
+<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header2.xml"/>
+</Relationships>`,
+	"word/header1.xml": `<w:hdr ` + docxNS + `><w:p><w:r><w:t>jane@example.com | +1 415 555 1234</w:t></w:r></w:p></w:hdr>`,
+	// Headers repeated for first and other pages are only kept once
+	"word/header2.xml": `<w:hdr ` + docxNS + `><w:p><w:r><w:t>jane@example.com | +1 415 555 1234</w:t></w:r></w:p></w:hdr>`,
+}
+
+const resumeMarkdown = `jane@example.com | +1 415 555 1234
+
+# Jane Doe
+
+Site: [my portfolio](https://jane.dev) and https://github.com/janedoe
+
+## Experience
+
+Acme | Staff Engineer | 2020 - Present
+
+Highlights
+
+- Led billing
+- Built the ***payments API*** in Go
+  - Nested item
+1. First
+2. Second
+
+### Skills
+
+Go, SQL
+Kubernetes
+
+Not a list`
+
+func TestDOCX(t *testing.T) {
+	res, err := DOCX(buildDOCX(t, resumeDOCX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Text != resumeMarkdown {
+		t.Fatalf("DOCX =\n%s\n\nwant\n%s", res.Text, resumeMarkdown)
+	}
+}
+
+// cell is a table cell holding one paragraph per text
+func cell(texts ...string) string {
+	var b strings.Builder
+	b.WriteString("<w:tc><w:tcPr/>")
+	for _, text := range texts {
+		b.WriteString("<w:p><w:r><w:t>" + text + "</w:t></w:r></w:p>")
+	}
+	b.WriteString("</w:tc>")
+	return b.String()
+}
+
+func TestDOCXTablesAreFlattened(t *testing.T) {
+	// A skills matrix whose second row, set on the next line, would be the
+	// delimiter row of a markdown pipe table
+	document := `<w:document ` + docxNS + `><w:body><w:tbl><w:tblGrid/>` +
+		`<w:tr>` + cell("Skill") + cell("Level") + `</w:tr>` +
+		`<w:tr>` + cell("---") + cell("---") + `</w:tr>` +
+		`<w:tr><w:trPr/>` + cell("Go") + cell("Expert") + `</w:tr>` +
+		`<w:tr>` + cell("Kubernetes") + cell("Daily use", "Ran three clusters") + `</w:tr>` +
+		`<w:tr>` + cell() + cell() + `</w:tr>` +
+		`</w:tbl></w:body></w:document>`
+	res, err := DOCX(buildDOCX(t, map[string]string{"word/document.xml": document}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Skill | Level\n\n--- | ---\n\nGo | Expert\n\nKubernetes\n\nDaily use\n\nRan three clusters"
+	if res.Text != want {
+		t.Fatalf("DOCX =\n%s\n\nwant\n%s", res.Text, want)
+	}
+	// Every row is a paragraph of its own, so nothing renders as a table
+	html := markdown.ToHTML(res.Text)
+	for _, row := range []string{"<p>Skill | Level</p>", "<p>--- | ---</p>", "<p>Go | Expert</p>"} {
+		if !strings.Contains(html, row) {
+			t.Errorf("rendered table has no %s:\n%s", row, html)
+		}
+	}
+	if strings.Contains(html, "<table") || strings.Contains(html, "<hr") {
+		t.Errorf("flattened table rendered as markup:\n%s", html)
+	}
+}
+
+func TestDOCXErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts map[string]string
+		want  error
+	}{
+		{"no body", map[string]string{"word/styles.xml": "<w:styles/>"}, ErrUnreadable},
+		{"malformed body", map[string]string{"word/document.xml": `<w:document ` + docxNS + `><w:body><w:p>`}, ErrUnreadable},
+		{"empty body", map[string]string{"word/document.xml": `<w:document ` + docxNS + `><w:body><w:p/></w:body></w:document>`}, ErrNoText},
+		{
+			"oversized body",
+			map[string]string{"word/document.xml": `<w:document ` + docxNS + `><w:body>` + strings.Repeat(`<w:p><w:r><w:t>x</w:t></w:r></w:p>`, maxPartSize/30) + `</w:body></w:document>`},
+			ErrUnreadable,
+		},
+	}
+	for _, tt := range tests {
+		if _, err := DOCX(buildDOCX(t, tt.parts)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: DOCX error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if _, err := DOCX([]byte("not a zip")); !errors.Is(err, ErrUnreadable) {
+		t.Errorf("DOCX(not a zip) error = %v", err)
+	}
+}
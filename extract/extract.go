@@ -0,0 +1,232 @@
+// Package extract pulls text out of uploaded resume files (PDF, DOCX, JSON
+// Resume and LinkedIn data exports) as the markdown the tweak prompt
+// expects.
+//
+// Extraction aims for text a person would retype: reading order across
+// columns, words split by line-end hyphens rejoined, and bullet glyphs
+// rebuilt as markdown "- " items. Layout is otherwise discarded.
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/johnhkchen/resume-tweaker/jsonresume"
+	"github.com/johnhkchen/resume-tweaker/linkedin"
+)
+
+// MaxSize caps an uploaded file
+const MaxSize = 10 << 20
+
+// MIME types of the formats File understands
+const (
+	MimePDF  = "application/pdf"
+	MimeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MimeJSON = "application/json"
+	// MimeLinkedIn is a LinkedIn data export, a zip of CSVs
+	MimeLinkedIn = "application/zip"
+)
+
+// MimeTypes lists every accepted upload type
+var MimeTypes = []string{MimePDF, MimeDOCX, MimeJSON, MimeLinkedIn}
+
+var (
+	// ErrUnsupported is returned for file types we can't read
+	ErrUnsupported = errors.New("unsupported file type")
+	// ErrUnreadable is returned for corrupt or encrypted files
+	ErrUnreadable = errors.New("file could not be read")
+	// ErrNoText is returned when a file has no extractable text, e.g. a
+	// scanned PDF
+	ErrNoText = errors.New("no text found")
+)
+
+// Result is the text extracted from a file
+type Result struct {
+	Text string
+	// Pages is the page count, where the format has pages
+	Pages int
+	// Columns is set when a multi-column layout was detected and
+	// reordered, which is worth a second look in the preview
+	Columns bool
+	// Profile is set for structured formats (JSON Resume, LinkedIn), whose
+	// data becomes the user's master profile
+	Profile *jsonresume.Resume
+}
+
+// Detect returns the MIME type of a file from its contents, ignoring
+// whatever type the client claimed
+func Detect(data []byte) string {
+	// Anything that opens like a JSON object goes to the JSON Resume
+	// importer, whose validation explains what's wrong with it
+	if trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '{' {
+		return MimeJSON
+	}
+
+	switch http.DetectContentType(data) {
+	case MimePDF:
+		return MimePDF
+	case "application/zip":
+		// A DOCX is a zip package with a Word document body
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return ""
+		}
+		for _, f := range zr.File {
+			if f.Name == "word/document.xml" {
+				return MimeDOCX
+			}
+		}
+		if linkedin.IsExport(zr) {
+			return MimeLinkedIn
+		}
+	}
+	return ""
+}
+
+// File extracts text from a file, picking the format from its MIME type
+func File(mime string, data []byte) (Result, error) {
+	switch mime {
+	case MimePDF:
+		return PDF(data)
+	case MimeDOCX:
+		return DOCX(data)
+	case MimeJSON:
+		r, err := jsonresume.Parse(data)
+		if err != nil {
+			return Result{}, err
+		}
+		return structured(r)
+	case MimeLinkedIn:
+		r, err := linkedin.Parse(data)
+		if errors.Is(err, linkedin.ErrEmpty) {
+			return Result{}, ErrNoText
+		}
+		if err != nil {
+			return Result{}, ErrUnreadable
+		}
+		return structured(r)
+	}
+	return Result{}, ErrUnsupported
+}
+
+// structured renders a resume parsed from structured data as markdown
+func structured(r jsonresume.Resume) (Result, error) {
+	text := jsonresume.Markdown(r)
+	if strings.TrimSpace(text) == "" {
+		return Result{}, ErrNoText
+	}
+	return Result{Text: text, Profile: &r}, nil
+}
+
+// line is one line of extracted text with the position it started at
+type line struct {
+	text string
+	// x is the left edge of the text; y the baseline, increasing upwards
+	x, y float64
+	size float64
+	// blank marks a paragraph break
+	blank bool
+}
+
+// bulletRunes are glyphs resumes use as list markers, including the private
+// use code points Symbol and Wingdings fonts map their bullets to
+var bulletRunes = map[rune]bool{
+	'•': true, '●': true, '○': true, '◦': true, '▪': true, '■': true,
+	'□': true, '‣': true, '⁃': true, '∙': true, '·': true, '➢': true,
+	'➤': true, '►': true, '▶': true, '✓': true, '✔': true, '–': true,
+	'—': true, '-': true, '*': true, '\uf0b7': true, '\uf0a7': true,
+	'\uf076': true, '\uf0d8': true, '\uf0fc': true,
+}
+
+// bullet splits a list marker off the start of text
+func bullet(text string) (string, bool) {
+	r, n := utf8.DecodeRuneInString(text)
+	if !bulletRunes[r] {
+		return text, false
+	}
+	rest := text[n:]
+	// "-5%" or "*nix" aren't list items
+	if rest != "" && !unicode.IsSpace([]rune(rest)[0]) && (r == '-' || r == '*' || r == '–' || r == '—') {
+		return text, false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// tidy joins extracted lines into text: rejoining hyphenated words,
+// rebuilding bullets and unwrapping lines that continue the one before
+func tidy(lines []line) string {
+	var out []string
+	// item is set while the last output line is a bullet whose text
+	// starts at itemX
+	item, itemX := false, 0.0
+	prev := line{blank: true}
+
+	for _, l := range lines {
+		if l.blank {
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+			item, prev = false, l
+			continue
+		}
+
+		text := strings.Join(strings.Fields(l.text), " ")
+		if text == "" {
+			continue
+		}
+		rest, isBullet := bullet(text)
+		if isBullet && rest == "" {
+			// A marker on a line of its own; the text follows
+			item, itemX, prev = true, l.x, l
+			out = append(out, "- ")
+			continue
+		}
+
+		if !prev.blank && len(out) > 0 && !isBullet {
+			last := out[len(out)-1]
+			switch {
+			case hyphenated(last, text):
+				out[len(out)-1] = last[:len(last)-1] + text
+				prev = l
+				continue
+			case strings.HasSuffix(last, "- ") || (item && l.x > itemX+1) || continues(last, text):
+				out[len(out)-1] = strings.TrimSuffix(last, " ") + " " + text
+				prev = l
+				continue
+			}
+		}
+
+		if isBullet {
+			out = append(out, "- "+rest)
+			item, itemX = true, l.x
+		} else {
+			out = append(out, text)
+			item = false
+		}
+		prev = l
+	}
+
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// hyphenated reports whether last ends in a word broken across the line
+func hyphenated(last, next string) bool {
+	if len(last) < 2 || !strings.HasSuffix(last, "-") {
+		return false
+	}
+	before, _ := utf8.DecodeLastRuneInString(last[:len(last)-1])
+	first, _ := utf8.DecodeRuneInString(next)
+	return unicode.IsLetter(before) && unicode.IsLower(first)
+}
+
+// continues reports whether next is the wrapped remainder of a sentence
+func continues(last, next string) bool {
+	first, _ := utf8.DecodeRuneInString(next)
+	end, _ := utf8.DecodeLastRuneInString(last)
+	return unicode.IsLower(first) && !strings.ContainsRune(".:;!?", end)
+}
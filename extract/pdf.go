@@ -0,0 +1,244 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// PDF extracts the text of a PDF in reading order. Text is grouped into
+// lines by baseline; when a page has a two-column layout, each column is
+// read top to bottom before the next, with full-width lines (a header, a
+// footer) kept in place between them.
+func PDF(data []byte) (res Result, err error) {
+	// The parser panics on some malformed files
+	defer func() {
+		if r := recover(); r != nil {
+			res, err = Result{}, fmt.Errorf("%w: %v", ErrUnreadable, r)
+		}
+	}()
+
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrUnreadable, err)
+	}
+
+	var lines []line
+	for i := 1; i <= r.NumPage(); i++ {
+		page := r.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		pageLines, columns := layout(page.Content().Text)
+		lines = append(lines, pageLines...)
+		lines = append(lines, line{blank: true})
+		res.Columns = res.Columns || columns
+		res.Pages++
+	}
+
+	res.Text = tidy(lines)
+	if res.Text == "" {
+		return Result{}, ErrNoText
+	}
+	return res, nil
+}
+
+// segment is a run of text on one baseline with no wide gaps in it
+type segment struct {
+	x0, x1, y, size float64
+	text            strings.Builder
+}
+
+// layout turns a page's glyphs into lines in reading order, and reports
+// whether it found a second column
+func layout(glyphs []pdf.Text) ([]line, bool) {
+	rows := rowsOf(glyphs)
+	var segs [][]*segment
+	for _, row := range rows {
+		segs = append(segs, segmentsOf(row))
+	}
+
+	gutter, ok := findGutter(segs)
+	if !ok {
+		var lines []line
+		for _, row := range segs {
+			lines = appendLine(lines, joinSegments(row))
+		}
+		return lines, false
+	}
+
+	// Walk down the page collecting each column; a full-width line ends a
+	// column block, so the columns above it are emitted first
+	var lines, left, right []line
+	flush := func() {
+		lines = append(lines, left...)
+		if len(left) > 0 && len(right) > 0 {
+			lines = append(lines, line{blank: true})
+		}
+		lines = append(lines, right...)
+		left, right = nil, nil
+	}
+	for _, row := range segs {
+		var l, r []*segment
+		full := false
+		for _, s := range row {
+			switch {
+			case s.x1 <= gutter+1:
+				l = append(l, s)
+			case s.x0 >= gutter-1:
+				r = append(r, s)
+			default:
+				full = true
+			}
+		}
+		if full {
+			flush()
+			lines = appendLine(lines, joinSegments(row))
+			continue
+		}
+		if len(l) > 0 {
+			left = appendLine(left, joinSegments(l))
+		}
+		if len(r) > 0 {
+			right = appendLine(right, joinSegments(r))
+		}
+	}
+	flush()
+	return lines, true
+}
+
+// rowsOf groups glyphs sharing a baseline, top of the page first, each row
+// sorted left to right
+func rowsOf(glyphs []pdf.Text) [][]pdf.Text {
+	sorted := make([]pdf.Text, 0, len(glyphs))
+	for _, g := range glyphs {
+		if g.S != "" {
+			sorted = append(sorted, g)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Y > sorted[j].Y })
+
+	var rows [][]pdf.Text
+	for _, g := range sorted {
+		n := len(rows)
+		if n > 0 && math.Abs(rows[n-1][0].Y-g.Y) <= math.Max(fontSize(g), fontSize(rows[n-1][0]))*0.4 {
+			rows[n-1] = append(rows[n-1], g)
+			continue
+		}
+		rows = append(rows, []pdf.Text{g})
+	}
+	for _, row := range rows {
+		sort.SliceStable(row, func(i, j int) bool { return row[i].X < row[j].X })
+	}
+	return rows
+}
+
+// segmentsOf splits a row at wide gaps, inserting spaces at word gaps
+func segmentsOf(row []pdf.Text) []*segment {
+	var segs []*segment
+	var cur *segment
+	for _, g := range row {
+		size := fontSize(g)
+
+		if cur != nil {
+			gap := g.X - cur.x1
+			switch {
+			case gap > size*2:
+				cur = nil
+			case gap > size*0.15 && !strings.HasSuffix(cur.text.String(), " ") && !strings.HasPrefix(g.S, " "):
+				cur.text.WriteByte(' ')
+			}
+		}
+		if cur == nil {
+			cur = &segment{x0: g.X, y: g.Y, size: size}
+			segs = append(segs, cur)
+		}
+		cur.text.WriteString(g.S)
+		if g.W > 0 {
+			cur.x1 = math.Max(cur.x1, g.X+g.W)
+		} else {
+			// Fonts without a widths table don't advance the position, so
+			// estimate from an average glyph width
+			cur.x1 = math.Max(cur.x1, g.X) + size*0.5*float64(len([]rune(g.S)))
+		}
+	}
+	return segs
+}
+
+// findGutter looks for the left edge of a second column: an x position
+// where at least three segments start after a gap on their row, and which
+// few segments cross. The most common start wins, the leftmost on a tie.
+func findGutter(rows [][]*segment) (float64, bool) {
+	starts := map[int]int{}
+	for _, row := range rows {
+		for i, s := range row[1:] {
+			// Text set well apart from its bullet isn't a column
+			if rest, ok := bullet(strings.TrimSpace(row[i].text.String())); ok && rest == "" {
+				continue
+			}
+			starts[int(math.Round(s.x0))]++
+		}
+	}
+
+	type candidate struct{ x, count int }
+	var candidates []candidate
+	for x, count := range starts {
+		if count >= 3 {
+			candidates = append(candidates, candidate{x, count})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].count != candidates[j].count {
+			return candidates[i].count > candidates[j].count
+		}
+		return candidates[i].x < candidates[j].x
+	})
+
+	for _, c := range candidates {
+		edge := float64(c.x)
+		aligned, crossing := 0, 0
+		for _, row := range rows {
+			for _, s := range row {
+				switch {
+				case math.Abs(s.x0-edge) <= 2:
+					aligned++
+				case s.x0 < edge-1 && s.x1 > edge+1:
+					crossing++
+				}
+			}
+		}
+		if crossing*2 <= aligned {
+			return edge, true
+		}
+	}
+	return 0, false
+}
+
+// joinSegments merges segments back into one line
+func joinSegments(segs []*segment) line {
+	parts := make([]string, len(segs))
+	for i, s := range segs {
+		parts[i] = s.text.String()
+	}
+	return line{text: strings.Join(parts, " "), x: segs[0].x0, y: segs[0].y, size: segs[0].size}
+}
+
+// appendLine adds l, marking a paragraph break first when the gap from the
+// previous line is well over the line height
+func appendLine(lines []line, l line) []line {
+	if n := len(lines); n > 0 && !lines[n-1].blank && lines[n-1].y-l.y > l.size*1.8 {
+		lines = append(lines, line{blank: true})
+	}
+	return append(lines, l)
+}
+
+func fontSize(g pdf.Text) float64 {
+	if g.FontSize > 0 {
+		return g.FontSize
+	}
+	return 10
+}
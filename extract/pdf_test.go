@@ -0,0 +1,99 @@
+package extract
+
+import (
+	"testing"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// text places a run of text on the page as one glyph
+func text(s string, x, y, w float64) pdf.Text {
+	return pdf.Text{S: s, X: x, Y: y, W: w, FontSize: 10}
+}
+
+// twoColumnPage is a header, two columns and a footer
+var twoColumnPage = []pdf.Text{
+	text("Jane Doe, Staff Engineer", 50, 750, 400),
+	text("Experience", 50, 720, 60), text("Skills", 300, 720, 40),
+	text("Acme 2020 - Present", 50, 708, 110), text("Go", 300, 708, 15),
+	text("- Built billing", 50, 696, 80), text("PostgreSQL", 300, 696, 60),
+	text("- Ran on-call", 50, 684, 75), text("Kubernetes", 300, 684, 60),
+	text("jane@example.com | github.com/janedoe", 50, 600, 400),
+}
+
+func TestLayoutTwoColumns(t *testing.T) {
+	lines, columns := layout(twoColumnPage)
+	if !columns {
+		t.Fatal("no second column found")
+	}
+	want := `Jane Doe, Staff Engineer
+Experience
+Acme 2020 - Present
+- Built billing
+- Ran on-call
+
+Skills
+Go
+PostgreSQL
+Kubernetes
+
+jane@example.com | github.com/janedoe`
+	if got := tidy(lines); got != want {
+		t.Fatalf("layout read\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLayoutSingleColumn(t *testing.T) {
+	lines, columns := layout([]pdf.Text{
+		text("Jane Doe", 50, 750, 50),
+		text("- Built billing", 50, 738, 80),
+		text("- Ran on-call", 50, 726, 75),
+	})
+	if columns {
+		t.Fatal("found a column in a single-column page")
+	}
+	if got := tidy(lines); got != "Jane Doe\n- Built billing\n- Ran on-call" {
+		t.Fatalf("layout read %q", got)
+	}
+}
+
+func TestFindGutterTieIsLeftmost(t *testing.T) {
+	// Three columns: starts at 200 and 350 are equally common
+	var glyphs []pdf.Text
+	for _, y := range []float64{720, 708, 696} {
+		glyphs = append(glyphs,
+			text("Left", 50, y, 40),
+			text("Middle", 200, y, 50),
+			text("Right", 350, y, 40),
+		)
+	}
+	rows := rowsOf(glyphs)
+	var segs [][]*segment
+	for _, row := range rows {
+		segs = append(segs, segmentsOf(row))
+	}
+
+	// Map order is random, so a tie broken by iteration order would show up
+	// across runs
+	for i := 0; i < 50; i++ {
+		gutter, ok := findGutter(segs)
+		if !ok || gutter != 200 {
+			t.Fatalf("findGutter = %v, %v; want 200", gutter, ok)
+		}
+	}
+}
+
+func TestFindGutterIgnoresSpacedBullets(t *testing.T) {
+	var glyphs []pdf.Text
+	for _, y := range []float64{720, 708, 696} {
+		glyphs = append(glyphs, text("•", 50, y, 5), text("Built billing", 80, y, 70))
+	}
+	rows := rowsOf(glyphs)
+	var segs [][]*segment
+	for _, row := range rows {
+		segs = append(segs, segmentsOf(row))
+	}
+	if gutter, ok := findGutter(segs); ok {
+		t.Fatalf("findGutter took bullet text for a column at %v", gutter)
+	}
+}
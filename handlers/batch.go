@@ -0,0 +1,806 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johnhkchen/resume-tweaker/batch"
+	"github.com/johnhkchen/resume-tweaker/export"
+	"github.com/johnhkchen/resume-tweaker/jobs"
+	"github.com/johnhkchen/resume-tweaker/jsonresume"
+	"github.com/johnhkchen/resume-tweaker/profile"
+	"github.com/johnhkchen/resume-tweaker/quota"
+	"github.com/johnhkchen/resume-tweaker/settings"
+	"github.com/johnhkchen/resume-tweaker/sse"
+	"github.com/johnhkchen/resume-tweaker/templates"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Defaults for BATCH_MAX_ITEMS and BATCH_CONCURRENCY
+const (
+	defaultBatchMaxItems    = 25
+	defaultBatchConcurrency = 2
+)
+
+// Batch limits: how many job descriptions one batch may hold, and how many
+// of its tweaks run at once. The LLM scheduler still caps calls across all
+// users, so this only keeps one batch from filling the queue.
+var (
+	batchMaxItems    = intFromEnv("BATCH_MAX_ITEMS", defaultBatchMaxItems)
+	batchConcurrency = intFromEnv("BATCH_CONCURRENCY", defaultBatchConcurrency)
+)
+
+const (
+	// batchesShown caps the batches listed on the batch page
+	batchesShown = 20
+	// baseResumesShown caps the saved resumes offered as a base
+	baseResumesShown = 10
+	// baseProfile is the base resume choice for the master profile
+	baseProfile = "profile"
+	// maxBatchCSVSize caps an uploaded CSV of job descriptions
+	maxBatchCSVSize = 5 << 20
+)
+
+func intFromEnv(key string, fallback int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		log.Printf("[Batch] Invalid %s %q, using %d", key, raw, fallback)
+		return fallback
+	}
+	return n
+}
+
+// HandleBatchesPagePB serves the batch page: a form to start a batch and the
+// user's recent batches
+func HandleBatchesPagePB(e *core.RequestEvent) error {
+	batches, err := batch.List(e.App, e.Auth.Id, batchesShown)
+	if err != nil {
+		log.Printf("[Batch] Warning: failed to list batches for %s: %v", e.Auth.Id, err)
+	}
+
+	var buf bytes.Buffer
+	if err := templates.BatchesPage(baseResumes(e.App, e.Auth.Id), batches, batchMaxItems).Render(e.Request.Context(), &buf); err != nil {
+		return e.String(http.StatusInternalServerError, "Failed to render page")
+	}
+	return e.HTML(http.StatusOK, buf.String())
+}
+
+// HandleCreateBatchPB starts a batch from the batch-form form: the base
+// resume, an optional name, and a CSV file and/or pasted job descriptions.
+// On success the browser is sent to the batch's progress page.
+func HandleCreateBatchPB(e *core.RequestEvent) error {
+	// Read the form before streaming the response
+	e.Request.Body = http.MaxBytesReader(e.Response, e.Request.Body, maxBatchCSVSize+2<<20)
+	entries, errMsg := readBatchForm(e)
+	base, name := e.Request.FormValue("base"), e.Request.FormValue("name")
+
+	sw, err := sse.New(e.Response, e.Request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "SSE not supported"})
+	}
+	if errMsg != "" {
+		return sw.MergeSignals(sse.Signals{"batch_creating": false, "batch_error": errMsg})
+	}
+
+	resume, ok := baseResume(e.App, e.Auth.Id, base)
+	if !ok {
+		return sw.MergeSignals(sse.Signals{"batch_creating": false, "batch_error": "Pick the resume to start from."})
+	}
+	b, errMsg := startBatch(e.App, e.Auth.Id, name, resume, entries)
+	if errMsg != "" {
+		return sw.MergeSignals(sse.Signals{"batch_creating": false, "batch_error": errMsg})
+	}
+	return sw.ExecuteScript(fmt.Sprintf("window.location.href = %q", "/app/batches/"+b.ID))
+}
+
+// readBatchForm reads the job descriptions from the uploaded CSV and the
+// pasted text. Failures come back as a user-facing message.
+func readBatchForm(e *core.RequestEvent) ([]batch.Entry, string) {
+	if err := e.Request.ParseMultipartForm(maxBatchCSVSize); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		var maxBytes *http.MaxBytesError
+		if errors.As(err, &maxBytes) {
+			return nil, fmt.Sprintf("That file is too large (max %d MB).", maxBatchCSVSize>>20)
+		}
+		return nil, "Invalid request."
+	}
+
+	var entries []batch.Entry
+	if file, _, err := e.Request.FormFile("file"); err == nil {
+		defer file.Close()
+		data, err := io.ReadAll(io.LimitReader(file, maxBatchCSVSize))
+		if err != nil {
+			return nil, "The upload didn't complete. Please try again."
+		}
+		parsed, err := batch.ParseCSV(data)
+		switch {
+		case errors.Is(err, batch.ErrNoColumns):
+			return nil, "The CSV needs a header row with a description or url column."
+		case errors.Is(err, batch.ErrNoEntries):
+			return nil, "The CSV has no job descriptions in it."
+		case err != nil:
+			return nil, "We couldn't read that CSV file."
+		}
+		entries = append(entries, parsed...)
+	}
+	entries = append(entries, batch.SplitPasted(e.Request.FormValue("pasted"))...)
+	return entries, ""
+}
+
+// startBatch validates the entries, creates the batch and sets it running.
+// Failures come back as a user-facing message.
+func startBatch(app core.App, userID, name, resume string, entries []batch.Entry) (batch.Batch, string) {
+	if len(resume) < 50 {
+		return batch.Batch{}, "Resume too short (min 50 chars)"
+	}
+	if len(resume) > MaxInputBytes {
+		return batch.Batch{}, fmt.Sprintf("Resume too long (max %d KB)", MaxInputBytes/1024)
+	}
+	if len(entries) == 0 {
+		return batch.Batch{}, "Add some job descriptions: upload a CSV or paste them, separated by a line with ---."
+	}
+	if len(entries) > batchMaxItems {
+		return batch.Batch{}, fmt.Sprintf("That's %d job descriptions; a batch can have at most %d.", len(entries), batchMaxItems)
+	}
+	for i, entry := range entries {
+		if entry.JobDescription == "" {
+			continue
+		}
+		if len(entry.JobDescription) < 20 {
+			return batch.Batch{}, fmt.Sprintf("Job description %d is too short (min 20 chars).", i+1)
+		}
+		if len(entry.JobDescription) > MaxInputBytes {
+			return batch.Batch{}, fmt.Sprintf("Job description %d is too long (max %d KB).", i+1, MaxInputBytes/1024)
+		}
+	}
+
+	if strings.TrimSpace(name) == "" {
+		name = "Batch of " + time.Now().Format("Jan 2, 15:04")
+	}
+	// Demo mode is free
+	b, err := createBatch(app, userID, strings.TrimSpace(name), resume, entries, os.Getenv("ANTHROPIC_API_KEY") != "")
+	var short *batchQuotaError
+	if errors.As(err, &short) {
+		return batch.Batch{}, batchErrorMessage(err)
+	}
+	if err != nil {
+		log.Printf("[Batch] Failed to create batch for %s: %v", userID, err)
+		return batch.Batch{}, "Failed to start the batch. Please try again."
+	}
+	batch.Run(app, b, batchConcurrency, batchTweak(app, userID), batchErrorMessage)
+	log.Printf("[Batch] Started batch %s for %s with %d items", b.ID, userID, len(b.Items))
+	return b, ""
+}
+
+// batchQuotaError is returned when a user's remaining tweaks can't cover a
+// new batch
+type batchQuotaError struct {
+	// remaining is the tweaks left today not held by the user's unfinished
+	// batches; held is how many those batches hold
+	remaining, held, needed int64
+}
+
+func (e *batchQuotaError) Error() string {
+	return fmt.Sprintf("batch needs %d tweaks, %d left", e.needed, e.remaining)
+}
+
+// createBatch creates a batch, first checking, when checkQuota is set, that
+// the user's tweaks left today cover it along with the items of their other
+// batches still waiting to start. The check and the creation share a
+// transaction, so two batches can't both count the same tweaks.
+func createBatch(app core.App, userID, name, resume string, entries []batch.Entry, checkQuota bool) (batch.Batch, error) {
+	var b batch.Batch
+	err := app.RunInTransaction(func(tx core.App) error {
+		if checkQuota {
+			if err := checkBatchQuota(tx, userID, len(entries)); err != nil {
+				return err
+			}
+		}
+		var err error
+		b, err = batch.Create(tx, userID, name, resume, entries)
+		return err
+	})
+	return b, err
+}
+
+// checkBatchQuota returns a *batchQuotaError if the user's tweaks left
+// today can't cover needed more. It fails open if usage can't be read.
+func checkBatchQuota(app core.App, userID string, needed int) error {
+	status, err := quota.GetStatus(app, userID, time.Now())
+	if err != nil {
+		log.Printf("[Quota] Warning: failed to load quota for %s: %v", userID, err)
+		return nil
+	}
+	remaining := status.TweaksRemaining()
+	if remaining < 0 {
+		return nil
+	}
+	held, err := batch.AwaitingJobs(app, userID)
+	if err != nil {
+		log.Printf("[Quota] Warning: failed to count batch items for %s: %v", userID, err)
+	}
+	remaining = max(remaining-int64(held), 0)
+	if remaining < int64(needed) {
+		return &batchQuotaError{remaining: remaining, held: int64(held), needed: int64(needed)}
+	}
+	return nil
+}
+
+// Failures of a batch item; batchErrorMessage says what they mean to the
+// user
+var (
+	errPostingTooLong = errors.New("posting too long")
+	errJobNotStarted  = errors.New("tweak job not started")
+	errResultNotSaved = errors.New("tweak finished but its result was not saved")
+)
+
+// importError is a failure to fetch an item's job posting
+type importError struct {
+	err error
+}
+
+func (e *importError) Error() string { return "import posting: " + e.err.Error() }
+
+func (e *importError) Unwrap() error { return e.err }
+
+// jobFailedError is an item's tweak job ending without a result. message is
+// the job's own user-facing error, if it had one.
+type jobFailedError struct {
+	message string
+}
+
+func (e *jobFailedError) Error() string {
+	if e.message == "" {
+		return "tweak job failed"
+	}
+	return "tweak job failed: " + e.message
+}
+
+// batchErrorMessage is the user-facing text for a batch failure
+func batchErrorMessage(err error) string {
+	var imported *importError
+	var failed *jobFailedError
+	var exceeded *quota.ExceededError
+	var short *batchQuotaError
+	switch {
+	case errors.As(err, &imported):
+		return jobImportErrorMessage(imported.err)
+	case errors.As(err, &exceeded):
+		return exceeded.Error()
+	case errors.As(err, &short) && short.held > 0:
+		return fmt.Sprintf("You have %d tweaks left today once your running batches finish, and this batch needs %d.", short.remaining, short.needed)
+	case errors.As(err, &short):
+		return fmt.Sprintf("You have %d tweaks left today, and this batch needs %d.", short.remaining, short.needed)
+	case errors.As(err, &failed) && failed.message != "":
+		return failed.message
+	case errors.Is(err, errPostingTooLong):
+		return fmt.Sprintf("That posting is too long (max %d KB).", MaxInputBytes/1024)
+	case errors.Is(err, errJobNotStarted):
+		return "Failed to start tweak"
+	case errors.Is(err, errResultNotSaved):
+		return "The tweak finished but couldn't be saved. Please try again."
+	default:
+		return jobErrorMessage(nil)
+	}
+}
+
+// batchTweak tweaks one batch item as an ordinary tweak job, so it is
+// queued, charged and saved like one started from the tweak page
+func batchTweak(app core.App, userID string) batch.TweakFunc {
+	return func(ctx context.Context, resume string, item *batch.Item, note func(string)) error {
+		if item.JobDescription == "" {
+			note("Fetching the job posting")
+			posting, err := jobFetcher.Import(ctx, item.URL)
+			if err != nil {
+				return &importError{err}
+			}
+			item.JobDescription = posting.Text()
+			if item.Title == "" {
+				item.Title = posting.Title
+			}
+			if item.Company == "" {
+				item.Company = posting.Company
+			}
+			if len(item.JobDescription) > MaxInputBytes {
+				return errPostingTooLong
+			}
+		}
+
+		llmEnabled := os.Getenv("ANTHROPIC_API_KEY") != ""
+		var usage *quota.Event
+		var quotaStatus quota.Status
+		if llmEnabled {
+			var exceeded *quota.ExceededError
+			usage, quotaStatus, exceeded = reserveQuota(app, userID, modeTweak)
+			if exceeded != nil {
+				return exceeded
+			}
+		}
+
+		req := tweakRequest{
+			Mode:           modeTweak,
+			Resume:         resume,
+			JobDescription: item.JobDescription,
+			ResumeLanguage: detectLanguage(ctx, resume),
+			RedactPII:      settings.Get(app, userID).RedactPII,
+		}
+		key := jobs.Key(userID, modeTweak, "", strconv.FormatBool(req.RedactPII), resume, item.JobDescription)
+		job, joined, err := jobs.Start(app, userID, modeBatch, key, initialTweakSignals(req.ResumeLanguage, detectLanguage(ctx, item.JobDescription)))
+		if err != nil {
+			usage.Cancel()
+			return fmt.Errorf("%w: %v", errJobNotStarted, err)
+		}
+		item.JobID = job.ID
+		note("Tailoring the resume")
+
+		if joined {
+			// The same tweak is already running; it's already paid for
+			usage.Cancel()
+		} else {
+			runTweak(app, job, req, llmEnabled, usage, quotaStatus)
+		}
+
+		u := waitJob(job)
+		if u.Status != jobs.StatusDone {
+			message, _ := u.Signals["error"].(string)
+			return &jobFailedError{message}
+		}
+		item.ResumeID, _ = u.Signals["resume_id"].(string)
+		if item.ResumeID == "" {
+			return errResultNotSaved
+		}
+		return nil
+	}
+}
+
+// waitJob blocks until job finishes, returning its final state
+func waitJob(job *jobs.Job) jobs.Update {
+	for {
+		u, changed := job.Since(0)
+		if u.Done {
+			return u
+		}
+		<-changed
+	}
+}
+
+// baseResumes lists the resumes a batch can start from: the master profile
+// and the user's recently uploaded or pasted resumes, without repeats
+func baseResumes(app core.App, userID string) []templates.BaseResume {
+	var bases []templates.BaseResume
+	if p, ok := profile.Get(app, userID); ok {
+		label := "Your profile"
+		if p.Resume.Basics != nil && p.Resume.Basics.Name != "" {
+			label += " (" + p.Resume.Basics.Name + ")"
+		}
+		bases = append(bases, templates.BaseResume{ID: baseProfile, Label: label})
+	}
+
+	records, err := app.FindRecordsByFilter("resumes", "user = {:user} && original_content != ''", "-created", 100, 0,
+		map[string]any{"user": userID})
+	if err != nil {
+		log.Printf("[Batch] Warning: failed to list resumes for %s: %v", userID, err)
+		return bases
+	}
+	seen := map[string]bool{}
+	for _, r := range records {
+		content := r.GetString("original_content")
+		key := strings.Join(strings.Fields(content), " ")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		label := export.Parse(content).Title
+		if label == "" {
+			label = "Resume"
+		}
+		if file := r.GetString("source_file"); file != "" {
+			label += " · " + r.GetString("source_file")
+		}
+		label += " · " + r.GetDateTime("created").Time().Format("Jan 2, 2006")
+		bases = append(bases, templates.BaseResume{ID: r.Id, Label: label})
+		if len(bases) >= baseResumesShown {
+			break
+		}
+	}
+	return bases
+}
+
+// baseResume loads the text of a base resume choice
+func baseResume(app core.App, userID, id string) (string, bool) {
+	if id == baseProfile {
+		p, ok := profile.Get(app, userID)
+		if !ok {
+			return "", false
+		}
+		return p.Markdown(), true
+	}
+	record, err := app.FindRecordById("resumes", id)
+	if err != nil || record.GetString("user") != userID {
+		return "", false
+	}
+	return record.GetString("original_content"), true
+}
+
+// HandleBatchPagePB serves a batch's progress page
+func HandleBatchPagePB(e *core.RequestEvent) error {
+	b, err := batch.Get(e.App, e.Auth.Id, e.Request.PathValue("id"))
+	if errors.Is(err, batch.ErrNotFound) {
+		return e.String(http.StatusNotFound, "Batch not found")
+	}
+	if err != nil {
+		return e.String(http.StatusInternalServerError, "Failed to load batch")
+	}
+
+	var buf bytes.Buffer
+	if err := templates.BatchPage(b, batch.Running(b.ID)).Render(e.Request.Context(), &buf); err != nil {
+		return e.String(http.StatusInternalServerError, "Failed to render page")
+	}
+	return e.HTML(http.StatusOK, buf.String())
+}
+
+// HandleBatchStreamPB streams a batch's item list as it changes, until the
+// batch finishes or the client goes away
+func HandleBatchStreamPB(e *core.RequestEvent) error {
+	id := e.Request.PathValue("id")
+	if _, err := batch.Get(e.App, e.Auth.Id, id); err != nil {
+		return e.JSON(http.StatusNotFound, map[string]string{"error": "Batch not found"})
+	}
+
+	sw, err := sse.New(e.Response, e.Request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "SSE not supported"})
+	}
+
+	ctx := e.Request.Context()
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		changed := batch.Watch(id)
+		b, err := batch.Get(e.App, e.Auth.Id, id)
+		if err != nil {
+			log.Printf("[Batch] Warning: failed to load batch %s: %v", id, err)
+			return nil
+		}
+		running := batch.Running(id)
+		var buf bytes.Buffer
+		if err := templates.BatchItems(b, running).Render(ctx, &buf); err != nil {
+			log.Printf("[Batch] Warning: failed to render batch %s: %v", id, err)
+			return nil
+		}
+		sw.MergeFragments(buf.String())
+		if !running {
+			return nil
+		}
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-changed:
+				break wait
+			case <-heartbeat.C:
+				if time.Since(sw.LastWrite()) >= heartbeatInterval {
+					sw.Comment("heartbeat")
+				}
+			}
+		}
+
+		// Coalesce items finishing together into one update
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(resultFlushInterval):
+		}
+	}
+}
+
+// HandleRetryBatchPB runs a batch's failed and interrupted items again
+func HandleRetryBatchPB(e *core.RequestEvent) error {
+	b, err := batch.Get(e.App, e.Auth.Id, e.Request.PathValue("id"))
+
+	sw, sseErr := sse.New(e.Response, e.Request)
+	if sseErr != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "SSE not supported"})
+	}
+	if err != nil {
+		return sw.MergeSignals(sse.Signals{"batch_error": "That batch no longer exists."})
+	}
+	if batch.Running(b.ID) {
+		return sw.MergeSignals(sse.Signals{"batch_error": "This batch is still running."})
+	}
+	if c := b.Counts(); c.Failed == 0 {
+		return sw.MergeSignals(sse.Signals{"batch_error": "There's nothing to retry."})
+	}
+	// Demo mode is free
+	retried, err := retryBatch(e.App, e.Auth.Id, b, os.Getenv("ANTHROPIC_API_KEY") != "")
+	var short *batchQuotaError
+	if errors.As(err, &short) {
+		return sw.MergeSignals(sse.Signals{"batch_error": batchErrorMessage(err)})
+	}
+	if err != nil {
+		log.Printf("[Batch] Failed to retry batch %s for %s: %v", b.ID, e.Auth.Id, err)
+		return sw.MergeSignals(sse.Signals{"batch_error": "Failed to retry the batch. Please try again."})
+	}
+	if !batch.Run(e.App, retried, batchConcurrency, batchTweak(e.App, e.Auth.Id), batchErrorMessage) {
+		return sw.MergeSignals(sse.Signals{"batch_error": "This batch is still running."})
+	}
+	return sw.ExecuteScript("window.location.reload()")
+}
+
+// retryBatch queues a batch's failed items again, first checking, when
+// checkQuota is set, that the user's tweaks left today cover them along
+// with the items of their batches still waiting to start. As in
+// createBatch, the check and the requeue share a transaction.
+func retryBatch(app core.App, userID string, b batch.Batch, checkQuota bool) (batch.Batch, error) {
+	var retried batch.Batch
+	err := app.RunInTransaction(func(tx core.App) error {
+		if checkQuota {
+			if err := checkBatchQuota(tx, userID, b.Counts().Failed); err != nil {
+				return err
+			}
+		}
+		var err error
+		retried, err = batch.Requeue(tx, b)
+		return err
+	})
+	return retried, err
+}
+
+// HandleBatchExportPB downloads a batch's finished tweaks as a zip, one file
+// per job description plus a summary.csv. The format query parameter picks
+// pdf (default), docx, json, tex or md; template and size are as for the
+// single exports.
+func HandleBatchExportPB(e *core.RequestEvent) error {
+	b, err := batch.Get(e.App, e.Auth.Id, e.Request.PathValue("id"))
+	if err != nil {
+		return e.JSON(http.StatusNotFound, map[string]string{"error": "Batch not found"})
+	}
+	ext, render, ok, err := batchRenderer(e)
+	if !ok {
+		return err
+	}
+	if b.Counts().Done == 0 {
+		return e.JSON(http.StatusNotFound, map[string]string{"error": "This batch has no finished tweaks to export yet"})
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	var summary bytes.Buffer
+	cw := csv.NewWriter(&summary)
+	cw.Write([]string{"position", "title", "company", "url", "status", "error", "file"})
+
+	for _, it := range b.Items {
+		file := ""
+		if it.Status == batch.ItemDone {
+			file = batchItemFilename(it, ext)
+			if err := addBatchFile(e.App, zw, e.Auth.Id, it, file, render); err != nil {
+				log.Printf("[Export] Failed to add batch item %s for %s: %v", it.ID, e.Auth.Id, err)
+				file = ""
+			}
+		}
+		cw.Write([]string{strconv.Itoa(it.Position), it.Title, it.Company, it.URL, it.Status, it.Error, file})
+	}
+	cw.Flush()
+
+	w, err := zw.Create("summary.csv")
+	if err == nil {
+		_, err = w.Write(summary.Bytes())
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		log.Printf("[Export] Failed to zip batch %s for %s: %v", b.ID, e.Auth.Id, err)
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create ZIP"})
+	}
+
+	name := filenameSlug(b.Name)
+	if name == "" {
+		name = "batch"
+	}
+	setAttachment(e, name+"-"+ext+".zip")
+	return e.Blob(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// addBatchFile renders an item's saved tweak into the zip
+func addBatchFile(app core.App, zw *zip.Writer, userID string, it batch.Item, name string, render func(string) ([]byte, error)) error {
+	record, err := app.FindRecordById("resumes", it.ResumeID)
+	if err != nil || record.GetString("user") != userID {
+		return errors.New("saved resume not found")
+	}
+	data, err := render(record.GetString("tweaked_content"))
+	if err != nil {
+		return err
+	}
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// batchItemFilename names an item's file by its position and job, e.g.
+// "03-backend-engineer-at-acme.pdf"
+func batchItemFilename(it batch.Item, ext string) string {
+	name := fmt.Sprintf("%02d", it.Position)
+	if slug := filenameSlug(it.Name()); slug != "" {
+		name += "-" + slug
+	}
+	return name + "." + ext
+}
+
+// batchRenderer picks the renderer for the format query parameter. When ok
+// is false the error response has been written.
+func batchRenderer(e *core.RequestEvent) (ext string, render func(string) ([]byte, error), ok bool, err error) {
+	fromDoc := func(r func(export.Document) ([]byte, error)) func(string) ([]byte, error) {
+		return func(content string) ([]byte, error) {
+			return r(export.Parse(content))
+		}
+	}
+
+	switch format := e.Request.URL.Query().Get("format"); format {
+	case "", "pdf", "docx":
+		tmpl, size, ok, err := exportOptions(e)
+		if !ok {
+			return "", nil, false, err
+		}
+		if format == "docx" {
+			return "docx", fromDoc(func(doc export.Document) ([]byte, error) { return export.DOCX(doc, tmpl, size) }), true, nil
+		}
+		return "pdf", fromDoc(func(doc export.Document) ([]byte, error) { return export.PDF(doc, tmpl, size) }), true, nil
+	case "json":
+		return "json", fromDoc(jsonresume.Export), true, nil
+	case "tex":
+		name := e.Request.URL.Query().Get("template")
+		if name == "" {
+			name = settings.Get(e.App, e.Auth.Id).LaTeXTemplate
+		}
+		tmpl, ok := export.LaTeXTemplateByName(name)
+		if !ok {
+			return "", nil, false, e.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown LaTeX template: " + name})
+		}
+		size, ok, err := exportPageSize(e)
+		if !ok {
+			return "", nil, false, err
+		}
+		return "tex", fromDoc(func(doc export.Document) ([]byte, error) { return export.LaTeX(doc, tmpl, size) }), true, nil
+	case "md":
+		return "md", func(content string) ([]byte, error) { return []byte(content), nil }, true, nil
+	default:
+		return "", nil, false, e.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown format: " + format})
+	}
+}
+
+// HandleListBatchesPB lists the caller's batches
+func HandleListBatchesPB(e *core.RequestEvent) error {
+	auth := e.Auth
+	if auth == nil {
+		return e.JSON(http.StatusUnauthorized, map[string]string{"error": "Not authenticated"})
+	}
+
+	batches, err := batch.List(e.App, auth.Id, 100)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch batches"})
+	}
+
+	result := make([]map[string]any, 0, len(batches))
+	for _, b := range batches {
+		result = append(result, map[string]any{
+			"id":      b.ID,
+			"name":    b.Name,
+			"status":  b.Status,
+			"created": b.Created,
+		})
+	}
+	return e.JSON(http.StatusOK, result)
+}
+
+// HandleGetBatchPB returns one of the caller's batches with its items
+func HandleGetBatchPB(e *core.RequestEvent) error {
+	auth := e.Auth
+	if auth == nil {
+		return e.JSON(http.StatusUnauthorized, map[string]string{"error": "Not authenticated"})
+	}
+
+	b, err := batch.Get(e.App, auth.Id, e.Request.PathValue("id"))
+	if err != nil {
+		return e.JSON(http.StatusNotFound, map[string]string{"error": "Batch not found"})
+	}
+	return e.JSON(http.StatusOK, batchJSON(b))
+}
+
+// HandleCreateBatchAPIPB starts a batch from JSON: the base resume as text
+// or a base id ("profile" or a resumes record), and the job descriptions
+func HandleCreateBatchAPIPB(e *core.RequestEvent) error {
+	auth := e.Auth
+	if auth == nil {
+		return e.JSON(http.StatusUnauthorized, map[string]string{"error": "Not authenticated"})
+	}
+
+	var data struct {
+		Name   string `json:"name"`
+		Base   string `json:"base"`
+		Resume string `json:"resume"`
+		Jobs   []struct {
+			Title          string `json:"title"`
+			Company        string `json:"company"`
+			URL            string `json:"url"`
+			JobDescription string `json:"job_description"`
+		} `json:"jobs"`
+	}
+	if err := e.BindBody(&data); err != nil {
+		return e.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	resume := data.Resume
+	if data.Base != "" {
+		var ok bool
+		if resume, ok = baseResume(e.App, auth.Id, data.Base); !ok {
+			return e.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown base resume: " + data.Base})
+		}
+	}
+	var entries []batch.Entry
+	for _, j := range data.Jobs {
+		entry := batch.Entry{
+			Title:          strings.TrimSpace(j.Title),
+			Company:        strings.TrimSpace(j.Company),
+			URL:            strings.TrimSpace(j.URL),
+			JobDescription: strings.TrimSpace(j.JobDescription),
+		}
+		if entry.JobDescription == "" && entry.URL == "" {
+			return e.JSON(http.StatusBadRequest, map[string]string{"error": "Each job needs a job_description or url"})
+		}
+		entries = append(entries, entry)
+	}
+
+	b, errMsg := startBatch(e.App, auth.Id, data.Name, resume, entries)
+	if errMsg != "" {
+		return e.JSON(http.StatusBadRequest, map[string]string{"error": errMsg})
+	}
+	return e.JSON(http.StatusCreated, batchJSON(b))
+}
+
+func batchJSON(b batch.Batch) map[string]any {
+	items := make([]map[string]any, 0, len(b.Items))
+	for _, it := range b.Items {
+		items = append(items, map[string]any{
+			"id":        it.ID,
+			"position":  it.Position,
+			"title":     it.Title,
+			"company":   it.Company,
+			"url":       it.URL,
+			"status":    it.Status,
+			"note":      it.Note,
+			"error":     it.Error,
+			"job_id":    it.JobID,
+			"resume_id": it.ResumeID,
+		})
+	}
+	return map[string]any{
+		"id":      b.ID,
+		"name":    b.Name,
+		"status":  b.Status,
+		"running": batch.Running(b.ID),
+		"created": b.Created,
+		"items":   items,
+	}
+}
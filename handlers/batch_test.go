@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/johnhkchen/resume-tweaker/batch"
+	"github.com/johnhkchen/resume-tweaker/jobpost"
+	"github.com/johnhkchen/resume-tweaker/quota"
+	"github.com/johnhkchen/resume-tweaker/testapp"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// batchQuotaApp returns an app with the batch and quota collections and a
+// user allowed tweaksPerDay tweaks
+func batchQuotaApp(t *testing.T, tweaksPerDay int64) (core.App, string) {
+	t.Helper()
+	app := testapp.New(t, batch.SetupCollections, quota.SetupCollections)
+	userID := testapp.User(t, app).Id
+	if err := quota.SetOverride(app, userID, quota.Limits{TweaksPerDay: tweaksPerDay}, ""); err != nil {
+		t.Fatal(err)
+	}
+	return app, userID
+}
+
+func batchEntries(n int) []batch.Entry {
+	entries := make([]batch.Entry, n)
+	for i := range entries {
+		entries[i] = batch.Entry{JobDescription: fmt.Sprintf("Job description number %d", i+1)}
+	}
+	return entries
+}
+
+func TestCreateBatchCountsUnstartedItems(t *testing.T) {
+	app, userID := batchQuotaApp(t, 3)
+
+	if _, err := createBatch(app, userID, "First", "resume", batchEntries(2), true); err != nil {
+		t.Fatal(err)
+	}
+	// The first batch's items haven't been charged yet, but hold 2 tweaks
+	_, err := createBatch(app, userID, "Second", "resume", batchEntries(2), true)
+	var short *batchQuotaError
+	if !errors.As(err, &short) {
+		t.Fatalf("createBatch = %v, want a batchQuotaError", err)
+	}
+	if *short != (batchQuotaError{remaining: 1, held: 2, needed: 2}) {
+		t.Errorf("batchQuotaError = %+v", *short)
+	}
+	if msg := batchErrorMessage(err); !strings.Contains(msg, "running batches") {
+		t.Errorf("message %q doesn't mention the running batches", msg)
+	}
+
+	if _, err := createBatch(app, userID, "Third", "resume", batchEntries(1), true); err != nil {
+		t.Fatalf("batch within the quota: %v", err)
+	}
+	// Demo mode doesn't check
+	if _, err := createBatch(app, userID, "Demo", "resume", batchEntries(5), false); err != nil {
+		t.Fatalf("unchecked batch: %v", err)
+	}
+}
+
+func TestCreateBatchQuotaIsAtomic(t *testing.T) {
+	// Creations only interleave with several threads running
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(runtime.GOMAXPROCS(0), 4)))
+
+	const perBatch, attempts = 3, 32
+	app, userID := batchQuotaApp(t, perBatch*2)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created, refused := 0, 0
+	start := make(chan struct{})
+	for range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := createBatch(app, userID, "Batch", "resume", batchEntries(perBatch), true)
+			var short *batchQuotaError
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				created++
+			case errors.As(err, &short):
+				refused++
+			default:
+				t.Errorf("createBatch: %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if created != 2 || refused != attempts-2 {
+		t.Fatalf("created %d batches, refused %d; want 2 and %d", created, refused, attempts-2)
+	}
+}
+
+// finishItems sets every item of b to status, as if a run had ended
+func finishItems(t *testing.T, app core.App, b batch.Batch, status string) batch.Batch {
+	t.Helper()
+	for _, it := range b.Items {
+		record, err := app.FindRecordById(batch.ItemCollection, it.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		record.Set("status", status)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := batch.Get(app, b.UserID, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestRetryBatchCountsFailedItems(t *testing.T) {
+	app, userID := batchQuotaApp(t, 2)
+
+	failed, err := createBatch(app, userID, "Failed", "resume", batchEntries(2), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed = finishItems(t, app, failed, batch.ItemFailed)
+	other, err := createBatch(app, userID, "Other", "resume", batchEntries(1), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The other batch's queued item holds one of the two tweaks
+	_, err = retryBatch(app, userID, failed, true)
+	var short *batchQuotaError
+	if !errors.As(err, &short) {
+		t.Fatalf("retryBatch = %v, want a batchQuotaError", err)
+	}
+	if *short != (batchQuotaError{remaining: 1, held: 1, needed: 2}) {
+		t.Errorf("batchQuotaError = %+v", *short)
+	}
+	if b, _ := batch.Get(app, userID, failed.ID); b.Counts().Failed != 2 {
+		t.Fatalf("refused retry changed the items: %+v", b.Counts())
+	}
+
+	finishItems(t, app, other, batch.ItemDone)
+	retried, err := retryBatch(app, userID, failed, true)
+	if err != nil {
+		t.Fatalf("retry within the quota: %v", err)
+	}
+	if c := retried.Counts(); c.Pending != 2 || retried.Status != batch.StatusRunning {
+		t.Fatalf("retried batch %s with %+v, want running with 2 pending", retried.Status, c)
+	}
+	if held, err := batch.AwaitingJobs(app, userID); err != nil || held != 2 {
+		t.Fatalf("AwaitingJobs = %d, %v; want the 2 requeued items", held, err)
+	}
+}
+
+func TestRetryBatchQuotaIsAtomic(t *testing.T) {
+	// Retries only interleave with several threads running
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(runtime.GOMAXPROCS(0), 4)))
+
+	const perBatch, attempts = 3, 32
+	app, userID := batchQuotaApp(t, perBatch*2)
+
+	var failed []batch.Batch
+	for range attempts {
+		b, err := createBatch(app, userID, "Batch", "resume", batchEntries(perBatch), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		failed = append(failed, finishItems(t, app, b, batch.ItemFailed))
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	retried, refused := 0, 0
+	start := make(chan struct{})
+	for _, b := range failed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := retryBatch(app, userID, b, true)
+			var short *batchQuotaError
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				retried++
+			case errors.As(err, &short):
+				refused++
+			default:
+				t.Errorf("retryBatch: %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if retried != 2 || refused != attempts-2 {
+		t.Fatalf("retried %d batches, refused %d; want 2 and %d", retried, refused, attempts-2)
+	}
+}
+
+func TestBatchErrorMessage(t *testing.T) {
+	exceeded := &quota.ExceededError{Kind: quota.KindTweaksPerDay, Limit: 5, Used: 5}
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&importError{jobpost.ErrNoContent}, jobImportErrorMessage(jobpost.ErrNoContent)},
+		{fmt.Errorf("item 3: %w", &importError{jobpost.ErrBlocked}), jobImportErrorMessage(jobpost.ErrBlocked)},
+		{exceeded, exceeded.Error()},
+		{&batchQuotaError{remaining: 1, needed: 4}, "You have 1 tweaks left today, and this batch needs 4."},
+		{&jobFailedError{"This tweak took too long to finish. Please try again."}, "This tweak took too long to finish. Please try again."},
+		{&jobFailedError{}, jobErrorMessage(nil)},
+		{errPostingTooLong, fmt.Sprintf("That posting is too long (max %d KB).", MaxInputBytes/1024)},
+		{fmt.Errorf("%w: disk full", errJobNotStarted), "Failed to start tweak"},
+		{errResultNotSaved, "The tweak finished but couldn't be saved. Please try again."},
+		{errors.New("unexpected"), jobErrorMessage(nil)},
+	}
+	for _, tt := range tests {
+		if got := batchErrorMessage(tt.err); got != tt.want {
+			t.Errorf("batchErrorMessage(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestBatchErrorsAreLowercase(t *testing.T) {
+	for _, err := range []error{
+		errPostingTooLong,
+		errJobNotStarted,
+		errResultNotSaved,
+		&importError{jobpost.ErrNoContent},
+		&jobFailedError{"Something went wrong. Please try again."},
+		&jobFailedError{},
+		&batchQuotaError{remaining: 1, needed: 2},
+	} {
+		if msg := err.Error(); msg[0] < 'a' || msg[0] > 'z' {
+			t.Errorf("error %q doesn't start lowercase", msg)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/johnhkchen/resume-tweaker/export"
+	"github.com/johnhkchen/resume-tweaker/extract"
+	"github.com/johnhkchen/resume-tweaker/jsonresume"
+	"github.com/johnhkchen/resume-tweaker/settings"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// HandleExportPDFPB downloads a saved tweak as a PDF. The template and
+// size query parameters pick the typographic template and paper size.
+func HandleExportPDFPB(e *core.RequestEvent) error {
+	tmpl, size, ok, err := exportOptions(e)
+	if !ok {
+		return err
+	}
+	return handleExport(e, "pdf", "application/pdf", func(doc export.Document) ([]byte, error) {
+		return export.PDF(doc, tmpl, size)
+	})
+}
+
+// HandleExportDOCXPB downloads a saved tweak as a Word document, taking the
+// same query parameters as the PDF export
+func HandleExportDOCXPB(e *core.RequestEvent) error {
+	tmpl, size, ok, err := exportOptions(e)
+	if !ok {
+		return err
+	}
+	return handleExport(e, "docx", extract.MimeDOCX, func(doc export.Document) ([]byte, error) {
+		return export.DOCX(doc, tmpl, size)
+	})
+}
+
+// HandleExportJSONPB downloads a saved tweak as a JSON Resume document
+func HandleExportJSONPB(e *core.RequestEvent) error {
+	return handleExport(e, "json", "application/json", jsonresume.Export)
+}
+
+// HandleExportLaTeXPB downloads a saved tweak as a single .tex file. The
+// template query parameter picks a LaTeX template, defaulting to the one the
+// user chose last; size is as for the PDF export.
+func HandleExportLaTeXPB(e *core.RequestEvent) error {
+	return handleLaTeXExport(e, false)
+}
+
+// HandleExportLaTeXZipPB downloads a saved tweak as a zip of the .tex file
+// and the template's class file
+func HandleExportLaTeXZipPB(e *core.RequestEvent) error {
+	return handleLaTeXExport(e, true)
+}
+
+func handleLaTeXExport(e *core.RequestEvent, zipped bool) error {
+	prefs := settings.Get(e.App, e.Auth.Id)
+	name := e.Request.URL.Query().Get("template")
+	if name == "" {
+		name = prefs.LaTeXTemplate
+	}
+	tmpl, ok := export.LaTeXTemplateByName(name)
+	if !ok {
+		return e.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown LaTeX template: " + name})
+	}
+	size, ok, err := exportPageSize(e)
+	if !ok {
+		return err
+	}
+
+	// Remember the choice for the next export
+	if tmpl.Name != prefs.LaTeXTemplate {
+		prefs.LaTeXTemplate = tmpl.Name
+		if err := settings.Save(e.App, e.Auth.Id, prefs); err != nil {
+			log.Printf("[Settings] Warning: failed to save settings for %s: %v", e.Auth.Id, err)
+		}
+	}
+
+	if zipped {
+		return handleExport(e, "zip", "application/zip", func(doc export.Document) ([]byte, error) {
+			return export.LaTeXZip(doc, tmpl, size, exportFilename(doc.Title, "tex"))
+		})
+	}
+	return handleExport(e, "tex", "application/x-tex", func(doc export.Document) ([]byte, error) {
+		return export.LaTeX(doc, tmpl, size)
+	})
+}
+
+// exportOptions reads the template and size query parameters. When ok is
+// false the error response has been written.
+func exportOptions(e *core.RequestEvent) (tmpl export.Template, size export.PageSize, ok bool, err error) {
+	name := e.Request.URL.Query().Get("template")
+	tmpl, ok = export.TemplateByName(name)
+	if !ok {
+		return tmpl, size, false, e.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown template: " + name})
+	}
+	size, ok, err = exportPageSize(e)
+	return tmpl, size, ok, err
+}
+
+func exportPageSize(e *core.RequestEvent) (export.PageSize, bool, error) {
+	name := e.Request.URL.Query().Get("size")
+	size, ok := export.PageSizeByName(name)
+	if !ok {
+		return size, false, e.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown page size: " + name})
+	}
+	return size, true, nil
+}
+
+// handleExport renders the saved tweak named in the path with render and
+// sends it as an attachment
+func handleExport(e *core.RequestEvent, ext, contentType string, render func(export.Document) ([]byte, error)) error {
+	content, ok, err := savedTweak(e)
+	if !ok {
+		return err
+	}
+
+	doc := export.Parse(content)
+	data, err := render(doc)
+	if err != nil {
+		log.Printf("[Export] Failed to render %s for %s: %v", ext, e.Auth.Id, err)
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create " + strings.ToUpper(ext)})
+	}
+
+	setAttachment(e, exportFilename(doc.Title, ext))
+	return e.Blob(http.StatusOK, contentType, data)
+}
+
+// savedTweak loads the tweaked content of the user's resumes record named in
+// the path. When ok is false the error response has been written.
+func savedTweak(e *core.RequestEvent) (content string, ok bool, err error) {
+	record, err := e.App.FindRecordById("resumes", e.Request.PathValue("id"))
+	if err != nil || record.GetString("user") != e.Auth.Id {
+		return "", false, e.JSON(http.StatusNotFound, map[string]string{"error": "Resume not found"})
+	}
+	content = record.GetString("tweaked_content")
+	if strings.TrimSpace(content) == "" {
+		return "", false, e.JSON(http.StatusNotFound, map[string]string{"error": "This resume has no tweaked version to export"})
+	}
+	return content, true, nil
+}
+
+var filenameUnsafe = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// exportFilename names a download after the resume's title, e.g.
+// "jane-doe-resume.pdf"
+func exportFilename(title, ext string) string {
+	slug := filenameSlug(title)
+	if slug == "" {
+		return "resume." + ext
+	}
+	return slug + "-resume." + ext
+}
+
+// filenameSlug reduces s to lower-case letters, digits and dashes, short
+// enough for a filename
+func filenameSlug(s string) string {
+	slug := strings.Trim(strings.ToLower(filenameUnsafe.ReplaceAllString(s, "-")), "-")
+	if len(slug) > 60 {
+		slug = strings.Trim(slug[:60], "-")
+	}
+	return slug
+}
+
+func setAttachment(e *core.RequestEvent, filename string) {
+	e.Response.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/johnhkchen/resume-tweaker/jobboard"
+	"github.com/johnhkchen/resume-tweaker/sse"
+	"github.com/johnhkchen/resume-tweaker/templates"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// savedJobsShown caps the saved jobs listed on the tweak page
+const savedJobsShown = 20
+
+// HandleJobBoardListPB lists the open jobs on the board named by the
+// board_source and board_input signals
+func HandleJobBoardListPB(e *core.RequestEvent) error {
+	var req struct {
+		Source string `json:"board_source"`
+		Input  string `json:"board_input"`
+	}
+	if err := e.BindBody(&req); err != nil {
+		return e.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	sw, err := sse.New(e.Response, e.Request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "SSE not supported"})
+	}
+
+	source, board, jobs, errMsg := listBoard(e, req.Source, req.Input)
+	if errMsg != "" {
+		return sw.MergeSignals(sse.Signals{"board_loading": false, "board_error": errMsg})
+	}
+
+	var buf bytes.Buffer
+	if err := templates.BoardJobs(source, board, jobs).Render(e.Request.Context(), &buf); err != nil {
+		log.Printf("[JobBoard] Warning: failed to render jobs: %v", err)
+		return sw.MergeSignals(sse.Signals{"board_loading": false, "board_error": "Something went wrong. Please try again."})
+	}
+	sw.MergeFragments(buf.String())
+	return sw.MergeSignals(sse.Signals{"board_loading": false, "board_error": ""})
+}
+
+// HandleJobBoardSavePB saves the jobs ticked in the board-jobs-form form.
+// The board is listed again rather than trusting job details from the page.
+func HandleJobBoardSavePB(e *core.RequestEvent) error {
+	e.Request.Body = http.MaxBytesReader(e.Response, e.Request.Body, 1<<20)
+	// Read the form before streaming the response
+	parseErr := e.Request.ParseMultipartForm(1 << 20)
+	if errors.Is(parseErr, http.ErrNotMultipart) {
+		parseErr = nil
+	}
+	sourceName, input, picked := e.Request.FormValue("source"), e.Request.FormValue("board"), e.Request.Form["job"]
+
+	sw, err := sse.New(e.Response, e.Request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "SSE not supported"})
+	}
+	if parseErr != nil {
+		return sw.MergeSignals(sse.Signals{"board_saving": false, "board_error": "Invalid request."})
+	}
+	if len(picked) == 0 {
+		return sw.MergeSignals(sse.Signals{"board_saving": false, "board_error": "Tick the jobs to save first."})
+	}
+
+	_, _, jobs, errMsg := listBoard(e, sourceName, input)
+	if errMsg != "" {
+		return sw.MergeSignals(sse.Signals{"board_saving": false, "board_error": errMsg})
+	}
+	wanted := map[string]bool{}
+	for _, id := range picked {
+		wanted[id] = true
+	}
+	saved := 0
+	for _, job := range jobs {
+		if !wanted[job.ExternalID] {
+			continue
+		}
+		if _, err := jobboard.Save(e.App, e.Auth.Id, job); err != nil {
+			log.Printf("[JobBoard] Failed to save %s/%s job %s for %s: %v", job.Source, job.Board, job.ExternalID, e.Auth.Id, err)
+			continue
+		}
+		saved++
+	}
+
+	notice := fmt.Sprintf("Saved %d jobs.", saved)
+	if saved == 1 {
+		notice = "Saved 1 job."
+	}
+	if missing := len(wanted) - saved; missing > 0 {
+		notice += fmt.Sprintf(" %d could not be saved; they may have just closed.", missing)
+	}
+
+	if list, err := jobboard.ListSaved(e.App, e.Auth.Id, savedJobsShown); err != nil {
+		log.Printf("[JobBoard] Warning: failed to list saved jobs for %s: %v", e.Auth.Id, err)
+	} else {
+		var buf bytes.Buffer
+		if err := templates.SavedJobs(list).Render(e.Request.Context(), &buf); err != nil {
+			log.Printf("[JobBoard] Warning: failed to render saved jobs: %v", err)
+		} else {
+			sw.MergeFragments(buf.String())
+		}
+	}
+	return sw.MergeSignals(sse.Signals{"board_saving": false, "board_error": "", "board_notice": notice})
+}
+
+// listBoard resolves a source and board from user input and lists its jobs.
+// Failures come back as a user-facing message.
+func listBoard(e *core.RequestEvent, sourceName, input string) (jobboard.Source, string, []jobboard.Job, string) {
+	source, err := jobboard.Lookup(sourceName)
+	if err != nil {
+		return nil, "", nil, "Pick a job board."
+	}
+	board, ok := source.Board(input)
+	if !ok {
+		return nil, "", nil, fmt.Sprintf("Enter the %s board name or the link to the company's job board.", source.Label())
+	}
+	jobs, err := source.List(e.Request.Context(), board)
+	switch {
+	case errors.Is(err, jobboard.ErrBoardNotFound):
+		return nil, "", nil, fmt.Sprintf("There's no %s board called %q.", source.Label(), board)
+	case err != nil:
+		log.Printf("[JobBoard] Failed to list %s/%s for %s: %v", source.Name(), board, e.Auth.Id, err)
+		return nil, "", nil, fmt.Sprintf("We couldn't reach %s. Please try again.", source.Label())
+	}
+	return source, board, jobs, ""
+}
+
+// HandleSavedJobUsePB fills the job description with a saved job
+func HandleSavedJobUsePB(e *core.RequestEvent) error {
+	job, err := jobboard.GetSaved(e.App, e.Auth.Id, e.Request.PathValue("id"))
+
+	sw, sseErr := sse.New(e.Response, e.Request)
+	if sseErr != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "SSE not supported"})
+	}
+	if err != nil {
+		return sw.MergeSignals(sse.Signals{"job_import_error": "That saved job no longer exists."})
+	}
+
+	text := job.Text()
+	source := "From your saved jobs: " + job.URL
+	if job.Status == jobboard.StatusClosed {
+		source += " - this job has closed"
+	}
+	return sw.MergeSignals(sse.Signals{
+		"job_description":   text,
+		"job_language":      detectLanguage(e.Request.Context(), text),
+		"job_import_error":  "",
+		"job_import_source": source,
+	})
+}
+
+// HandleListJobsPB lists the caller's saved jobs
+func HandleListJobsPB(e *core.RequestEvent) error {
+	auth := e.Auth
+	if auth == nil {
+		return e.JSON(http.StatusUnauthorized, map[string]string{"error": "Not authenticated"})
+	}
+
+	saved, err := jobboard.ListSaved(e.App, auth.Id, 100)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch jobs"})
+	}
+
+	result := make([]map[string]any, 0, len(saved))
+	for _, j := range saved {
+		result = append(result, map[string]any{
+			"id":              j.ID,
+			"source":          j.Source,
+			"board":           j.Board,
+			"external_id":     j.ExternalID,
+			"title":           j.Title,
+			"company":         j.Company,
+			"location":        j.Location,
+			"department":      j.Department,
+			"team":            j.Team,
+			"employment_type": j.EmploymentType,
+			"salary":          j.Salary,
+			"url":             j.URL,
+			"description":     j.Description,
+			"status":          j.Status,
+		})
+	}
+	return e.JSON(http.StatusOK, result)
+}
+
+// HandleRefreshJobsPB checks every saved open job against its board now,
+// closing the ones gone (superusers only)
+func HandleRefreshJobsPB(e *core.RequestEvent) error {
+	closed, err := jobboard.Refresh(e.Request.Context(), e.App)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "Refresh failed"})
+	}
+	return e.JSON(http.StatusOK, map[string]int{"closed": closed})
+}
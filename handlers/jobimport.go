@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/johnhkchen/resume-tweaker/jobpost"
+	"github.com/johnhkchen/resume-tweaker/sse"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// jobFetcher downloads job pages for HandleJobImportPB, refusing private
+// and internal addresses
+var jobFetcher = jobpost.New()
+
+// HandleJobImportPB fetches the job posting at the job_url signal and sends
+// its text back as the job_description signal
+func HandleJobImportPB(e *core.RequestEvent) error {
+	var req struct {
+		JobURL string `json:"job_url"`
+	}
+	if err := e.BindBody(&req); err != nil {
+		return e.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	sw, err := sse.New(e.Response, e.Request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "SSE not supported"})
+	}
+
+	posting, err := jobFetcher.Import(e.Request.Context(), req.JobURL)
+	if err != nil {
+		log.Printf("[JobImport] Import failed for %s (%q): %v", e.Auth.Id, req.JobURL, err)
+		return sw.MergeSignals(sse.Signals{"job_importing": false, "job_import_error": jobImportErrorMessage(err)})
+	}
+	text := posting.Text()
+	if len(text) > MaxInputBytes {
+		return sw.MergeSignals(sse.Signals{
+			"job_importing":    false,
+			"job_import_error": fmt.Sprintf("That job description is too long (max %d KB of text).", MaxInputBytes/1024),
+		})
+	}
+
+	source := "Imported from " + posting.URL
+	if !posting.Structured {
+		source += " - the page had no structured job data, so check nothing is missing or left over"
+	}
+	return sw.MergeSignals(sse.Signals{
+		"job_description":   text,
+		"job_language":      detectLanguage(e.Request.Context(), text),
+		"job_importing":     false,
+		"job_import_error":  "",
+		"job_import_source": source,
+	})
+}
+
+// jobImportErrorMessage turns an import error into user-facing text
+func jobImportErrorMessage(err error) string {
+	var status *jobpost.StatusError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, jobpost.ErrInvalidURL):
+		return "Enter the link to a job posting, starting with https://."
+	case errors.Is(err, jobpost.ErrBlocked):
+		return "That address can't be imported. Use the public link to the posting."
+	case errors.Is(err, jobpost.ErrTooLarge):
+		return "That page is too large to import. Paste the job description instead."
+	case errors.Is(err, jobpost.ErrNotHTML):
+		return "That link isn't a web page. Paste the job description instead."
+	case errors.Is(err, jobpost.ErrNoContent):
+		return "We couldn't find a job description on that page. Paste it instead."
+	case errors.As(err, &status) && (status.Code == http.StatusUnauthorized || status.Code == http.StatusForbidden):
+		return "That page needs a login or blocks imports. Paste the job description instead."
+	case errors.As(err, &status) && (status.Code == http.StatusNotFound || status.Code == http.StatusGone):
+		return "That posting wasn't found. It may have been taken down."
+	case errors.As(err, &status):
+		return fmt.Sprintf("The site returned an error (%d). Try again later or paste the job description instead.", status.Code)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "The site took too long to respond. Try again or paste the job description instead."
+	default:
+		return "We couldn't reach that page. Check the link or paste the job description instead."
+	}
+}
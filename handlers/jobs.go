@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johnhkchen/resume-tweaker/jobs"
+	"github.com/johnhkchen/resume-tweaker/sse"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// HandleTweakJobStreamPB re-attaches to a tweak job, e.g. after a reload or
+// a dropped connection. The client says what it already has with the
+// Last-Event-ID header or an ?offset= of the last version it received;
+// without either it gets the full current state.
+func HandleTweakJobStreamPB(e *core.RequestEvent) error {
+	job, err := jobs.Get(e.App, e.Auth.Id, e.Request.PathValue("id"))
+	if err != nil {
+		return e.JSON(http.StatusNotFound, map[string]string{"error": "Tweak not found"})
+	}
+
+	since := 0
+	if id, version, ok := parseJobEventID(e.Request.Header.Get("Last-Event-ID")); ok && id == job.ID {
+		since = version
+	} else if offset, err := strconv.Atoi(e.Request.URL.Query().Get("offset")); err == nil && offset > 0 {
+		since = offset
+	}
+	return streamJob(e, job, since)
+}
+
+// jobFromLastEventID finds the job a retried stream request was watching
+func jobFromLastEventID(e *core.RequestEvent) (*jobs.Job, int, bool) {
+	id, version, ok := parseJobEventID(e.Request.Header.Get("Last-Event-ID"))
+	if !ok {
+		return nil, 0, false
+	}
+	job, err := jobs.Get(e.App, e.Auth.Id, id)
+	if err != nil {
+		return nil, 0, false
+	}
+	return job, version, true
+}
+
+// streamJob streams a job to the client from after version since, until the
+// job finishes or the client goes away. The job keeps running either way.
+func streamJob(e *core.RequestEvent, job *jobs.Job, since int) error {
+	sw, err := sse.New(e.Response, e.Request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "SSE not supported"})
+	}
+
+	ctx := e.Request.Context()
+	if u, _ := job.Since(0); since > u.Version {
+		// Not a version of this job - start over
+		since = 0
+	}
+	rs := newResultStream(ctx, sw, job.ID, since > 0)
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		u, changed := job.Since(since)
+		if u.Version > since || !rs.started {
+			rs.Send(u)
+			since = u.Version
+		}
+		if u.Done {
+			return nil
+		}
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-changed:
+				break wait
+			case <-heartbeat.C:
+				// Keep proxies from closing the stream during long pauses
+				if time.Since(sw.LastWrite()) >= heartbeatInterval {
+					sw.Comment("heartbeat")
+				}
+			}
+		}
+
+		// Coalesce bursts of tokens into one update per flush interval
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(resultFlushInterval):
+		}
+	}
+}
+
+// jobEventID is the SSE event id for a job version
+func jobEventID(jobID string, version int) string {
+	return jobID + ":" + strconv.Itoa(version)
+}
+
+func parseJobEventID(id string) (string, int, bool) {
+	jobID, raw, ok := strings.Cut(id, ":")
+	if !ok || jobID == "" {
+		return "", 0, false
+	}
+	version, err := strconv.Atoi(raw)
+	if err != nil || version < 0 {
+		return "", 0, false
+	}
+	return jobID, version, true
+}
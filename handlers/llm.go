@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/johnhkchen/resume-tweaker/llm"
+	"github.com/johnhkchen/resume-tweaker/scheduler"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// defaultLLMConcurrency is used when LLM_MAX_CONCURRENCY is unset or invalid
+const defaultLLMConcurrency = 4
+
+// llmScheduler caps in-flight LLM calls across all users
+var llmScheduler = scheduler.New(llmConcurrencyFromEnv())
+
+func llmConcurrencyFromEnv() int {
+	raw := os.Getenv("LLM_MAX_CONCURRENCY")
+	if raw == "" {
+		return defaultLLMConcurrency
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		log.Printf("[LLM] Invalid LLM_MAX_CONCURRENCY %q, using %d", raw, defaultLLMConcurrency)
+		return defaultLLMConcurrency
+	}
+	return n
+}
+
+// HandleLLMQueueStatsPB reports LLM queue depth, wait-time metrics and
+// circuit breaker states (superusers only)
+func HandleLLMQueueStatsPB(e *core.RequestEvent) error {
+	return e.JSON(http.StatusOK, map[string]any{
+		"queue":    llmScheduler.Stats(),
+		"breakers": llm.BreakerStates(),
+	})
+}
@@ -3,12 +3,28 @@ package handlers
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	bamlpkg "github.com/boundaryml/baml/engine/language_client_go/pkg"
 	baml "github.com/johnhkchen/resume-tweaker/baml_client/baml_client"
+	"github.com/johnhkchen/resume-tweaker/budget"
+	"github.com/johnhkchen/resume-tweaker/jobboard"
+	"github.com/johnhkchen/resume-tweaker/jobs"
+	"github.com/johnhkchen/resume-tweaker/lang"
+	"github.com/johnhkchen/resume-tweaker/llm"
+	"github.com/johnhkchen/resume-tweaker/offline"
+	"github.com/johnhkchen/resume-tweaker/profile"
+	"github.com/johnhkchen/resume-tweaker/quota"
+	"github.com/johnhkchen/resume-tweaker/redact"
+	"github.com/johnhkchen/resume-tweaker/settings"
+	"github.com/johnhkchen/resume-tweaker/sse"
 	"github.com/johnhkchen/resume-tweaker/templates"
 	"github.com/pocketbase/pocketbase/core"
 )
@@ -42,21 +58,80 @@ func HandleLogoutPagePB(e *core.RequestEvent) error {
 
 // HandleTweakPagePB serves the main tweak interface (protected)
 func HandleTweakPagePB(e *core.RequestEvent) error {
+	status, err := quota.GetStatus(e.App, e.Auth.Id, time.Now())
+	if err != nil {
+		log.Printf("[Quota] Warning: failed to load quota for %s: %v", e.Auth.Id, err)
+	}
+
+	// Re-attach to a tweak still running from before a reload
+	activeJobID := ""
+	if job := jobs.Active(e.Auth.Id, modeTweak, modeTranslate); job != nil {
+		activeJobID = job.ID
+	}
+
+	savedJobs, err := jobboard.ListSaved(e.App, e.Auth.Id, savedJobsShown)
+	if err != nil {
+		log.Printf("[JobBoard] Warning: failed to list saved jobs for %s: %v", e.Auth.Id, err)
+	}
+
+	_, hasProfile := profile.Get(e.App, e.Auth.Id)
+
 	var buf bytes.Buffer
-	if err := templates.TweakPage().Render(e.Request.Context(), &buf); err != nil {
+	if err := templates.TweakPage(status, settings.Get(e.App, e.Auth.Id), activeJobID, savedJobs, hasProfile).Render(e.Request.Context(), &buf); err != nil {
 		return e.String(http.StatusInternalServerError, "Failed to render page")
 	}
 	return e.HTML(http.StatusOK, buf.String())
 }
 
+// MaxInputBytes caps each pasted input. Anything under this is accepted and
+// then fitted to the model's token budget.
+const MaxInputBytes = 200 * 1024
+
+// Kinds of resumes record. Uploads hold only an original; tweaks also hold
+// a result. Records from before kinds existed are tweaks.
+const (
+	ResumeKindTweak  = "tweak"
+	ResumeKindUpload = "upload"
+)
+
+// Stream modes
+const (
+	// modeTweak tailors the resume to a job description
+	modeTweak = "tweak"
+	// modeTranslate translates and localizes the resume
+	modeTranslate = "translate"
+	// modeBatch tailors the resume as one item of a batch; it runs like
+	// modeTweak, but the tweak page doesn't re-attach to it
+	modeBatch = "batch"
+)
+
+// tweakRequest is a validated request for streamBAMLMode
+type tweakRequest struct {
+	Mode           string
+	Resume         string
+	JobDescription string
+	// TargetLanguage is a lang code; "" keeps the resume's language
+	TargetLanguage string
+	// ResumeLanguage is the detected resume language, "" if unknown
+	ResumeLanguage string
+	RedactPII      bool
+}
+
 // HandleTweakStreamPB handles SSE streaming for resume tweaking
 func HandleTweakStreamPB(e *core.RequestEvent) error {
-	ctx := e.Request.Context()
+	// A retried request carries the last event it saw; pick the job back up
+	// instead of starting (and charging for) another one
+	if job, since, ok := jobFromLastEventID(e); ok {
+		return streamJob(e, job, since)
+	}
 
 	// Datastar sends signals as top-level JSON keys
 	var body struct {
 		Resume         string `json:"resume"`
 		JobDescription string `json:"job_description"`
+		RedactPII      *bool  `json:"redact_pii"`
+		Mode           string `json:"mode"`
+		TargetLanguage string `json:"target_language"`
 	}
 	if err := e.BindBody(&body); err != nil {
 		return e.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON: " + err.Error()})
@@ -64,99 +139,419 @@ func HandleTweakStreamPB(e *core.RequestEvent) error {
 
 	resume := body.Resume
 	jobDesc := body.JobDescription
+	mode := body.Mode
+	if mode == "" {
+		mode = modeTweak
+	}
 
 	// Validate
+	if mode != modeTweak && mode != modeTranslate {
+		return e.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown mode: " + mode})
+	}
+	if body.TargetLanguage != "" {
+		if _, ok := lang.Lookup(body.TargetLanguage); !ok {
+			return e.JSON(http.StatusBadRequest, map[string]string{"error": "Unsupported language: " + body.TargetLanguage})
+		}
+	}
 	if len(resume) < 50 {
 		return e.JSON(http.StatusBadRequest, map[string]string{"error": "Resume too short (min 50 chars)"})
 	}
-	if len(jobDesc) < 20 {
+	if mode == modeTweak && len(jobDesc) < 20 {
 		return e.JSON(http.StatusBadRequest, map[string]string{"error": "Job description too short (min 20 chars)"})
 	}
+	if mode == modeTranslate && body.TargetLanguage == "" {
+		return e.JSON(http.StatusBadRequest, map[string]string{"error": "Choose a language to translate into"})
+	}
+	if len(resume) > MaxInputBytes || len(jobDesc) > MaxInputBytes {
+		return e.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": fmt.Sprintf("Input too long (max %d KB each)", MaxInputBytes/1024)})
+	}
 
-	// Set SSE headers
-	w := e.Response
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
+	// Redaction is on by default; remember the user's choice if it changed
+	prefs := settings.Get(e.App, e.Auth.Id)
+	if body.RedactPII != nil && *body.RedactPII != prefs.RedactPII {
+		prefs.RedactPII = *body.RedactPII
+		if err := settings.Save(e.App, e.Auth.Id, prefs); err != nil {
+			log.Printf("[Settings] Warning: failed to save settings for %s: %v", e.Auth.Id, err)
+		}
+	}
 
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "SSE not supported"})
+	// The same input is already being tweaked for this user (double submit,
+	// second tab) - watch that job instead of paying for another stream
+	key := jobs.Key(e.Auth.Id, mode, body.TargetLanguage, strconv.FormatBool(prefs.RedactPII), resume, jobDesc)
+	if job := jobs.Join(key); job != nil {
+		return streamJob(e, job, 0)
 	}
 
-	// Send initial state - using datastar-merge-signals for beta.11
-	sendDatastarSignals(w, flusher, `{"loading":true,"result":"","error":"","step":0}`)
+	// Enforce per-user quota before any LLM work (demo mode is free)
+	llmEnabled := os.Getenv("ANTHROPIC_API_KEY") != ""
+	var usage *quota.Event
+	var quotaStatus quota.Status
+	if llmEnabled {
+		var ok bool
+		var err error
+		usage, quotaStatus, ok, err = enforceQuota(e, mode)
+		if !ok {
+			return err
+		}
+	}
 
-	// Check if ANTHROPIC_API_KEY is set
-	if os.Getenv("ANTHROPIC_API_KEY") == "" {
-		streamDemoMode(ctx, w, flusher)
+	// Start the job in the background; this request just watches it
+	ctx := e.Request.Context()
+	resumeLang, jobLang := detectLanguage(ctx, resume), detectLanguage(ctx, jobDesc)
+	job, joined, err := jobs.Start(e.App, e.Auth.Id, mode, key, initialTweakSignals(resumeLang, jobLang))
+	if err != nil {
+		log.Printf("[Jobs] Failed to start job for %s: %v", e.Auth.Id, err)
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to start tweak"})
+	}
+	if joined {
+		// Lost a race with an identical request; it's already paid for
+		usage.Cancel()
+		return streamJob(e, job, 0)
+	}
+
+	go runTweak(e.App, job, tweakRequest{
+		Mode:           mode,
+		Resume:         resume,
+		JobDescription: jobDesc,
+		TargetLanguage: body.TargetLanguage,
+		ResumeLanguage: resumeLang,
+		RedactPII:      prefs.RedactPII,
+	}, llmEnabled, usage, quotaStatus)
+
+	return streamJob(e, job, 0)
+}
+
+// initialTweakSignals are the signals a new tweak job starts with
+func initialTweakSignals(resumeLang, jobLang string) sse.Signals {
+	return sse.Signals{
+		"loading":           true,
+		"result":            "",
+		"error":             "",
+		"step":              0,
+		"queue_position":    0,
+		"served_by":         "",
+		"notice":            "",
+		"redactions":        []redact.Entry{},
+		"injection_warning": "",
+		"trims":             []budget.Trim{},
+		"resume_language":   resumeLang,
+		"job_language":      jobLang,
+		"resume_id":         "",
+	}
+}
+
+// jobTimeout bounds a whole job, including time queued for an LLM slot
+const jobTimeout = 15 * time.Minute
+
+var (
+	errJobTimeout             = errors.New("job timed out")
+	errTranslationUnavailable = errors.New("translation unavailable")
+)
+
+// jobErrorMessage turns a job error into something to show the user
+func jobErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, errTranslationUnavailable):
+		return "Translation is temporarily unavailable. Please try again in a few minutes."
+	case errors.Is(err, errJobTimeout):
+		return "This tweak took too long to finish. Please try again."
+	default:
+		return "Something went wrong. Please try again."
+	}
+}
+
+// runTweak generates a tweak into job. It runs detached from any request,
+// so the result is kept and saved if the browser disconnects.
+func runTweak(app core.App, job *jobs.Job, req tweakRequest, llmEnabled bool, usage *quota.Event, quotaStatus quota.Status) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	if !llmEnabled {
+		streamDemoMode(ctx, job)
+		job.Finish(savedResumeSignals(app, job, req, sse.Signals{"served_by": llm.PathDemo}))
+		return
+	}
+
+	// Wait for an LLM slot, reporting queue position while waiting
+	release, err := llmScheduler.Acquire(ctx, job.UserID, func(pos int) {
+		job.Signal(sse.Signals{"queue_position": pos})
+	})
+	if err != nil {
+		job.Fail(jobErrorMessage(errJobTimeout), sse.Signals{"queue_position": 0})
+		return
+	}
+	defer release()
+	job.Signal(sse.Signals{"queue_position": 0})
+
+	// Use BAML streaming, collecting token usage for the quota
+	collector, err := baml.NewCollector(req.Mode)
+	if err != nil {
+		log.Printf("[Quota] Warning: failed to create collector: %v", err)
+	}
+	var opts []baml.CallOptionFunc
+	if collector != nil {
+		opts = append(opts, baml.WithCollector(collector))
+	}
+	err = streamBAMLMode(ctx, job, req, opts...)
+
+	usage.Finish(collectorTokens(collector))
+	final := sse.Signals{}
+	if remaining := quotaStatus.TweaksRemaining(); remaining >= 0 {
+		final["quota_remaining"] = remaining
+	}
+	if err != nil {
+		job.Fail(jobErrorMessage(err), final)
+		return
+	}
+	job.Finish(savedResumeSignals(app, job, req, final))
+}
+
+// savedResumeSignals saves a finished tweak as a resumes record, so it can
+// be exported, and adds its id to signals as resume_id
+func savedResumeSignals(app core.App, job *jobs.Job, req tweakRequest, signals sse.Signals) sse.Signals {
+	collection, err := app.FindCollectionByNameOrId("resumes")
+	if err != nil {
+		log.Printf("[Tweak] Warning: failed to save result of job %s: %v", job.ID, err)
+		return signals
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("user", job.UserID)
+	record.Set("kind", ResumeKindTweak)
+	record.Set("original_content", req.Resume)
+	record.Set("job_description", req.JobDescription)
+	record.Set("tweaked_content", job.Content())
+	if err := app.Save(record); err != nil {
+		log.Printf("[Tweak] Warning: failed to save result of job %s: %v", job.ID, err)
+		return signals
+	}
+	signals["resume_id"] = record.Id
+	return signals
+}
+
+// streamBAMLMode streams the tweak (or translation) from the first available
+// model, falling back down the model chain and finally to offline suggestions.
+// When req.RedactPII is set, personal details are swapped for placeholder
+// tokens before anything leaves the server and restored in the output.
+func streamBAMLMode(ctx context.Context, job *jobs.Job, req tweakRequest, opts ...baml.CallOptionFunc) error {
+	job.Signal(sse.Signals{"step": 1})
+
+	// Redact first so nothing sent to a model, including the role
+	// summaries made while fitting, holds personal details
+	red := redact.New()
+	llmResume, llmJobDesc := req.Resume, req.JobDescription
+	if req.RedactPII {
+		llmResume = red.RedactResume(llmResume)
+		llmJobDesc = red.Redact(llmJobDesc)
+		job.Signal(sse.Signals{"redactions": red.Entries()})
+	}
+
+	// Fit the inputs into the token budget of the primary model
+	fitted := budget.Fit(llm.Routes()[0].Client, llmResume, llmJobDesc, budget.MaxInputTokens(), roleSummarizer(ctx, opts...))
+	if len(fitted.Trims) > 0 {
+		for i := range fitted.Trims {
+			fitted.Trims[i].Description = red.Restore(fitted.Trims[i].Description)
+		}
+		job.Signal(sse.Signals{"trims": fitted.Trims})
+	}
+	llmResume, llmJobDesc = fitted.Resume, fitted.JobDescription
+
+	// Screen pasted text for prompt injection before it reaches the model
+	llmResume, llmJobDesc, warning := screenInputs(ctx, llmResume, llmJobDesc, opts...)
+	if warning != "" {
+		job.Signal(sse.Signals{"injection_warning": warning})
+	}
+
+	call := tweakCall(req, llmResume, llmJobDesc)
+
+	var content string
+	route, err := llm.Fallback(ctx, llm.Routes(), func(route llm.Route) error {
+		var err error
+		content, err = streamRoute(ctx, job, route, red, call, opts...)
+		if err != nil && ctx.Err() == nil {
+			job.SetContent("")
+			job.Signal(sse.Signals{"notice": "The AI model is slow or unavailable right now, switching to a backup..."})
+		}
+		return err
+	})
+	if ctx.Err() != nil {
+		// Out of time overall - not the model's fault
+		return errJobTimeout
+	}
+	if err == nil {
+		job.SetContent(content)
+		job.Signal(sse.Signals{"step": 4, "served_by": route.Path, "notice": ""})
 		return nil
 	}
 
-	// Use BAML streaming
-	streamBAMLMode(ctx, w, flusher, resume, jobDesc)
+	if req.Mode == modeTranslate {
+		// Translation needs a model - there is no offline equivalent
+		job.Signal(sse.Signals{"step": 0, "notice": ""})
+		return errTranslationUnavailable
+	}
+	streamOfflineMode(job, req.Resume, req.JobDescription)
 	return nil
 }
 
-// streamBAMLMode uses BAML to stream real LLM responses
-func streamBAMLMode(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, resume, jobDesc string) {
-	sendDatastarSignals(w, flusher, `{"step":1}`)
+// streamCall starts a string-returning BAML stream
+type streamCall func(ctx context.Context, opts ...baml.CallOptionFunc) (<-chan baml.StreamValue[string, string], error)
+
+// tweakCall picks the BAML function for the request's mode and language
+func tweakCall(req tweakRequest, resume, jobDesc string) streamCall {
+	if req.Mode == modeTranslate {
+		target, _ := lang.Lookup(req.TargetLanguage)
+		return func(ctx context.Context, opts ...baml.CallOptionFunc) (<-chan baml.StreamValue[string, string], error) {
+			return baml.Stream.TranslateResume(ctx, resume, target.Name, target.Conventions, opts...)
+		}
+	}
+
+	targetName := "the same language as the original resume"
+	code := req.TargetLanguage
+	if code == "" {
+		code = req.ResumeLanguage
+	}
+	if target, ok := lang.Lookup(code); ok {
+		targetName = target.Name
+	}
+	return func(ctx context.Context, opts ...baml.CallOptionFunc) (<-chan baml.StreamValue[string, string], error) {
+		return baml.Stream.TweakResume(ctx, resume, jobDesc, targetName, opts...)
+	}
+}
+
+// streamRoute streams a BAML call from a single model, enforcing the
+// first-token and total generation deadlines. Output is passed through red
+// to restore any redacted values.
+func streamRoute(ctx context.Context, job *jobs.Job, route llm.Route, red *redact.Redactor, call streamCall, opts ...baml.CallOptionFunc) (string, error) {
+	deadlines := llm.StageDeadlines()
+	routeCtx, cancel := context.WithTimeout(ctx, deadlines.Total)
+	defer cancel()
 
-	stream, err := baml.Stream.TweakResume(ctx, resume, jobDesc)
+	opts = append(opts[:len(opts):len(opts)], baml.WithClientRegistry(routeRegistry(route)))
+	stream, err := call(routeCtx, opts...)
 	if err != nil {
-		sendDatastarSignals(w, flusher, fmt.Sprintf(`{"error":"Failed to start: %s","loading":false,"step":0}`, err.Error()))
-		return
+		return "", fmt.Errorf("failed to start: %w", err)
 	}
+	// Drain anything left so the BAML goroutine can exit
+	defer func() {
+		go func() {
+			for range stream {
+			}
+		}()
+	}()
+
+	job.Signal(sse.Signals{"step": 2})
 
-	sendDatastarSignals(w, flusher, `{"step":2}`)
+	// Until the first token this is the first-token deadline; after that it
+	// is reset on every token to catch an upstream that stalls mid-stream
+	idle := time.NewTimer(deadlines.FirstToken)
+	defer idle.Stop()
 
 	var lastContent string
-	stepAdvanced := false
+	started := false
 
-	for value := range stream {
+	for {
+		var value baml.StreamValue[string, string]
+		var ok bool
 		select {
-		case <-ctx.Done():
-			return
-		default:
+		case <-routeCtx.Done():
+			return lastContent, fmt.Errorf("generation deadline exceeded: %w", routeCtx.Err())
+		case <-idle.C:
+			if !started {
+				return "", fmt.Errorf("no output within %s", deadlines.FirstToken)
+			}
+			return lastContent, fmt.Errorf("no output for %s", deadlines.Idle)
+		case value, ok = <-stream:
+		}
+		if !ok {
+			break
 		}
 
 		if value.IsError {
-			sendDatastarSignals(w, flusher, fmt.Sprintf(`{"error":"Stream error: %s","loading":false,"step":0}`, value.Error.Error()))
-			return
+			return lastContent, value.Error
 		}
 
-		if !stepAdvanced {
-			sendDatastarSignals(w, flusher, `{"step":3}`)
-			stepAdvanced = true
+		if !started {
+			job.Signal(sse.Signals{"step": 3})
+			started = true
 		}
+		idle.Reset(deadlines.Idle)
 
 		if value.IsFinal {
 			if final := value.Final(); final != nil {
-				lastContent = *final
+				lastContent = red.Restore(*final)
 			}
 		} else {
 			if partial := value.Stream(); partial != nil {
-				lastContent = *partial
-				sendDatastarSignals(w, flusher, fmt.Sprintf(`{"result":%q}`, lastContent))
+				lastContent = red.RestorePartial(*partial)
+				job.SetContent(lastContent)
 			}
 		}
 	}
 
-	sendDatastarSignals(w, flusher, fmt.Sprintf(`{"step":4,"result":%q,"loading":false}`, lastContent))
+	if lastContent == "" {
+		return "", fmt.Errorf("empty response")
+	}
+	return lastContent, nil
+}
+
+// routeRegistry returns a client registry that forces the route's client,
+// overriding the client declared on the BAML function
+func routeRegistry(route llm.Route) *bamlpkg.ClientRegistry {
+	registry := &bamlpkg.ClientRegistry{}
+	registry.SetPrimaryClient(route.Client)
+	return registry
+}
+
+// summarizeTimeout bounds each role summary made while fitting the budget
+const summarizeTimeout = 15 * time.Second
+
+// roleSummarizer summarizes older roles with the model. Fit cuts a role to
+// its first bullet instead when a call fails.
+func roleSummarizer(ctx context.Context, opts ...baml.CallOptionFunc) budget.Summarizer {
+	return func(heading string, bullets []string) (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, summarizeTimeout)
+		defer cancel()
+		return baml.SummarizeRole(ctx, heading, strings.Join(bullets, "\n"), opts...)
+	}
+}
+
+// detectTimeout bounds a language detection call; the stopword heuristic
+// answers instead when it runs out
+const detectTimeout = 5 * time.Second
+
+// detectLanguage names the language of a resume or job description with the
+// model, or with lang's stopword heuristic when no model is configured or
+// the call fails. The model only sees a redacted sample.
+func detectLanguage(ctx context.Context, text string) string {
+	if os.Getenv("ANTHROPIC_API_KEY") == "" {
+		return lang.Detect(text)
+	}
+	return lang.DetectWith(text, func(sample string) (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, detectTimeout)
+		defer cancel()
+		return baml.DetectLanguage(ctx, redact.New().RedactResume(sample))
+	})
+}
+
+// streamOfflineMode serves rule-based suggestions when every model is down
+func streamOfflineMode(job *jobs.Job, resume, jobDesc string) {
+	job.Signal(sse.Signals{"step": 3})
+	job.SetContent(offline.Suggest(resume, jobDesc))
+	job.Signal(sse.Signals{"step": 4, "served_by": llm.PathOffline, "notice": ""})
 }
 
 // streamDemoMode streams demo content without LLM
-func streamDemoMode(ctx context.Context, w http.ResponseWriter, flusher http.Flusher) {
-	sendDatastarSignals(w, flusher, `{"step":1}`)
+func streamDemoMode(ctx context.Context, job *jobs.Job) {
+	job.Signal(sse.Signals{"step": 1})
 	time.Sleep(300 * time.Millisecond)
 
-	sendDatastarSignals(w, flusher, `{"step":2}`)
+	job.Signal(sse.Signals{"step": 2})
 	time.Sleep(300 * time.Millisecond)
 
-	sendDatastarSignals(w, flusher, `{"step":3}`)
+	job.Signal(sse.Signals{"step": 3})
 	time.Sleep(300 * time.Millisecond)
 
-	sendDatastarSignals(w, flusher, `{"step":4}`)
+	job.Signal(sse.Signals{"step": 4})
 
 	chunks := []string{
 		"## Resume Analysis\n\n",
@@ -180,19 +575,10 @@ func streamDemoMode(ctx context.Context, w http.ResponseWriter, flusher http.Flu
 			return
 		default:
 			fullResult += chunk
-			sendDatastarSignals(w, flusher, fmt.Sprintf(`{"result":%q}`, fullResult))
+			job.SetContent(fullResult)
 			time.Sleep(100 * time.Millisecond)
 		}
 	}
-
-	sendDatastarSignals(w, flusher, `{"loading":false}`)
-}
-
-// sendDatastarSignals sends a Datastar SSE event to merge signals
-// Uses datastar-merge-signals for compatibility with Datastar beta.8-11
-func sendDatastarSignals(w http.ResponseWriter, flusher http.Flusher, signals string) {
-	fmt.Fprintf(w, "event: datastar-merge-signals\ndata: signals %s\n\n", signals)
-	flusher.Flush()
 }
 
 // HandleCreateResumePB saves a resume to PocketBase
@@ -221,6 +607,7 @@ func HandleCreateResumePB(e *core.RequestEvent) error {
 
 	record := core.NewRecord(collection)
 	record.Set("user", auth.Id)
+	record.Set("kind", ResumeKindTweak)
 	record.Set("original_content", data.OriginalContent)
 	record.Set("job_description", data.JobDescription)
 	record.Set("tweaked_content", data.TweakedContent)
@@ -235,7 +622,7 @@ func HandleCreateResumePB(e *core.RequestEvent) error {
 	})
 }
 
-// HandleListResumesPB lists user's saved resumes
+// HandleListResumesPB lists user's saved tweaks, leaving out bare uploads
 func HandleListResumesPB(e *core.RequestEvent) error {
 	auth := e.Auth
 	if auth == nil {
@@ -244,11 +631,11 @@ func HandleListResumesPB(e *core.RequestEvent) error {
 
 	records, err := e.App.FindRecordsByFilter(
 		"resumes",
-		"user = {:userId}",
+		"user = {:userId} && kind != {:upload}",
 		"-created",
 		10,
 		0,
-		map[string]any{"userId": auth.Id},
+		map[string]any{"userId": auth.Id, "upload": ResumeKindUpload},
 	)
 	if err != nil {
 		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch resumes"})
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/johnhkchen/resume-tweaker/profile"
+	"github.com/johnhkchen/resume-tweaker/sse"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// HandleProfileResumePB fills the resume with the baseline rendered from
+// the caller's master profile
+func HandleProfileResumePB(e *core.RequestEvent) error {
+	p, ok := profile.Get(e.App, e.Auth.Id)
+
+	sw, err := sse.New(e.Response, e.Request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "SSE not supported"})
+	}
+	if !ok {
+		return sw.MergeSignals(sse.Signals{"upload_error": "You don't have a saved profile yet. Upload a LinkedIn data export or JSON Resume file to create one."})
+	}
+
+	text := p.Markdown()
+	return sw.MergeSignals(sse.Signals{
+		"resume":          text,
+		"resume_language": detectLanguage(e.Request.Context(), text),
+		"upload_error":    "",
+	})
+}
+
+// HandleGetProfilePB returns the caller's master profile as JSON Resume
+func HandleGetProfilePB(e *core.RequestEvent) error {
+	auth := e.Auth
+	if auth == nil {
+		return e.JSON(http.StatusUnauthorized, map[string]string{"error": "Not authenticated"})
+	}
+
+	p, ok := profile.Get(e.App, auth.Id)
+	if !ok {
+		return e.JSON(http.StatusNotFound, map[string]string{"error": "No profile saved"})
+	}
+	return e.JSON(http.StatusOK, map[string]any{"source": p.Source, "resume": p.Resume})
+}
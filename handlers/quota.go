@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	baml "github.com/johnhkchen/resume-tweaker/baml_client/baml_client"
+	"github.com/johnhkchen/resume-tweaker/quota"
+	"github.com/johnhkchen/resume-tweaker/sse"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// quotaErrorResponse is the structured body returned when a quota is exhausted
+type quotaErrorResponse struct {
+	Error   string    `json:"error"`
+	Code    string    `json:"code"`
+	Quota   string    `json:"quota"`
+	Limit   int64     `json:"limit"`
+	Used    int64     `json:"used"`
+	ResetAt time.Time `json:"reset_at"`
+}
+
+// enforceQuota reserves one request of the caller's quota before an LLM
+// endpoint runs. If the quota is exhausted it streams the error to the page
+// as signals and returns ok=false. Call Finish on the returned event with
+// the token count.
+func enforceQuota(e *core.RequestEvent, endpoint string) (event *quota.Event, status quota.Status, ok bool, err error) {
+	event, status, exceeded := reserveQuota(e.App, e.Auth.Id, endpoint)
+	if exceeded == nil {
+		return event, status, true, nil
+	}
+
+	// The tweak form posts with Datastar, which only understands SSE
+	sw, err := sse.New(e.Response, e.Request)
+	if err != nil {
+		return nil, status, false, e.JSON(http.StatusInternalServerError, map[string]string{"error": "SSE not supported"})
+	}
+	return nil, status, false, sw.MergeSignals(quotaExceededSignals(exceeded))
+}
+
+// quotaExceededSignals tell the tweak page a quota ran out and when it resets
+func quotaExceededSignals(exceeded *quota.ExceededError) sse.Signals {
+	signals := sse.Signals{
+		"loading":        false,
+		"error":          exceeded.Error(),
+		"quota_reset_at": exceeded.ResetAt.Format(quotaResetLayout),
+	}
+	if exceeded.Kind == quota.KindTweaksPerDay {
+		signals["quota_remaining"] = 0
+	}
+	return signals
+}
+
+// quotaResetLayout formats quota reset times for the page
+const quotaResetLayout = "Jan 2, 15:04 MST"
+
+// reserveQuota reserves one request of a user's quota, returning the
+// exceeded error instead if the quota is exhausted
+func reserveQuota(app core.App, userID, endpoint string) (*quota.Event, quota.Status, *quota.ExceededError) {
+	event, status, err := quota.Reserve(app, userID, endpoint, time.Now())
+
+	var exceeded *quota.ExceededError
+	if errors.As(err, &exceeded) {
+		return nil, status, exceeded
+	}
+	if err != nil {
+		// Fail open - a broken usage table shouldn't take the product down
+		log.Printf("[Quota] Warning: failed to reserve quota for %s: %v", userID, err)
+		event, err = quota.Record(app, userID, endpoint)
+		if err != nil {
+			log.Printf("[Quota] Warning: failed to record usage for %s: %v", userID, err)
+		}
+	}
+	return event, status, nil
+}
+
+// collectorTokens returns the total tokens recorded by a BAML collector
+func collectorTokens(collector baml.Collector) int64 {
+	if collector == nil {
+		return 0
+	}
+	usage, err := collector.Usage()
+	if err != nil {
+		return 0
+	}
+	in, _ := usage.InputTokens()
+	out, _ := usage.OutputTokens()
+	return in + out
+}
+
+// HandleQuotaStatusPB returns the caller's current usage and limits
+func HandleQuotaStatusPB(e *core.RequestEvent) error {
+	auth := e.Auth
+	if auth == nil {
+		return e.JSON(http.StatusUnauthorized, map[string]string{"error": "Not authenticated"})
+	}
+
+	status, err := quota.GetStatus(e.App, auth.Id, time.Now())
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to load quota"})
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"quota":            status,
+		"tweaks_remaining": status.TweaksRemaining(),
+		"tokens_remaining": status.TokensRemaining(),
+	})
+}
+
+// HandleSetQuotaOverridePB sets a per-user quota override (superusers only).
+// Omitted limits fall back to the global defaults; 0 means unlimited.
+func HandleSetQuotaOverridePB(e *core.RequestEvent) error {
+	userID := e.Request.PathValue("userId")
+	if _, err := e.App.FindRecordById("users", userID); err != nil {
+		return e.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+
+	var data struct {
+		TweaksPerDay   *int64 `json:"tweaks_per_day"`
+		TokensPerMonth *int64 `json:"tokens_per_month"`
+		Note           string `json:"note"`
+	}
+	if err := e.BindBody(&data); err != nil {
+		return e.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	limits := quota.DefaultLimits()
+	if data.TweaksPerDay != nil {
+		limits.TweaksPerDay = *data.TweaksPerDay
+	}
+	if data.TokensPerMonth != nil {
+		limits.TokensPerMonth = *data.TokensPerMonth
+	}
+	if limits.TweaksPerDay < 0 || limits.TokensPerMonth < 0 {
+		return e.JSON(http.StatusBadRequest, map[string]string{"error": "Limits must be >= 0"})
+	}
+
+	if err := quota.SetOverride(e.App, userID, limits, data.Note); err != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save override"})
+	}
+
+	return e.JSON(http.StatusOK, limits)
+}
+
+// HandleClearQuotaOverridePB removes a per-user quota override (superusers only)
+func HandleClearQuotaOverridePB(e *core.RequestEvent) error {
+	if err := quota.ClearOverride(e.App, e.Request.PathValue("userId")); err != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to clear override"})
+	}
+	return e.NoContent(http.StatusNoContent)
+}
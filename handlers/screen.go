@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	baml "github.com/johnhkchen/resume-tweaker/baml_client/baml_client"
+	"github.com/johnhkchen/resume-tweaker/baml_client/baml_client/types"
+	"github.com/johnhkchen/resume-tweaker/screen"
+)
+
+// classifierTimeout bounds each optional prompt-injection classifier call
+const classifierTimeout = 10 * time.Second
+
+// screenedInput is one input after prompt-injection screening
+type screenedInput struct {
+	source   string
+	text     string
+	excerpts []string
+}
+
+// screenInputs checks the resume and job description for prompt injection
+// before they reach the model. It returns the text to send (neutralized
+// unless PROMPT_INJECTION_MODE=flag) and the warning to show the user.
+func screenInputs(ctx context.Context, resume, jobDesc string, opts ...baml.CallOptionFunc) (string, string, string) {
+	mode := screen.Mode()
+	inputs := []*screenedInput{
+		{source: "resume", text: resume},
+		{source: "job_description", text: jobDesc},
+	}
+
+	var findings []screen.Finding
+	for _, in := range inputs {
+		findings = append(findings, screen.Scan(in.source, in.text)...)
+
+		if !screen.ClassifierEnabled() {
+			continue
+		}
+		verdict, err := classifyInjection(ctx, in.text, opts...)
+		if err != nil {
+			log.Printf("[Screen] Classifier failed for %s, using heuristics only: %v", in.source, err)
+			continue
+		}
+		if verdict.Suspicious {
+			finding := screen.Finding{Source: in.source, Reason: "Flagged by classifier: " + verdict.Reason}
+			if len(verdict.Excerpts) > 0 {
+				finding.Excerpt = verdict.Excerpts[0]
+			}
+			findings = append(findings, finding)
+			in.excerpts = verdict.Excerpts
+		}
+	}
+
+	if mode == screen.ModeNeutralize {
+		for _, in := range inputs {
+			in.text = screen.Neutralize(in.text, in.excerpts...)
+		}
+	}
+
+	if len(findings) > 0 {
+		log.Printf("[Screen] %d prompt-injection finding(s), mode=%s", len(findings), mode)
+	}
+	return inputs[0].text, inputs[1].text, screen.Summary(findings, mode)
+}
+
+// classifyInjection runs the optional LLM classifier on one input
+func classifyInjection(ctx context.Context, text string, opts ...baml.CallOptionFunc) (types.InjectionVerdict, error) {
+	ctx, cancel := context.WithTimeout(ctx, classifierTimeout)
+	defer cancel()
+	return baml.ClassifyInjection(ctx, text, opts...)
+}
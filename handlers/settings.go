@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/johnhkchen/resume-tweaker/export"
+	"github.com/johnhkchen/resume-tweaker/settings"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// HandleGetSettingsPB returns the caller's preferences
+func HandleGetSettingsPB(e *core.RequestEvent) error {
+	auth := e.Auth
+	if auth == nil {
+		return e.JSON(http.StatusUnauthorized, map[string]string{"error": "Not authenticated"})
+	}
+	return e.JSON(http.StatusOK, settings.Get(e.App, auth.Id))
+}
+
+// HandleUpdateSettingsPB updates the caller's preferences. Omitted fields
+// keep their current values.
+func HandleUpdateSettingsPB(e *core.RequestEvent) error {
+	auth := e.Auth
+	if auth == nil {
+		return e.JSON(http.StatusUnauthorized, map[string]string{"error": "Not authenticated"})
+	}
+
+	var data struct {
+		RedactPII     *bool   `json:"redact_pii"`
+		LaTeXTemplate *string `json:"latex_template"`
+	}
+	if err := e.BindBody(&data); err != nil {
+		return e.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	prefs := settings.Get(e.App, auth.Id)
+	if data.RedactPII != nil {
+		prefs.RedactPII = *data.RedactPII
+	}
+	if data.LaTeXTemplate != nil {
+		if _, ok := export.LaTeXTemplateByName(*data.LaTeXTemplate); !ok {
+			return e.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown LaTeX template: " + *data.LaTeXTemplate})
+		}
+		prefs.LaTeXTemplate = *data.LaTeXTemplate
+	}
+
+	if err := settings.Save(e.App, auth.Id, prefs); err != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save settings"})
+	}
+	return e.JSON(http.StatusOK, prefs)
+}
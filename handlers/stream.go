@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/johnhkchen/resume-tweaker/jobs"
+	"github.com/johnhkchen/resume-tweaker/markdown"
+	"github.com/johnhkchen/resume-tweaker/sse"
+	"github.com/johnhkchen/resume-tweaker/templates"
+)
+
+// Result streaming cadence, from SSE_FLUSH_INTERVAL,
+// SSE_CHECKPOINT_INTERVAL and SSE_HEARTBEAT_INTERVAL
+var (
+	resultFlushInterval      = durationFromEnv("SSE_FLUSH_INTERVAL", 100*time.Millisecond)
+	resultCheckpointInterval = durationFromEnv("SSE_CHECKPOINT_INTERVAL", 10*time.Second)
+	heartbeatInterval        = durationFromEnv("SSE_HEARTBEAT_INTERVAL", 15*time.Second)
+)
+
+// Stream states, sent in the stream_status signal. A stream always ends by
+// sending streamComplete or streamError, so a client still showing
+// streamActive after the connection closes knows it was cut off.
+const (
+	streamActive   = "streaming"
+	streamComplete = "complete"
+	streamError    = "error"
+)
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		log.Printf("[SSE] Invalid %s %q, using %s", key, raw, fallback)
+		return fallback
+	}
+	return d
+}
+
+// resultStream sends a job's growing result to one client as deltas.
+// Complete markdown blocks are rendered once and appended; only the block
+// still being written is re-sent. Every event carries the job version as
+// its id, and a full checkpoint is sent periodically and at the end so a
+// client that missed an update converges.
+type resultStream struct {
+	ctx   context.Context
+	sw    *sse.Writer
+	jobID string
+
+	// resumed is set when the client already has the output up to the
+	// version it reconnected from
+	resumed bool
+	started bool
+	// sent is the content the client has; stable is how much of it is in
+	// complete blocks
+	sent           string
+	stable         int
+	lastCheckpoint time.Time
+}
+
+func newResultStream(ctx context.Context, sw *sse.Writer, jobID string, resumed bool) *resultStream {
+	return &resultStream{ctx: ctx, sw: sw, jobID: jobID, resumed: resumed, lastCheckpoint: time.Now()}
+}
+
+// Send brings the client up to date with u
+func (rs *resultStream) Send(u jobs.Update) {
+	if !rs.started {
+		rs.started = true
+		if !u.Done {
+			u.Signals["stream_status"] = streamActive
+		}
+		if !rs.resumed || u.Known < 0 {
+			rs.checkpoint(u)
+			return
+		}
+		rs.sent = u.Content[:u.Known]
+		rs.stable = markdown.StableLength(rs.sent)
+	}
+
+	if u.Done || time.Since(rs.lastCheckpoint) >= resultCheckpointInterval || !strings.HasPrefix(u.Content, rs.sent) {
+		rs.checkpoint(u)
+		return
+	}
+
+	id := sse.WithID(jobEventID(rs.jobID, u.Version))
+	if u.Content != rs.sent {
+		if n := markdown.StableLength(u.Content); n > rs.stable {
+			if html, ok := rs.render(templates.ResultChunk(u.Content[rs.stable:n])); ok {
+				rs.sw.MergeFragments(html, sse.WithSelector("#result-blocks"), sse.WithMergeMode(sse.MergeAppend), id)
+				rs.stable = n
+			}
+		}
+		if html, ok := rs.render(templates.ResultTail(u.Content[rs.stable:], u.Version)); ok {
+			rs.sw.MergeFragments(html, id)
+		}
+		rs.sent = u.Content
+	}
+
+	u.Signals["result_seq"] = u.Version
+	rs.sw.MergeSignals(u.Signals, id)
+}
+
+// checkpoint re-sends the whole result, including the raw markdown in the
+// result signal for copying and export
+func (rs *resultStream) checkpoint(u jobs.Update) {
+	id := sse.WithID(jobEventID(rs.jobID, u.Version))
+	if html, ok := rs.render(templates.ResultView(u.Content, u.Version)); ok {
+		rs.sw.MergeFragments(html, id)
+	}
+
+	if u.Done {
+		u.Signals["stream_status"] = streamComplete
+		if u.Status != jobs.StatusDone {
+			u.Signals["stream_status"] = streamError
+		}
+	}
+
+	u.Signals["result"] = u.Content
+	u.Signals["result_seq"] = u.Version
+	rs.sw.MergeSignals(u.Signals, id)
+
+	rs.sent = u.Content
+	rs.stable = markdown.StableLength(u.Content)
+	rs.lastCheckpoint = time.Now()
+}
+
+func (rs *resultStream) render(c templ.Component) (string, bool) {
+	var buf bytes.Buffer
+	if err := c.Render(rs.ctx, &buf); err != nil {
+		log.Printf("[Tweak] Warning: failed to render result: %v", err)
+		return "", false
+	}
+	return buf.String(), true
+}
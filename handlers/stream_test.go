@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/johnhkchen/resume-tweaker/jobs"
+	"github.com/johnhkchen/resume-tweaker/sse"
+	"github.com/johnhkchen/resume-tweaker/testapp"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+// streamRecorder is a flushable response writer that is safe to read while
+// a handler is still writing
+type streamRecorder struct {
+	mu     sync.Mutex
+	header http.Header
+	buf    bytes.Buffer
+}
+
+func newStreamRecorder() *streamRecorder {
+	return &streamRecorder{header: http.Header{}}
+}
+
+func (r *streamRecorder) Header() http.Header { return r.header }
+func (r *streamRecorder) WriteHeader(int)     {}
+func (r *streamRecorder) Flush()              {}
+
+func (r *streamRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.Write(p)
+}
+
+func (r *streamRecorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.String()
+}
+
+// sentEvent is one parsed SSE event
+type sentEvent struct {
+	typ, id, data string
+}
+
+func (e sentEvent) isCheckpoint() bool {
+	return strings.Contains(e.data, `"result":`) || strings.Contains(e.data, `id="result-view"`)
+}
+
+func (e sentEvent) isAppend() bool {
+	return strings.Contains(e.data, "selector #result-blocks") && strings.Contains(e.data, "mergeMode append")
+}
+
+func parseEvents(stream string) []sentEvent {
+	var events []sentEvent
+	for _, block := range strings.Split(stream, "\n\n") {
+		var e sentEvent
+		var data []string
+		for _, line := range strings.Split(block, "\n") {
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				e.typ = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "id: "):
+				e.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				data = append(data, strings.TrimPrefix(line, "data: "))
+			}
+		}
+		if e.typ != "" {
+			e.data = strings.Join(data, "\n")
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func newTestStream(t testing.TB, resumed bool) (*resultStream, *streamRecorder) {
+	t.Helper()
+	rec := newStreamRecorder()
+	sw, err := sse.NewWithProtocol(rec, sse.ProtocolBeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newResultStream(context.Background(), sw, "job1", resumed), rec
+}
+
+func update(version int, content string, known int) jobs.Update {
+	return jobs.Update{Version: version, Content: content, Known: known, Signals: sse.Signals{}, Status: jobs.StatusRunning}
+}
+
+func TestResultStreamDeltas(t *testing.T) {
+	rs, rec := newTestStream(t, false)
+
+	// A fresh client gets a checkpoint first
+	rs.Send(update(3, "# Jane", 0))
+	events := parseEvents(rec.String())
+	if len(events) != 2 || !events[0].isCheckpoint() || !events[1].isCheckpoint() {
+		t.Fatalf("first send = %+v, want a checkpoint", events)
+	}
+	if events[1].id != "job1:3" || !strings.Contains(events[1].data, `"stream_status":"streaming"`) {
+		t.Fatalf("checkpoint signals = %+v", events[1])
+	}
+
+	// A completed block is appended once and the tail re-sent
+	before := len(rec.String())
+	rs.Send(update(4, "# Jane\n\nBuilt billing", 0))
+	events = parseEvents(rec.String()[before:])
+	if len(events) != 3 {
+		t.Fatalf("delta = %+v, want append, tail and signals", events)
+	}
+	if !events[0].isAppend() || !strings.Contains(events[0].data, "<h1>Jane</h1>") {
+		t.Errorf("first delta event %+v, want the heading appended", events[0])
+	}
+	if !strings.Contains(events[1].data, `id="result-tail" data-seq="4"`) || !strings.Contains(events[1].data, "Built billing") {
+		t.Errorf("second delta event %+v, want the tail", events[1])
+	}
+	for _, e := range events {
+		if e.id != "job1:4" {
+			t.Errorf("delta event id %q, want job1:4", e.id)
+		}
+		if e.isCheckpoint() {
+			t.Errorf("delta re-sent the whole result: %+v", e)
+		}
+	}
+
+	// Text inside the same block only re-sends the tail
+	before = len(rec.String())
+	rs.Send(update(5, "# Jane\n\nBuilt billing for Acme", 0))
+	events = parseEvents(rec.String()[before:])
+	if len(events) != 2 || events[0].isAppend() || strings.Contains(events[0].data, "Jane") {
+		t.Fatalf("tail-only delta = %+v", events)
+	}
+
+	// A signal-only change sends no HTML
+	before = len(rec.String())
+	u := update(6, "# Jane\n\nBuilt billing for Acme", 0)
+	u.Signals["step"] = 3
+	rs.Send(u)
+	events = parseEvents(rec.String()[before:])
+	if len(events) != 1 || events[0].typ != sse.EventMergeSignals || !strings.Contains(events[0].data, `"result_seq":6`) {
+		t.Fatalf("signal-only update = %+v", events)
+	}
+}
+
+func TestResultStreamCheckpoints(t *testing.T) {
+	tests := []struct {
+		name   string
+		next   jobs.Update
+		status string
+	}{
+		{name: "content replaced", next: update(5, "# Different", 0)},
+		{name: "done", next: jobs.Update{Version: 5, Content: "# Jane\n\nDone", Signals: sse.Signals{}, Done: true, Status: jobs.StatusDone}, status: streamComplete},
+		{name: "failed", next: jobs.Update{Version: 5, Content: "# Jane", Signals: sse.Signals{}, Done: true, Status: jobs.StatusFailed}, status: streamError},
+	}
+	for _, tt := range tests {
+		rs, rec := newTestStream(t, false)
+		rs.Send(update(3, "# Jane\n\nBuilt", 0))
+		rs.Send(update(4, "# Jane\n\nBuilt billing", 0))
+
+		before := len(rec.String())
+		rs.Send(tt.next)
+		events := parseEvents(rec.String()[before:])
+		if len(events) != 2 || !events[0].isCheckpoint() || !events[1].isCheckpoint() {
+			t.Errorf("%s: sent %+v, want a checkpoint", tt.name, events)
+			continue
+		}
+		if tt.status != "" && !strings.Contains(events[1].data, `"stream_status":"`+tt.status+`"`) {
+			t.Errorf("%s: signals %s, want stream_status %s", tt.name, events[1].data, tt.status)
+		}
+	}
+}
+
+func TestResultStreamCheckpointInterval(t *testing.T) {
+	defer func(d time.Duration) { resultCheckpointInterval = d }(resultCheckpointInterval)
+	resultCheckpointInterval = time.Hour
+
+	rs, rec := newTestStream(t, false)
+	rs.Send(update(3, "# Jane", 0))
+	rs.Send(update(4, "# Jane\n\nBuilt", 0))
+	if events := parseEvents(rec.String()); events[len(events)-1].isCheckpoint() {
+		t.Fatal("checkpointed before the interval")
+	}
+
+	rs.lastCheckpoint = time.Now().Add(-2 * time.Hour)
+	before := len(rec.String())
+	rs.Send(update(5, "# Jane\n\nBuilt billing", 0))
+	if events := parseEvents(rec.String()[before:]); len(events) != 2 || !events[0].isCheckpoint() {
+		t.Fatalf("sent %+v after the interval, want a checkpoint", events)
+	}
+}
+
+func TestResultStreamResume(t *testing.T) {
+	content := "# Jane\n\nBuilt billing\n\nRan on-call"
+
+	// The client has everything up to "Built billing", so only the rest is sent
+	rs, rec := newTestStream(t, true)
+	rs.Send(update(7, content, len("# Jane\n\nBuilt billing")))
+	events := parseEvents(rec.String())
+	for _, e := range events {
+		if e.isCheckpoint() {
+			t.Fatalf("resume sent a checkpoint: %+v", e)
+		}
+	}
+	if len(events) != 3 || !events[0].isAppend() || strings.Contains(events[0].data, "Jane") || !strings.Contains(events[0].data, "Built billing") {
+		t.Fatalf("resume = %+v, want only the new blocks appended", events)
+	}
+	if !strings.Contains(events[1].data, "Ran on-call") || !strings.Contains(events[2].data, `"stream_status":"streaming"`) {
+		t.Fatalf("resume tail and signals = %+v", events[1:])
+	}
+
+	// Content replaced since the client's version: start over
+	rs, rec = newTestStream(t, true)
+	rs.Send(update(7, content, -1))
+	if events := parseEvents(rec.String()); len(events) != 2 || !events[0].isCheckpoint() {
+		t.Fatalf("resume after a reset = %+v, want a checkpoint", events)
+	}
+}
+
+func TestJobEventID(t *testing.T) {
+	if id := jobEventID("abc123", 42); id != "abc123:42" {
+		t.Fatalf("jobEventID = %q", id)
+	}
+	if id, version, ok := parseJobEventID("abc123:42"); !ok || id != "abc123" || version != 42 {
+		t.Fatalf("parseJobEventID = %q, %d, %v", id, version, ok)
+	}
+	for _, bad := range []string{"", "abc123", ":42", "abc123:", "abc123:x", "abc123:-1"} {
+		if _, _, ok := parseJobEventID(bad); ok {
+			t.Errorf("parseJobEventID(%q) ok", bad)
+		}
+	}
+}
+
+// runningJob starts a tweak job with some output for a new user
+func runningJob(t *testing.T) (core.App, *core.Record, *jobs.Job) {
+	t.Helper()
+	app := testapp.New(t, jobs.SetupCollections)
+	user := testapp.User(t, app)
+
+	job, _, err := jobs.Start(app, user.Id, "tweak", jobs.Key(user.Id, t.Name()), sse.Signals{"loading": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job.SetContent("# Jane")
+	job.SetContent("# Jane\n\nBuilt billing")
+	return app, user, job
+}
+
+// streamTweakJob runs HandleTweakJobStreamPB until the job finishes or ctx
+// is cancelled
+func streamTweakJob(ctx context.Context, app core.App, user *core.Record, target, lastEventID string) (*streamRecorder, chan error) {
+	rec := newStreamRecorder()
+	req := httptest.NewRequest(http.MethodGet, target, nil).WithContext(ctx)
+	req.SetPathValue("id", strings.Split(strings.TrimPrefix(target, "/app/tweak/jobs/"), "/")[0])
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	e := &core.RequestEvent{App: app, Auth: user, Event: router.Event{Response: rec, Request: req}}
+
+	done := make(chan error, 1)
+	go func() { done <- HandleTweakJobStreamPB(e) }()
+	return rec, done
+}
+
+func waitForEvents(t *testing.T, rec *streamRecorder, n int) []sentEvent {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if events := parseEvents(rec.String()); len(events) >= n {
+			return events
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d events:\n%s", n, rec.String())
+	return nil
+}
+
+func TestTweakJobStreamResumes(t *testing.T) {
+	defer func(d time.Duration) { resultFlushInterval = d }(resultFlushInterval)
+	resultFlushInterval = time.Millisecond
+
+	app, user, job := runningJob(t)
+	u, _ := job.Since(0)
+	latest := u.Version
+	target := "/app/tweak/jobs/" + job.ID + "/stream"
+
+	tests := []struct {
+		name        string
+		query       string
+		lastEventID string
+		checkpoint  bool
+	}{
+		{name: "fresh", checkpoint: true},
+		{name: "last event id", lastEventID: jobEventID(job.ID, latest)},
+		{name: "offset", query: fmt.Sprintf("?offset=%d", latest)},
+		{name: "last event id wins over offset", query: "?offset=1", lastEventID: jobEventID(job.ID, latest)},
+		{name: "another job's event id", lastEventID: jobEventID("other", latest), checkpoint: true},
+		{name: "version from the future", lastEventID: jobEventID(job.ID, latest+100), checkpoint: true},
+	}
+	for _, tt := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+		rec, done := streamTweakJob(ctx, app, user, target+tt.query, tt.lastEventID)
+		first := waitForEvents(t, rec, 1)[0]
+		cancel()
+		if err := <-done; err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if first.isCheckpoint() != tt.checkpoint {
+			t.Errorf("%s: first event %+v, checkpoint %v", tt.name, first, !tt.checkpoint)
+		}
+		if first.id != jobEventID(job.ID, latest) {
+			t.Errorf("%s: first event id %q, want %q", tt.name, first.id, jobEventID(job.ID, latest))
+		}
+	}
+}
+
+func TestTweakJobStreamFollowsJob(t *testing.T) {
+	defer func(d time.Duration) { resultFlushInterval = d }(resultFlushInterval)
+	resultFlushInterval = time.Millisecond
+
+	app, user, job := runningJob(t)
+	u, _ := job.Since(0)
+	rec, done := streamTweakJob(context.Background(), app, user, "/app/tweak/jobs/"+job.ID+"/stream", jobEventID(job.ID, u.Version))
+
+	// Resumed at the latest version: just the sequence number
+	events := waitForEvents(t, rec, 1)
+	if events[0].isCheckpoint() || !strings.Contains(events[0].data, `"result_seq"`) {
+		t.Fatalf("resume at latest = %+v", events[0])
+	}
+
+	job.SetContent("# Jane\n\nBuilt billing\n\nRan on-call")
+	events = waitForEvents(t, rec, 4)
+	if !events[1].isAppend() || strings.Contains(events[1].data, "Jane") || !strings.Contains(events[1].data, "Built billing") {
+		t.Fatalf("delta after resume = %+v", events[1])
+	}
+
+	job.Finish(sse.Signals{"step": 4})
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream didn't end when the job finished")
+	}
+	events = parseEvents(rec.String())
+	last := events[len(events)-1]
+	if !last.isCheckpoint() || !strings.Contains(last.data, `"stream_status":"complete"`) || !strings.Contains(last.data, "Ran on-call") {
+		t.Fatalf("final event %+v, want a complete checkpoint", last)
+	}
+}
+
+// benchmarkResult is a long markdown result, roughly the size of a tweaked
+// resume
+func benchmarkResult() string {
+	var b strings.Builder
+	b.WriteString("# Jane Doe\n\nStaff engineer building billing and payments systems.\n\n")
+	for role := 0; role < 12; role++ {
+		fmt.Fprintf(&b, "## Staff Engineer, Company %d (%d - %d)\n\n", role, 2024-2*role, 2026-2*role)
+		for bullet := 0; bullet < 6; bullet++ {
+			fmt.Fprintf(&b, "- Led **project %d.%d**, cutting p99 latency by %d%% across *%d services*\n", role, bullet, 10+bullet*7, 3+role)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// countingWriter discards a stream, counting its bytes
+type countingWriter struct {
+	header http.Header
+	n      int
+}
+
+func (w *countingWriter) Header() http.Header         { return w.header }
+func (w *countingWriter) WriteHeader(int)             {}
+func (w *countingWriter) Flush()                      {}
+func (w *countingWriter) Write(p []byte) (int, error) { w.n += len(p); return len(p), nil }
+
+// BenchmarkResultStream streams benchmarkResult to one client four bytes (about
+// one model token) at a time. "full" re-sends the whole result on every
+// token; "deltas" is the default, with updates coalesced five tokens at a
+// time (50 tokens/s over the 100ms flush interval).
+func BenchmarkResultStream(b *testing.B) {
+	result := benchmarkResult()
+	defer func(d time.Duration) { resultCheckpointInterval = d }(resultCheckpointInterval)
+
+	for _, bm := range []struct {
+		name       string
+		checkpoint time.Duration
+		tokens     int
+	}{
+		{name: "full", checkpoint: 0, tokens: 1},
+		{name: "deltas", checkpoint: time.Hour, tokens: 5},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			resultCheckpointInterval = bm.checkpoint
+			var bytes int
+			for i := 0; i < b.N; i++ {
+				w := &countingWriter{header: http.Header{}}
+				sw, err := sse.NewWithProtocol(w, sse.ProtocolBeta)
+				if err != nil {
+					b.Fatal(err)
+				}
+				rs := newResultStream(context.Background(), sw, "job1", false)
+				version := 2
+				for end := 0; end < len(result); end += 4 * bm.tokens {
+					version++
+					rs.Send(update(version, result[:end], 0))
+				}
+				rs.Send(jobs.Update{Version: version + 1, Content: result, Signals: sse.Signals{}, Done: true, Status: jobs.StatusDone})
+				bytes = w.n
+			}
+			b.ReportMetric(float64(len(result)), "result-bytes")
+			b.ReportMetric(float64(bytes), "sent-bytes/op")
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/johnhkchen/resume-tweaker/extract"
+	"github.com/johnhkchen/resume-tweaker/jsonresume"
+	"github.com/johnhkchen/resume-tweaker/linkedin"
+	"github.com/johnhkchen/resume-tweaker/profile"
+	"github.com/johnhkchen/resume-tweaker/sse"
+	"github.com/johnhkchen/resume-tweaker/templates"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+// HandleResumeUploadPB extracts the text of an uploaded resume file, keeps
+// the file on a new resumes record, and sends the text back as the resume
+// signal along with a preview to check it against
+func HandleResumeUploadPB(e *core.RequestEvent) error {
+	// Read the whole upload before streaming the response: once the
+	// response has started the request body may no longer be readable
+	name, data, res, uploadErr := readUpload(e)
+
+	sw, err := sse.New(e.Response, e.Request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, map[string]string{"error": "SSE not supported"})
+	}
+	if uploadErr != "" {
+		return sw.MergeSignals(sse.Signals{"uploading": false, "upload_error": uploadErr})
+	}
+
+	// Keep the original with the extracted text. The text is what matters
+	// to the user, so a failed save doesn't fail the upload.
+	uploadID := ""
+	if record, err := saveUpload(e.App, e.Auth.Id, name, data, res.Text); err != nil {
+		log.Printf("[Upload] Warning: failed to store %s for %s: %v", name, e.Auth.Id, err)
+	} else {
+		uploadID = record.Id
+	}
+
+	// Structured uploads replace the master profile, which can rebuild a
+	// baseline resume later
+	if res.Profile != nil {
+		source := "jsonresume"
+		if extract.Detect(data) == extract.MimeLinkedIn {
+			source = "linkedin"
+		}
+		if err := profile.Save(e.App, e.Auth.Id, profile.Profile{Resume: *res.Profile, Source: source}); err != nil {
+			log.Printf("[Upload] Warning: failed to save profile for %s: %v", e.Auth.Id, err)
+			res.Profile = nil
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := templates.UploadPreview(name, res).Render(e.Request.Context(), &buf); err != nil {
+		log.Printf("[Upload] Warning: failed to render preview: %v", err)
+	} else {
+		sw.MergeFragments(buf.String())
+	}
+
+	return sw.MergeSignals(sse.Signals{
+		"resume":          res.Text,
+		"resume_language": detectLanguage(e.Request.Context(), res.Text),
+		"uploading":       false,
+		"upload_error":    "",
+		"upload_name":     name,
+		"upload_id":       uploadID,
+	})
+}
+
+// readUpload reads the "file" form field and extracts its text. Failures
+// come back as a user-facing message.
+func readUpload(e *core.RequestEvent) (string, []byte, extract.Result, string) {
+	tooLarge := fmt.Sprintf("That file is too large (max %d MB).", extract.MaxSize>>20)
+
+	// Leave room for the multipart envelope around the file
+	e.Request.Body = http.MaxBytesReader(e.Response, e.Request.Body, extract.MaxSize+1<<20)
+	file, header, err := e.Request.FormFile("file")
+	if err != nil {
+		var maxBytes *http.MaxBytesError
+		if errors.As(err, &maxBytes) {
+			return "", nil, extract.Result{}, tooLarge
+		}
+		return "", nil, extract.Result{}, "Choose a file to upload."
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, extract.MaxSize+1))
+	if err != nil {
+		return "", nil, extract.Result{}, "The upload didn't complete. Please try again."
+	}
+	if len(data) > extract.MaxSize {
+		return "", nil, extract.Result{}, tooLarge
+	}
+
+	name := filepath.Base(header.Filename)
+	res, err := extract.File(extract.Detect(data), data)
+	if err != nil {
+		log.Printf("[Upload] Extraction failed for %s (%s): %v", e.Auth.Id, name, err)
+		return "", nil, extract.Result{}, uploadErrorMessage(err)
+	}
+	if len(res.Text) > MaxInputBytes {
+		return "", nil, extract.Result{}, fmt.Sprintf("That resume is too long (max %d KB of text).", MaxInputBytes/1024)
+	}
+	return name, data, res, ""
+}
+
+// saveUpload stores an uploaded file and its text as an upload resumes
+// record. A LinkedIn export is cut down to the profile CSVs first.
+func saveUpload(app core.App, userID, name string, data []byte, text string) (*core.Record, error) {
+	collection, err := app.FindCollectionByNameOrId("resumes")
+	if err != nil {
+		return nil, err
+	}
+	if extract.Detect(data) == extract.MimeLinkedIn {
+		if data, err = linkedin.Trim(data); err != nil {
+			return nil, err
+		}
+	}
+	file, err := filesystem.NewFileFromBytes(data, name)
+	if err != nil {
+		return nil, err
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("user", userID)
+	record.Set("kind", ResumeKindUpload)
+	record.Set("original_content", text)
+	record.Set("source_file", file)
+	if err := app.Save(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// uploadErrorMessage turns an extraction error into user-facing text
+func uploadErrorMessage(err error) string {
+	var invalid *jsonresume.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		return "That isn't a valid JSON Resume file: " + strings.Join(invalid.Problems, "; ") + "."
+	case errors.Is(err, extract.ErrUnsupported):
+		return "That file type isn't supported. Upload a PDF, Word (.docx) or JSON Resume file or a LinkedIn data export (.zip), or paste your resume instead."
+	case errors.Is(err, extract.ErrNoText):
+		return "We couldn't find any text in that file. Scanned PDFs aren't supported - paste your resume instead."
+	default:
+		return "We couldn't read that file. It may be damaged or password-protected."
+	}
+}
@@ -0,0 +1,146 @@
+package jobboard
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johnhkchen/resume-tweaker/jobpost"
+)
+
+// GreenhouseAPI is the Greenhouse job board API
+const GreenhouseAPI = "https://boards-api.greenhouse.io/v1/boards"
+
+// Greenhouse lists jobs from a Greenhouse job board, identified by its board
+// token (the "acme" in boards.greenhouse.io/acme)
+type Greenhouse struct {
+	// BaseURL overrides GreenhouseAPI
+	BaseURL string
+	Client  *http.Client
+}
+
+func (g *Greenhouse) Name() string  { return "greenhouse" }
+func (g *Greenhouse) Label() string { return "Greenhouse" }
+
+func (g *Greenhouse) Board(input string) (string, bool) {
+	board := strings.ToLower(boardFromURL(input,
+		"boards.greenhouse.io", "job-boards.greenhouse.io", "boards.eu.greenhouse.io", "job-boards.eu.greenhouse.io",
+		"boards-api.greenhouse.io/v1/boards"))
+	// Embedded boards name the token in a query parameter:
+	// boards.greenhouse.io/embed/job_board?for=acme
+	if board == "embed" {
+		if u, err := url.Parse(strings.TrimSpace(input)); err == nil {
+			board = strings.ToLower(u.Query().Get("for"))
+		}
+	}
+	return board, validBoard(board)
+}
+
+// greenhouseBoard is GET /boards/{token}
+type greenhouseBoard struct {
+	Name string `json:"name"`
+}
+
+// greenhouseJobs is GET /boards/{token}/jobs?content=true
+type greenhouseJobs struct {
+	Jobs []struct {
+		ID             int64  `json:"id"`
+		Title          string `json:"title"`
+		AbsoluteURL    string `json:"absolute_url"`
+		CompanyName    string `json:"company_name"`
+		FirstPublished string `json:"first_published"`
+		UpdatedAt      string `json:"updated_at"`
+		// Content is HTML, entity-encoded
+		Content  string `json:"content"`
+		Location struct {
+			Name string `json:"name"`
+		} `json:"location"`
+		Departments []struct {
+			Name string `json:"name"`
+		} `json:"departments"`
+		Offices []struct {
+			Name string `json:"name"`
+		} `json:"offices"`
+		Metadata []struct {
+			Name  string `json:"name"`
+			Value any    `json:"value"`
+		} `json:"metadata"`
+	} `json:"jobs"`
+}
+
+func (g *Greenhouse) List(ctx context.Context, board string) ([]Job, error) {
+	base := g.BaseURL
+	if base == "" {
+		base = GreenhouseAPI
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	var listing greenhouseJobs
+	if err := getJSON(ctx, g.Client, base+"/"+board+"/jobs?content=true", &listing); err != nil {
+		return nil, err
+	}
+
+	// Older boards leave company_name out of jobs; the board has it
+	company := ""
+	for _, j := range listing.Jobs {
+		if j.CompanyName == "" {
+			var info greenhouseBoard
+			if err := getJSON(ctx, g.Client, base+"/"+board, &info); err == nil {
+				company = info.Name
+			}
+			break
+		}
+	}
+
+	jobs := make([]Job, 0, len(listing.Jobs))
+	for _, j := range listing.Jobs {
+		job := Job{
+			Source:      g.Name(),
+			Board:       board,
+			ExternalID:  strconv.FormatInt(j.ID, 10),
+			Title:       strings.TrimSpace(j.Title),
+			Company:     strings.TrimSpace(j.CompanyName),
+			Location:    strings.TrimSpace(j.Location.Name),
+			URL:         j.AbsoluteURL,
+			Description: jobpost.PlainText(j.Content),
+			Published:   parseTime(j.FirstPublished, j.UpdatedAt),
+		}
+		if job.Company == "" {
+			job.Company = company
+		}
+		var departments []string
+		for _, d := range j.Departments {
+			departments = append(departments, d.Name)
+		}
+		job.Department = joinNonEmpty(", ", departments...)
+		if job.Location == "" {
+			var offices []string
+			for _, o := range j.Offices {
+				offices = append(offices, o.Name)
+			}
+			job.Location = joinNonEmpty("; ", offices...)
+		}
+		// Employment type is a custom field, when the company set one up
+		for _, m := range j.Metadata {
+			if value, ok := m.Value.(string); ok && strings.Contains(strings.ToLower(m.Name), "employment type") {
+				job.EmploymentType = value
+			}
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// parseTime reads the first of values that is an RFC 3339 timestamp
+func parseTime(values ...string) time.Time {
+	for _, v := range values {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,201 @@
+// Package jobboard imports open jobs from the public job board APIs of
+// applicant tracking systems, such as Greenhouse and Lever, and keeps the
+// ones a user saves in the saved_jobs collection.
+//
+// Each system is a Source, registered by name; adding one means
+// implementing Source and calling Register. Sources take their API base URL
+// and HTTP client as fields, so they can be pointed at a stand-in server.
+package jobboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johnhkchen/resume-tweaker/jobpost"
+)
+
+// maxResponseSize caps a board listing; boards with hundreds of jobs and
+// full descriptions run to a few megabytes
+const maxResponseSize = 20 << 20
+
+// requestTimeout bounds a board API call
+const requestTimeout = 20 * time.Second
+
+var (
+	// ErrBoardNotFound is returned when the board token or company slug
+	// doesn't exist
+	ErrBoardNotFound = errors.New("job board not found")
+	// ErrUnknownSource is returned for a source name nobody registered
+	ErrUnknownSource = errors.New("unknown job board source")
+	// ErrNotFound is returned for a saved job that isn't the user's
+	ErrNotFound = errors.New("saved job not found")
+)
+
+// Job is an open job listed by a board
+type Job struct {
+	// Source and Board identify where the job came from, e.g. "greenhouse"
+	// and the board token
+	Source string
+	Board  string
+	// ExternalID is the job's ID on the board
+	ExternalID     string
+	Title          string
+	Company        string
+	Location       string
+	Department     string
+	Team           string
+	EmploymentType string
+	Salary         string
+	// URL is the public posting
+	URL string
+	// Description is plain text with markdown headings and list items
+	Description string
+	// Published is when the job was first posted, if the board says
+	Published time.Time
+}
+
+// Text formats the job for the job description field
+func (j Job) Text() string {
+	return jobpost.Posting{
+		Title:          j.Title,
+		Company:        j.Company,
+		Location:       j.Location,
+		EmploymentType: j.EmploymentType,
+		Salary:         j.Salary,
+		Description:    j.Description,
+	}.Text()
+}
+
+// Source lists the open jobs on one kind of job board
+type Source interface {
+	// Name is the identifier stored with saved jobs
+	Name() string
+	// Label is the name shown to users
+	Label() string
+	// Board turns what a user typed - a board token, company slug or the
+	// URL of the board page - into the board to list, reporting false when
+	// it can't be one
+	Board(input string) (string, bool)
+	// List returns the board's open jobs
+	List(ctx context.Context, board string) ([]Job, error)
+}
+
+var sources = map[string]Source{}
+
+// Register makes a source available by name, replacing any with the same
+// name
+func Register(s Source) {
+	sources[s.Name()] = s
+}
+
+// Lookup returns the source registered under name
+func Lookup(name string) (Source, error) {
+	s, ok := sources[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSource, name)
+	}
+	return s, nil
+}
+
+// Sources lists the registered sources by label
+func Sources() []Source {
+	list := make([]Source, 0, len(sources))
+	for _, s := range sources {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Label() < list[j].Label() })
+	return list
+}
+
+func init() {
+	Register(&Greenhouse{})
+	Register(&Lever{})
+}
+
+// defaultClient is used by sources without a client of their own
+var defaultClient = &http.Client{Timeout: requestTimeout}
+
+// getJSON fetches a board API URL into v. A 404 means the board doesn't
+// exist.
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	if client == nil {
+		client = defaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrBoardNotFound
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("job board API returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxResponseSize {
+		return fmt.Errorf("job board API response over %d MB", maxResponseSize>>20)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// boardFromURL picks the board out of a board page URL on one of hosts: the
+// first path segment. Anything without a scheme or host is taken as the
+// board itself.
+func boardFromURL(input string, hosts ...string) string {
+	input = strings.TrimSpace(input)
+	rest := input
+	for _, scheme := range []string{"https://", "http://"} {
+		rest = strings.TrimPrefix(rest, scheme)
+	}
+	for _, host := range hosts {
+		if path, ok := strings.CutPrefix(rest, host+"/"); ok {
+			board, _, _ := strings.Cut(path, "/")
+			board, _, _ = strings.Cut(board, "?")
+			board, _, _ = strings.Cut(board, "#")
+			return board
+		}
+	}
+	return input
+}
+
+// validBoard reports whether s can be a board token or slug: letters,
+// digits, dots, dashes and underscores, so it's safe in a URL path
+func validBoard(s string) bool {
+	if s == "" || len(s) > 100 {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return s != "." && s != ".."
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var out []string
+	for _, s := range parts {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return strings.Join(out, sep)
+}
@@ -0,0 +1,383 @@
+package jobboard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/johnhkchen/resume-tweaker/testapp"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// boardServer serves board API responses by request URI
+type boardServer struct {
+	mu        sync.Mutex
+	responses map[string]string
+}
+
+func newBoardServer(t *testing.T, fixtures map[string]string) (*boardServer, *httptest.Server) {
+	t.Helper()
+	b := &boardServer{responses: map[string]string{}}
+	for uri, file := range fixtures {
+		data, err := os.ReadFile("testdata/" + file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.responses[uri] = string(data)
+	}
+	srv := httptest.NewServer(b)
+	t.Cleanup(srv.Close)
+	return b, srv
+}
+
+func (b *boardServer) set(uri, body string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.responses[uri] = body
+}
+
+func (b *boardServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	body, ok := b.responses[r.URL.RequestURI()]
+	b.mu.Unlock()
+	switch {
+	case !ok:
+		http.NotFound(w, r)
+	case body == "500":
+		http.Error(w, "unavailable", http.StatusInternalServerError)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}
+}
+
+func TestGreenhouseList(t *testing.T) {
+	_, srv := newBoardServer(t, map[string]string{
+		"/acme/jobs?content=true": "greenhouse_jobs.json",
+		"/acme":                   "greenhouse_board.json",
+	})
+	g := &Greenhouse{BaseURL: srv.URL, Client: srv.Client()}
+
+	jobs, err := g.List(context.Background(), "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Job{
+		{
+			Source:         "greenhouse",
+			Board:          "acme",
+			ExternalID:     "4012345",
+			Title:          "Senior Backend Engineer",
+			Company:        "Acme",
+			Location:       "Berlin, Germany",
+			Department:     "Engineering, Payments",
+			EmploymentType: "Full-time",
+			URL:            "https://boards.greenhouse.io/acme/jobs/4012345",
+			Description:    "Build the billing platform.\n\n### Requirements\n\n- Go\n- PostgreSQL",
+			Published:      time.Date(2026, 9, 1, 16, 0, 0, 0, time.UTC),
+		},
+		{
+			Source:     "greenhouse",
+			Board:      "acme",
+			ExternalID: "4012346",
+			Title:      "Support Lead",
+			// Missing from the job, so taken from the board
+			Company: "Acme Corp",
+			// No location, so the offices
+			Location:    "London; Dublin",
+			URL:         "https://boards.greenhouse.io/acme/jobs/4012346",
+			Description: "Lead our support team.",
+			Published:   time.Date(2026, 9, 12, 10, 0, 0, 0, time.UTC),
+		},
+	}
+	assertJobs(t, jobs, want)
+}
+
+func TestLeverList(t *testing.T) {
+	_, srv := newBoardServer(t, map[string]string{"/initech?mode=json": "lever_postings.json"})
+	l := &Lever{BaseURL: srv.URL, Client: srv.Client()}
+
+	jobs, err := l.List(context.Background(), "initech")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Job{
+		{
+			Source:         "lever",
+			Board:          "initech",
+			ExternalID:     "5ac2e8a1-43c4-4a1b-9c6e-1f0e2a7b9d10",
+			Title:          "Site Reliability Engineer",
+			Location:       "Austin, TX; Denver, CO - Hybrid",
+			Department:     "Engineering",
+			Team:           "Infrastructure",
+			EmploymentType: "Full-time",
+			Salary:         "USD 140000–175000.5 per year",
+			URL:            "https://jobs.lever.co/initech/5ac2e8a1-43c4-4a1b-9c6e-1f0e2a7b9d10",
+			Description:    "Keep our systems running.\n\n### What you'll do\n\n- Run Kubernetes\n- Own on-call\n\nWe offer a four day week.",
+			Published:      time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			Source:         "lever",
+			Board:          "initech",
+			ExternalID:     "7d9f0c22-0b8e-4f43-8a55-2c3d4e5f6a7b",
+			Title:          "Recruiter",
+			Location:       "Remote - Remote",
+			EmploymentType: "Contract",
+			URL:            "https://jobs.lever.co/initech/7d9f0c22-0b8e-4f43-8a55-2c3d4e5f6a7b",
+			Description:    "Hire great people.",
+		},
+	}
+	assertJobs(t, jobs, want)
+}
+
+func assertJobs(t *testing.T, got, want []Job) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d jobs, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("job %d =\n%+v\nwant\n%+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestListErrors(t *testing.T) {
+	b, srv := newBoardServer(t, nil)
+	b.set("/broken/jobs?content=true", "500")
+	b.set("/garbled?mode=json", "{not json")
+
+	g := &Greenhouse{BaseURL: srv.URL, Client: srv.Client()}
+	if _, err := g.List(context.Background(), "missing"); !errors.Is(err, ErrBoardNotFound) {
+		t.Errorf("missing board: %v, want ErrBoardNotFound", err)
+	}
+	if _, err := g.List(context.Background(), "broken"); err == nil || errors.Is(err, ErrBoardNotFound) {
+		t.Errorf("failing board: %v, want a status error", err)
+	}
+	l := &Lever{BaseURL: srv.URL, Client: srv.Client()}
+	if _, err := l.List(context.Background(), "garbled"); err == nil {
+		t.Error("garbled response: no error")
+	}
+}
+
+func TestBoard(t *testing.T) {
+	tests := []struct {
+		source Source
+		input  string
+		want   string
+		ok     bool
+	}{
+		{&Greenhouse{}, "acme", "acme", true},
+		{&Greenhouse{}, "https://boards.greenhouse.io/Acme/jobs/123?gh_src=x", "acme", true},
+		{&Greenhouse{}, "https://boards.greenhouse.io/embed/job_board?for=acme", "acme", true},
+		{&Greenhouse{}, "job-boards.eu.greenhouse.io/acme", "acme", true},
+		{&Greenhouse{}, "../admin", "../admin", false},
+		{&Lever{}, "https://jobs.lever.co/initech/5ac2e8a1", "initech", true},
+		{&Lever{}, "jobs.eu.lever.co/initech#top", "initech", true},
+		{&Lever{}, "init tech", "init tech", false},
+		{&Lever{}, "", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.source.Board(tt.input)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s Board(%q) = %q, %v; want %q, %v", tt.source.Name(), tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// useSources points the registered sources at srv for the test
+func useSources(t *testing.T, srv *httptest.Server) {
+	Register(&Greenhouse{BaseURL: srv.URL, Client: srv.Client()})
+	Register(&Lever{BaseURL: srv.URL, Client: srv.Client()})
+	t.Cleanup(func() {
+		Register(&Greenhouse{})
+		Register(&Lever{})
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	app := testapp.New(t, SetupCollections)
+	userID := testapp.User(t, app).Id
+	b, srv := newBoardServer(t, map[string]string{
+		"/acme/jobs?content=true": "greenhouse_jobs.json",
+		"/acme":                   "greenhouse_board.json",
+		"/initech?mode=json":      "lever_postings.json",
+	})
+	useSources(t, srv)
+
+	// Save every job on both boards
+	for _, s := range []struct{ source, board string }{{"greenhouse", "acme"}, {"lever", "initech"}} {
+		source, _ := Lookup(s.source)
+		jobs, err := source.List(context.Background(), s.board)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, job := range jobs {
+			if _, err := Save(app, userID, job); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Greenhouse drops a job and retitles the other; Lever is down
+	b.set("/acme/jobs?content=true", `{"jobs": [{"id": 4012345, "title": "Staff Backend Engineer", "company_name": "Acme", "content": "&lt;p&gt;Lead the billing platform.&lt;/p&gt;"}]}`)
+	b.set("/initech?mode=json", "500")
+
+	closed, err := Refresh(context.Background(), app)
+	if err != nil || closed != 1 {
+		t.Fatalf("Refresh = %d, %v; want 1 closed", closed, err)
+	}
+
+	saved, err := ListSaved(app, userID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := map[string]Saved{}
+	for _, s := range saved {
+		byID[s.ExternalID] = s
+	}
+	if len(byID) != 4 {
+		t.Fatalf("%d saved jobs after refresh, want 4", len(byID))
+	}
+	if s := byID["4012345"]; s.Status != StatusOpen || s.Title != "Staff Backend Engineer" || s.Description != "Lead the billing platform." {
+		t.Errorf("listed job not updated: %+v", s)
+	}
+	if s := byID["4012346"]; s.Status != StatusClosed || s.ClosedAt.IsZero() {
+		t.Errorf("removed job not closed: %+v", s)
+	}
+	for _, id := range []string{"5ac2e8a1-43c4-4a1b-9c6e-1f0e2a7b9d10", "7d9f0c22-0b8e-4f43-8a55-2c3d4e5f6a7b"} {
+		if s := byID[id]; s.Status != StatusOpen {
+			t.Errorf("job on a failing board was closed: %+v", s)
+		}
+	}
+	// Open jobs are listed first
+	if saved[len(saved)-1].Status != StatusClosed {
+		t.Errorf("closed job not listed last: %+v", saved)
+	}
+
+	// A closed job saved again reopens
+	source, _ := Lookup("greenhouse")
+	b.set("/acme/jobs?content=true", mustRead(t, "greenhouse_jobs.json"))
+	jobs, _ := source.List(context.Background(), "acme")
+	record, err := Save(app, userID, jobs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Id != byID["4012346"].ID || record.GetString("status") != StatusOpen || !record.GetDateTime("closed_at").IsZero() {
+		t.Errorf("saving a closed job again = %v, want the same record reopened", record)
+	}
+}
+
+func mustRead(t *testing.T, file string) string {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSaveCutsLongDescriptionsOnRunes(t *testing.T) {
+	app := testapp.New(t, SetupCollections)
+	userID := testapp.User(t, app).Id
+
+	// One ASCII byte first, so a cut by bytes lands inside an "é"
+	long := "x" + strings.Repeat("é", maxDescriptionChars)
+	record, err := Save(app, userID, Job{Source: "greenhouse", Board: "acme", ExternalID: "1", Title: "Engineer", Description: long})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := GetSaved(app, userID, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := saved.Description
+	if !utf8.ValidString(got) {
+		t.Fatal("stored description isn't valid UTF-8")
+	}
+	if n := utf8.RuneCountInString(got); n != maxDescriptionChars {
+		t.Fatalf("stored description has %d characters, want %d", n, maxDescriptionChars)
+	}
+	if !strings.HasPrefix(long, got) {
+		t.Fatal("stored description isn't the start of the original")
+	}
+}
+
+func TestSetupRenamesLegacyCollection(t *testing.T) {
+	app := testapp.New(t)
+	userID := testapp.User(t, app).Id
+
+	// A database from before the rename
+	users, _ := app.FindCollectionByNameOrId("users")
+	legacy := core.NewBaseCollection(legacyCollection)
+	legacy.Fields.Add(&core.RelationField{Name: "user", CollectionId: users.Id, MaxSelect: 1})
+	for _, name := range []string{"source", "board", "external_id", "title"} {
+		legacy.Fields.Add(&core.TextField{Name: name})
+	}
+	legacy.Fields.Add(&core.SelectField{Name: "status", MaxSelect: 1, Values: []string{StatusOpen, StatusClosed}})
+	legacy.AddIndex("idx_jobs_user_job", true, "user, source, board, external_id", "")
+	legacy.AddIndex("idx_jobs_status", false, "status", "")
+	if err := app.Save(legacy); err != nil {
+		t.Fatal(err)
+	}
+	record := core.NewRecord(legacy)
+	record.Set("user", userID)
+	record.Set("source", "lever")
+	record.Set("board", "initech")
+	record.Set("external_id", "1")
+	record.Set("title", "Recruiter")
+	record.Set("status", StatusOpen)
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetupCollections(app); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.FindCollectionByNameOrId(legacyCollection); err == nil {
+		t.Fatal("legacy collection still exists")
+	}
+	collection, err := app.FindCollectionByNameOrId(Collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indexes := strings.Join(collection.Indexes, "\n"); !strings.Contains(indexes, "idx_saved_jobs_user_job") || strings.Contains(indexes, "idx_jobs_") {
+		t.Errorf("indexes not renamed: %s", indexes)
+	}
+	moved, err := app.FindFirstRecordByFilter(Collection, "external_id = {:id}", dbx.Params{"id": "1"})
+	if err != nil || moved.Id != record.Id || moved.GetString("title") != "Recruiter" {
+		t.Fatalf("saved job not kept: %v, %v", moved, err)
+	}
+
+	// Running setup again is a no-op
+	if err := SetupCollections(app); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetupLeavesOtherJobsCollection(t *testing.T) {
+	app := testapp.New(t)
+	other := core.NewBaseCollection(legacyCollection)
+	other.Fields.Add(&core.TextField{Name: "name"})
+	if err := app.Save(other); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetupCollections(app); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.FindCollectionByNameOrId(legacyCollection); err != nil {
+		t.Fatal("a jobs collection that doesn't hold saved jobs was renamed")
+	}
+	if _, err := app.FindCollectionByNameOrId(Collection); err != nil {
+		t.Fatal(err)
+	}
+}
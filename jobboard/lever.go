@@ -0,0 +1,128 @@
+package jobboard
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johnhkchen/resume-tweaker/jobpost"
+)
+
+// LeverAPI is the Lever postings API
+const LeverAPI = "https://api.lever.co/v0/postings"
+
+// Lever lists jobs from a Lever job site, identified by the company's slug
+// (the "acme" in jobs.lever.co/acme)
+type Lever struct {
+	// BaseURL overrides LeverAPI, e.g. with https://api.eu.lever.co/v0/postings
+	// for companies hosted in the EU
+	BaseURL string
+	Client  *http.Client
+}
+
+func (l *Lever) Name() string  { return "lever" }
+func (l *Lever) Label() string { return "Lever" }
+
+func (l *Lever) Board(input string) (string, bool) {
+	board := strings.ToLower(boardFromURL(input, "jobs.lever.co", "jobs.eu.lever.co", "api.lever.co/v0/postings"))
+	return board, validBoard(board)
+}
+
+// leverPosting is one entry of GET /postings/{company}?mode=json
+type leverPosting struct {
+	ID         string `json:"id"`
+	Text       string `json:"text"`
+	HostedURL  string `json:"hostedUrl"`
+	CreatedAt  int64  `json:"createdAt"`
+	Categories struct {
+		Commitment   string   `json:"commitment"`
+		Department   string   `json:"department"`
+		Location     string   `json:"location"`
+		Team         string   `json:"team"`
+		AllLocations []string `json:"allLocations"`
+	} `json:"categories"`
+	WorkplaceType string `json:"workplaceType"`
+	// Description, Lists[].Content and Additional are HTML
+	Description string `json:"description"`
+	Lists       []struct {
+		Text    string `json:"text"`
+		Content string `json:"content"`
+	} `json:"lists"`
+	Additional  string `json:"additional"`
+	SalaryRange *struct {
+		Currency string  `json:"currency"`
+		Interval string  `json:"interval"`
+		Min      float64 `json:"min"`
+		Max      float64 `json:"max"`
+	} `json:"salaryRange"`
+}
+
+func (l *Lever) List(ctx context.Context, board string) ([]Job, error) {
+	base := l.BaseURL
+	if base == "" {
+		base = LeverAPI
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	var postings []leverPosting
+	if err := getJSON(ctx, l.Client, base+"/"+board+"?mode=json", &postings); err != nil {
+		return nil, err
+	}
+
+	jobs := make([]Job, 0, len(postings))
+	for _, p := range postings {
+		job := Job{
+			Source:         l.Name(),
+			Board:          board,
+			ExternalID:     p.ID,
+			Title:          strings.TrimSpace(p.Text),
+			Location:       strings.TrimSpace(p.Categories.Location),
+			Department:     strings.TrimSpace(p.Categories.Department),
+			Team:           strings.TrimSpace(p.Categories.Team),
+			EmploymentType: strings.TrimSpace(p.Categories.Commitment),
+			URL:            p.HostedURL,
+		}
+		if len(p.Categories.AllLocations) > 1 {
+			job.Location = joinNonEmpty("; ", p.Categories.AllLocations...)
+		}
+		if p.WorkplaceType == "remote" || p.WorkplaceType == "hybrid" {
+			job.Location = joinNonEmpty(" - ", job.Location, strings.ToUpper(p.WorkplaceType[:1])+p.WorkplaceType[1:])
+		}
+		if p.CreatedAt > 0 {
+			job.Published = time.UnixMilli(p.CreatedAt).UTC()
+		}
+		if s := p.SalaryRange; s != nil && s.Max > 0 {
+			job.Salary = joinNonEmpty(" ", s.Currency, formatAmount(s.Min)+"–"+formatAmount(s.Max), leverInterval(s.Interval))
+		}
+
+		// The posting page shows the description, then each list under
+		// its heading, then the closing text
+		var b strings.Builder
+		b.WriteString(p.Description)
+		for _, list := range p.Lists {
+			b.WriteString("<h3>" + html.EscapeString(list.Text) + "</h3><ul>" + list.Content + "</ul>")
+		}
+		b.WriteString(p.Additional)
+		job.Description = jobpost.PlainText(b.String())
+
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func formatAmount(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// leverInterval turns intervals like "per-year-salary" into "per year"
+func leverInterval(interval string) string {
+	parts := strings.Split(interval, "-")
+	if len(parts) >= 2 && parts[0] == "per" {
+		return "per " + parts[1]
+	}
+	return ""
+}
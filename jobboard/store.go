@@ -0,0 +1,281 @@
+package jobboard
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Collection is the PocketBase collection holding saved jobs
+const Collection = "saved_jobs"
+
+// legacyCollection is what Collection was called before it was renamed to
+// tell it apart from tweak jobs
+const legacyCollection = "jobs"
+
+// Saved job statuses
+const (
+	StatusOpen   = "open"
+	StatusClosed = "closed"
+)
+
+// maxDescriptionChars bounds a stored description
+const maxDescriptionChars = 200000
+
+// refreshTimeout bounds a whole refresh run
+const refreshTimeout = 10 * time.Minute
+
+// Saved is a job a user saved
+type Saved struct {
+	Job
+	ID     string
+	Status string
+	// ClosedAt is when a refresh found the job gone from its board
+	ClosedAt time.Time
+}
+
+// SetupCollections creates the saved_jobs collection if it doesn't exist,
+// renaming it from jobs in databases created before the rename
+func SetupCollections(app core.App) error {
+	if _, err := app.FindCollectionByNameOrId(Collection); err == nil {
+		return nil
+	}
+
+	if legacy, err := app.FindCollectionByNameOrId(legacyCollection); err == nil && legacy.Fields.GetByName("external_id") != nil {
+		log.Printf("[Setup] Renaming %s collection to %s...", legacyCollection, Collection)
+		legacy.Name = Collection
+		legacy.RemoveIndex("idx_jobs_user_job")
+		legacy.RemoveIndex("idx_jobs_status")
+		addIndexes(legacy)
+		return app.Save(legacy)
+	}
+
+	log.Printf("[Setup] Creating %s collection...", Collection)
+
+	usersCollection, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		return err
+	}
+
+	collection := core.NewBaseCollection(Collection)
+	collection.Fields.Add(&core.RelationField{
+		Name:          "user",
+		Required:      true,
+		CollectionId:  usersCollection.Id,
+		MaxSelect:     1,
+		CascadeDelete: true,
+	})
+	for _, name := range []string{"source", "board", "external_id"} {
+		collection.Fields.Add(&core.TextField{Name: name, Required: true})
+	}
+	for _, name := range []string{"title", "company", "location", "department", "team", "employment_type", "salary", "url"} {
+		collection.Fields.Add(&core.TextField{Name: name})
+	}
+	collection.Fields.Add(&core.TextField{
+		Name: "description",
+		Max:  maxDescriptionChars,
+	})
+	collection.Fields.Add(&core.SelectField{
+		Name:      "status",
+		Required:  true,
+		MaxSelect: 1,
+		Values:    []string{StatusOpen, StatusClosed},
+	})
+	collection.Fields.Add(&core.DateField{Name: "published"})
+	collection.Fields.Add(&core.DateField{Name: "closed_at"})
+	collection.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		OnCreate: true,
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	addIndexes(collection)
+
+	// Users can read and remove their own jobs; only the server writes them
+	collection.ListRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+	collection.ViewRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+	collection.DeleteRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+
+	return app.Save(collection)
+}
+
+func addIndexes(collection *core.Collection) {
+	collection.AddIndex("idx_saved_jobs_user_job", true, "user, source, board, external_id", "")
+	collection.AddIndex("idx_saved_jobs_status", false, "status", "")
+}
+
+// Save stores a job for a user, updating it if they saved it before. A
+// job saved again is open again.
+func Save(app core.App, userID string, job Job) (*core.Record, error) {
+	record, err := app.FindFirstRecordByFilter(Collection,
+		"user = {:user} && source = {:source} && board = {:board} && external_id = {:id}",
+		dbx.Params{"user": userID, "source": job.Source, "board": job.Board, "id": job.ExternalID},
+	)
+	if err != nil {
+		collection, err := app.FindCollectionByNameOrId(Collection)
+		if err != nil {
+			return nil, err
+		}
+		record = core.NewRecord(collection)
+		record.Set("user", userID)
+		record.Set("source", job.Source)
+		record.Set("board", job.Board)
+		record.Set("external_id", job.ExternalID)
+	}
+	setJob(record, job)
+	record.Set("status", StatusOpen)
+	record.Set("closed_at", "")
+	if err := app.Save(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// setJob copies the fields a board can change onto a record
+func setJob(record *core.Record, job Job) {
+	description := job.Description
+	if utf8.RuneCountInString(description) > maxDescriptionChars {
+		description = string([]rune(description)[:maxDescriptionChars])
+	}
+	record.Set("title", job.Title)
+	record.Set("company", job.Company)
+	record.Set("location", job.Location)
+	record.Set("department", job.Department)
+	record.Set("team", job.Team)
+	record.Set("employment_type", job.EmploymentType)
+	record.Set("salary", job.Salary)
+	record.Set("url", job.URL)
+	record.Set("description", description)
+	if !job.Published.IsZero() {
+		record.Set("published", job.Published)
+	}
+}
+
+// ListSaved returns a user's saved jobs, open ones first, newest first
+func ListSaved(app core.App, userID string, limit int) ([]Saved, error) {
+	records, err := app.FindRecordsByFilter(Collection, "user = {:user}", "-status,-created", limit, 0,
+		dbx.Params{"user": userID})
+	if err != nil {
+		return nil, err
+	}
+	saved := make([]Saved, 0, len(records))
+	for _, r := range records {
+		saved = append(saved, fromRecord(r))
+	}
+	return saved, nil
+}
+
+// GetSaved returns one of a user's saved jobs
+func GetSaved(app core.App, userID, id string) (Saved, error) {
+	record, err := app.FindRecordById(Collection, id)
+	if err != nil {
+		return Saved{}, err
+	}
+	if record.GetString("user") != userID {
+		return Saved{}, ErrNotFound
+	}
+	return fromRecord(record), nil
+}
+
+func fromRecord(r *core.Record) Saved {
+	return Saved{
+		ID:       r.Id,
+		Status:   r.GetString("status"),
+		ClosedAt: r.GetDateTime("closed_at").Time(),
+		Job: Job{
+			Source:         r.GetString("source"),
+			Board:          r.GetString("board"),
+			ExternalID:     r.GetString("external_id"),
+			Title:          r.GetString("title"),
+			Company:        r.GetString("company"),
+			Location:       r.GetString("location"),
+			Department:     r.GetString("department"),
+			Team:           r.GetString("team"),
+			EmploymentType: r.GetString("employment_type"),
+			Salary:         r.GetString("salary"),
+			URL:            r.GetString("url"),
+			Description:    r.GetString("description"),
+			Published:      r.GetDateTime("published").Time(),
+		},
+	}
+}
+
+// Refresh lists every board with open saved jobs once, updates the jobs
+// still listed and marks the rest closed. A board that can't be listed is
+// skipped rather than closing its jobs, since the failure may be ours.
+func Refresh(ctx context.Context, app core.App) (closed int, err error) {
+	records, err := app.FindAllRecords(Collection, dbx.HashExp{"status": StatusOpen})
+	if err != nil {
+		return 0, err
+	}
+
+	type boardKey struct{ source, board string }
+	boards := map[boardKey][]*core.Record{}
+	for _, r := range records {
+		key := boardKey{r.GetString("source"), r.GetString("board")}
+		boards[key] = append(boards[key], r)
+	}
+
+	now := types.NowDateTime()
+	for key, saved := range boards {
+		source, err := Lookup(key.source)
+		if err != nil {
+			log.Printf("[JobBoard] Skipping %s/%s: %v", key.source, key.board, err)
+			continue
+		}
+		listed, err := source.List(ctx, key.board)
+		if err != nil {
+			log.Printf("[JobBoard] Failed to list %s/%s: %v", key.source, key.board, err)
+			continue
+		}
+		open := make(map[string]Job, len(listed))
+		for _, job := range listed {
+			open[job.ExternalID] = job
+		}
+
+		for _, r := range saved {
+			if job, ok := open[r.GetString("external_id")]; ok {
+				setJob(r, job)
+			} else {
+				r.Set("status", StatusClosed)
+				r.Set("closed_at", now)
+				closed++
+			}
+			if err := app.Save(r); err != nil {
+				log.Printf("[JobBoard] Failed to update job %s: %v", r.Id, err)
+			}
+		}
+	}
+	return closed, nil
+}
+
+// ScheduleRefresh runs Refresh on the cron schedule in JOB_BOARD_REFRESH,
+// e.g. "0 */6 * * *". Refreshing is off when it's unset.
+func ScheduleRefresh(app core.App) {
+	schedule := os.Getenv("JOB_BOARD_REFRESH")
+	if schedule == "" {
+		return
+	}
+	err := app.Cron().Add("jobBoardRefresh", schedule, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+		closed, err := Refresh(ctx, app)
+		if err != nil {
+			log.Printf("[JobBoard] Refresh failed: %v", err)
+			return
+		}
+		log.Printf("[JobBoard] Refresh done, %d jobs closed", closed)
+	})
+	if err != nil {
+		log.Printf("[JobBoard] Invalid JOB_BOARD_REFRESH %q, refresh disabled: %v", schedule, err)
+	}
+}
@@ -0,0 +1,447 @@
+package jobpost
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// minContentLength is the shortest text accepted as a job description;
+// anything shorter is most likely a login wall or an error page
+const minContentLength = 200
+
+// Posting is a job posting read from a page
+type Posting struct {
+	Title          string
+	Company        string
+	Location       string
+	EmploymentType string
+	Salary         string
+	// Description is the body as plain text with markdown headings and
+	// list items
+	Description string
+	// URL is the page the posting was read from, after redirects
+	URL string
+	// Structured is set when the posting came from JobPosting JSON-LD
+	// rather than the page content
+	Structured bool
+}
+
+// Text formats the posting for the job description field: title and
+// details first, then the description
+func (p Posting) Text() string {
+	var head []string
+	if title := joinNonEmpty(" at ", p.Title, p.Company); title != "" {
+		head = append(head, title)
+	}
+	if details := joinNonEmpty(" | ", p.Location, p.EmploymentType, p.Salary); details != "" {
+		head = append(head, details)
+	}
+	// The fallback description often opens with the title itself
+	desc := p.Description
+	if p.Title != "" && strings.HasPrefix(desc, "## "+p.Title+"\n") {
+		desc = strings.TrimSpace(strings.TrimPrefix(desc, "## "+p.Title))
+	}
+	if len(head) == 0 {
+		return desc
+	}
+	return strings.Join(head, "\n") + "\n\n" + desc
+}
+
+// Extract reads the job posting from a page, preferring JobPosting JSON-LD
+// and falling back to the main content of the page
+func Extract(page []byte) (Posting, error) {
+	doc, err := html.Parse(bytes.NewReader(page))
+	if err != nil {
+		return Posting{}, err
+	}
+	if p, ok := structuredPosting(doc); ok && len(p.Description) >= minContentLength {
+		return p, nil
+	}
+
+	p := Posting{Title: pageTitle(doc), Company: metaContent(doc, "og:site_name")}
+	p.Description = mainContent(doc)
+	if len(p.Description) < minContentLength {
+		return Posting{}, ErrNoContent
+	}
+	return p, nil
+}
+
+// structuredPosting finds the first JobPosting in the page's JSON-LD
+func structuredPosting(doc *html.Node) (Posting, bool) {
+	for _, script := range findAll(doc, func(n *html.Node) bool {
+		return n.DataAtom == atom.Script && strings.EqualFold(strings.TrimSpace(attr(n, "type")), "application/ld+json")
+	}) {
+		var data any
+		if err := json.Unmarshal(jsonLDText(script), &data); err != nil {
+			continue
+		}
+		if obj := findJobPosting(data); obj != nil {
+			return postingFromJSONLD(obj), true
+		}
+	}
+	return Posting{}, false
+}
+
+// jsonLDText returns a script's JSON. Raw line breaks inside strings are a
+// common mistake in hand-written JSON-LD; outside strings they're just
+// whitespace, so spaces are safe either way.
+func jsonLDText(script *html.Node) []byte {
+	var b strings.Builder
+	for c := script.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+	}
+	return []byte(strings.NewReplacer("\r", " ", "\n", " ", "\t", " ").Replace(b.String()))
+}
+
+// findJobPosting searches JSON-LD for a JobPosting object, through arrays
+// and @graph
+func findJobPosting(v any) map[string]any {
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			if obj := findJobPosting(item); obj != nil {
+				return obj
+			}
+		}
+	case map[string]any:
+		for _, t := range values(v["@type"]) {
+			if t == "JobPosting" || strings.HasSuffix(t, "/JobPosting") {
+				return v
+			}
+		}
+		if graph, ok := v["@graph"]; ok {
+			return findJobPosting(graph)
+		}
+	}
+	return nil
+}
+
+func postingFromJSONLD(obj map[string]any) Posting {
+	p := Posting{
+		Title:      firstOf(str(obj["title"]), str(obj["name"])),
+		Company:    orgName(obj["hiringOrganization"]),
+		Location:   jobLocation(obj),
+		Salary:     salary(obj["baseSalary"]),
+		Structured: true,
+	}
+
+	var types []string
+	for _, t := range values(obj["employmentType"]) {
+		types = append(types, employmentType(t))
+	}
+	p.EmploymentType = strings.Join(types, ", ")
+
+	desc, _ := obj["description"].(string)
+	p.Description = PlainText(desc)
+	return p
+}
+
+func orgName(v any) string {
+	if obj, ok := v.(map[string]any); ok {
+		return str(obj["name"])
+	}
+	return str(v)
+}
+
+// jobLocation lists the posting's places, with remote work noted
+func jobLocation(obj map[string]any) string {
+	var places []string
+	locations := obj["jobLocation"]
+	if _, ok := locations.([]any); !ok {
+		locations = []any{locations}
+	}
+	for _, loc := range locations.([]any) {
+		place, ok := loc.(map[string]any)
+		if !ok {
+			continue
+		}
+		address := place["address"]
+		if a, ok := address.(map[string]any); ok {
+			country := a["addressCountry"]
+			if c, ok := country.(map[string]any); ok {
+				country = c["name"]
+			}
+			address = joinNonEmpty(", ", str(a["addressLocality"]), str(a["addressRegion"]), str(country))
+		}
+		if s := firstOf(str(address), str(place["name"])); s != "" && !contains(places, s) {
+			places = append(places, s)
+		}
+	}
+	if strings.EqualFold(str(obj["jobLocationType"]), "TELECOMMUTE") {
+		places = append(places, "Remote")
+	}
+	return strings.Join(places, "; ")
+}
+
+// employmentType turns schema.org values like FULL_TIME into "Full time"
+func employmentType(t string) string {
+	t = strings.ToLower(strings.ReplaceAll(t, "_", " "))
+	if t == "" {
+		return ""
+	}
+	return strings.ToUpper(t[:1]) + t[1:]
+}
+
+// salary formats a MonetaryAmount such as "USD 120000–150000 per year"
+func salary(v any) string {
+	amount, ok := v.(map[string]any)
+	if !ok {
+		return ""
+	}
+	value := amount["value"]
+	unit := ""
+	if q, ok := value.(map[string]any); ok {
+		unit = str(q["unitText"])
+		value = firstOf(str(q["value"]), joinNonEmpty("–", str(q["minValue"]), str(q["maxValue"])))
+	}
+	s := joinNonEmpty(" ", str(amount["currency"]), str(value))
+	if s == "" || str(value) == "" {
+		return ""
+	}
+	if unit != "" {
+		s += " per " + strings.ToLower(unit)
+	}
+	return s
+}
+
+// pageTitle prefers og:title, then the first h1, then <title>
+func pageTitle(doc *html.Node) string {
+	if s := metaContent(doc, "og:title"); s != "" {
+		return s
+	}
+	for _, a := range []atom.Atom{atom.H1, atom.Title} {
+		if nodes := findAll(doc, func(n *html.Node) bool { return n.DataAtom == a }); len(nodes) > 0 {
+			if s := oneLine(inlineText(nodes[0])); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func metaContent(doc *html.Node, property string) string {
+	for _, n := range findAll(doc, func(n *html.Node) bool { return n.DataAtom == atom.Meta }) {
+		if attr(n, "property") == property || attr(n, "name") == property {
+			return oneLine(attr(n, "content"))
+		}
+	}
+	return ""
+}
+
+// boilerplateTags never hold the job description
+var boilerplateTags = map[atom.Atom]bool{
+	atom.Nav: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+	atom.Button: true, atom.Dialog: true, atom.Menu: true,
+}
+
+// boilerplateRoles are ARIA landmarks around the content
+var boilerplateRoles = map[string]bool{
+	"navigation": true, "banner": true, "contentinfo": true, "complementary": true,
+	"dialog": true, "alertdialog": true, "search": true,
+}
+
+// boilerplatePattern matches class and id names of page furniture
+var boilerplatePattern = regexp.MustCompile(`(?i)(^|[-_\s])(nav|navbar|menu|header|footer|sidebar|cookies?|consent|gdpr|banner|breadcrumbs?|share|social|related|similar|recommended|newsletter|subscribe|signup|promo|ads?|advert|modal|popup|comments?|skip)($|[-_\s])`)
+
+// contentPattern matches class and id names that usually wrap the posting
+var contentPattern = regexp.MustCompile(`(?i)job|description|posting|vacancy|content|article|main`)
+
+// containerTags are the elements that can be chosen as the main content
+var containerTags = map[atom.Atom]bool{
+	atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true,
+	atom.Td: true, atom.Body: true,
+}
+
+// textTags are the blocks whose text is credited to their containers
+var textTags = map[atom.Atom]bool{
+	atom.P: true, atom.Li: true, atom.Pre: true, atom.Blockquote: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Dd: true, atom.Dt: true,
+}
+
+// mainContent strips boilerplate and returns the text of the container
+// holding most of the page's prose, in the spirit of Readability: each
+// paragraph scores its nearest containers, with less credit further up, so
+// the winner is the tightest element around the bulk of the text
+func mainContent(doc *html.Node) string {
+	body := doc
+	if nodes := findAll(doc, func(n *html.Node) bool { return n.DataAtom == atom.Body }); len(nodes) > 0 {
+		body = nodes[0]
+	}
+	prune(body)
+
+	scores := map[*html.Node]float64{}
+	for _, block := range findAll(body, func(n *html.Node) bool { return textTags[n.DataAtom] }) {
+		length := float64(len(oneLine(inlineText(block))))
+		if length < 20 {
+			continue
+		}
+		credit := length
+		for n, level := block.Parent, 0; n != nil && level < 3; n = n.Parent {
+			if n.Type != html.ElementNode || !containerTags[n.DataAtom] {
+				continue
+			}
+			scores[n] += credit
+			credit /= 2
+			level++
+		}
+	}
+
+	var best *html.Node
+	bestScore := 0.0
+	for n, score := range scores {
+		score *= 1 - linkDensity(n)
+		if contentPattern.MatchString(attr(n, "class") + " " + attr(n, "id")) {
+			score *= 1.25
+		}
+		if n.DataAtom == atom.Main || n.DataAtom == atom.Article {
+			score *= 1.25
+		}
+		if score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best == nil {
+		// No paragraphs at all: take whatever text survived pruning
+		best = body
+	}
+	return text(best)
+}
+
+// prune removes boilerplate elements and everything inside them
+func prune(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.CommentNode || c.Type == html.ElementNode && isBoilerplate(c) {
+			n.RemoveChild(c)
+		} else {
+			prune(c)
+		}
+		c = next
+	}
+}
+
+func isBoilerplate(n *html.Node) bool {
+	if skippedTags[n.DataAtom] || boilerplateTags[n.DataAtom] || boilerplateRoles[attr(n, "role")] {
+		return true
+	}
+	if _, hidden := getAttr(n, "hidden"); hidden || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	if n.DataAtom == atom.Main || n.DataAtom == atom.Article {
+		return false
+	}
+	return boilerplatePattern.MatchString(attr(n, "class")) || boilerplatePattern.MatchString(attr(n, "id"))
+}
+
+// linkDensity is the share of an element's text inside links; navigation
+// lists are mostly links, descriptions hardly any
+func linkDensity(n *html.Node) float64 {
+	total := len(oneLine(inlineText(n)))
+	if total == 0 {
+		return 1
+	}
+	linked := 0
+	for _, a := range findAll(n, func(n *html.Node) bool { return n.DataAtom == atom.A }) {
+		linked += len(oneLine(inlineText(a)))
+	}
+	return float64(linked) / float64(total)
+}
+
+func findAll(n *html.Node, match func(*html.Node) bool) []*html.Node {
+	var found []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && match(n) {
+			found = append(found, n)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return found
+}
+
+func getAttr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func attr(n *html.Node, key string) string {
+	v, _ := getAttr(n, key)
+	return v
+}
+
+// str reads a JSON-LD scalar as text
+func str(v any) string {
+	switch v := v.(type) {
+	case string:
+		return oneLine(html.UnescapeString(v))
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// values reads a JSON-LD value that may be a single string or a list
+func values(v any) []string {
+	if list, ok := v.([]any); ok {
+		var out []string
+		for _, item := range list {
+			if s := str(item); s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	if s := str(v); s != "" {
+		return []string{s}
+	}
+	return nil
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var out []string
+	for _, s := range parts {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return strings.Join(out, sep)
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// oneLine collapses whitespace, including newlines, to single spaces
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
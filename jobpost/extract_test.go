@@ -0,0 +1,126 @@
+package jobpost
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const jsonLDPage = `<!doctype html>
+<html><head>
+<title>Careers | Acme</title>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@graph": [
+  {"@type": "Organization", "name": "Acme"},
+  {"@type": "JobPosting",
+   "title": "Senior Backend Engineer",
+   "hiringOrganization": {"@type": "Organization", "name": "Acme Corp"},
+   "jobLocation": [
+     {"@type": "Place", "address": {"addressLocality": "Berlin", "addressCountry": {"name": "Germany"}}},
+     {"@type": "Place", "address": {"addressLocality": "Berlin", "addressCountry": {"name": "Germany"}}}
+   ],
+   "jobLocationType": "TELECOMMUTE",
+   "employmentType": ["FULL_TIME", "CONTRACTOR"],
+   "baseSalary": {"@type": "MonetaryAmount", "currency": "EUR",
+     "value": {"@type": "QuantitativeValue", "minValue": 80000, "maxValue": 100000, "unitText": "YEAR"}},
+   "description": "<p>Join the payments team to build the billing platform that runs every invoice we send.</p>
+<h3>What you'll do</h3><ul><li>Design and own Go services handling millions of requests</li><li>Mentor engineers across two teams</li></ul>"}
+]}
+</script>
+</head><body><nav><a href="/">Home</a></nav><p>Apply below.</p></body></html>`
+
+func TestExtractJSONLD(t *testing.T) {
+	p, err := Extract([]byte(jsonLDPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Posting{
+		Title:          "Senior Backend Engineer",
+		Company:        "Acme Corp",
+		Location:       "Berlin, Germany; Remote",
+		EmploymentType: "Full time, Contractor",
+		Salary:         "EUR 80000–100000 per year",
+		Description: `Join the payments team to build the billing platform that runs every invoice we send.
+
+### What you'll do
+
+- Design and own Go services handling millions of requests
+- Mentor engineers across two teams`,
+		Structured: true,
+	}
+	if p != want {
+		t.Fatalf("Extract =\n%+v\nwant\n%+v", p, want)
+	}
+	if text := p.Text(); !strings.HasPrefix(text, "Senior Backend Engineer at Acme Corp\nBerlin, Germany; Remote | Full time, Contractor | EUR 80000–100000 per year\n\nJoin the payments team") {
+		t.Fatalf("Text =\n%s", text)
+	}
+}
+
+const articlePage = `<!doctype html>
+<html><head>
+<title>Jobs</title>
+<meta property="og:title" content="Site Reliability Engineer">
+<meta property="og:site_name" content="Initech">
+</head><body>
+<header class="site-header"><a href="/">Initech</a> <a href="/about">About us</a></header>
+<nav><ul><li><a href="/jobs">All jobs</a></li><li><a href="/teams">Teams and offices around the world</a></li></ul></nav>
+<div class="cookie-banner"><p>We use cookies to improve your experience on our website and for analytics.</p></div>
+<main>
+<h1>Site Reliability Engineer</h1>
+<p>You will keep our payment systems running around the clock and make on-call calmer for everyone.</p>
+<ul><li>Run Kubernetes clusters across three regions</li><li>Automate incident response with Go tooling</li></ul>
+<p>We offer a four day week and a generous learning budget for conferences.</p>
+</main>
+<aside><p>Similar jobs: Platform Engineer, Database Administrator, Network Engineer</p></aside>
+<footer><p>Copyright Initech. All rights reserved. Privacy policy and terms of use.</p></footer>
+</body></html>`
+
+func TestExtractMainContent(t *testing.T) {
+	p, err := Extract([]byte(articlePage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Title != "Site Reliability Engineer" || p.Company != "Initech" || p.Structured {
+		t.Fatalf("Extract = %+v", p)
+	}
+	for _, want := range []string{"## Site Reliability Engineer", "keep our payment systems running", "- Run Kubernetes clusters", "four day week"} {
+		if !strings.Contains(p.Description, want) {
+			t.Errorf("description is missing %q:\n%s", want, p.Description)
+		}
+	}
+	for _, boilerplate := range []string{"About us", "All jobs", "cookies", "Similar jobs", "Copyright"} {
+		if strings.Contains(p.Description, boilerplate) {
+			t.Errorf("description kept boilerplate %q:\n%s", boilerplate, p.Description)
+		}
+	}
+	// The title heading isn't repeated in the text
+	if text := p.Text(); strings.Count(text, "Site Reliability Engineer") != 1 {
+		t.Errorf("Text repeats the title:\n%s", text)
+	}
+}
+
+func TestExtractBoilerplateOnly(t *testing.T) {
+	page := `<html><head><title>Sign in</title></head><body>
+<header><a href="/">Home</a></header>
+<nav><ul><li><a href="/jobs">Jobs</a></li><li><a href="/login">Sign in to see this job and many more like it</a></li></ul></nav>
+<div id="consent-modal"><p>We use cookies to personalise content and ads, and to analyse our traffic and usage.</p></div>
+<div class="content"><p>Please sign in to continue.</p></div>
+<footer><p>Copyright 2026 Example Jobs Ltd. All rights reserved. Terms, privacy and accessibility statement.</p></footer>
+</body></html>`
+	if p, err := Extract([]byte(page)); !errors.Is(err, ErrNoContent) {
+		t.Fatalf("Extract = %+v, %v; want ErrNoContent", p, err)
+	}
+}
+
+func TestExtractShortJSONLDFallsBack(t *testing.T) {
+	// A JobPosting with a stub description loses to the page content
+	page := strings.Replace(articlePage, "</head>",
+		`<script type="application/ld+json">{"@type": "JobPosting", "title": "SRE", "description": "See page"}</script></head>`, 1)
+	p, err := Extract([]byte(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Structured || !strings.Contains(p.Description, "Run Kubernetes clusters") {
+		t.Fatalf("Extract = %+v, want the page content", p)
+	}
+}
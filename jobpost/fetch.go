@@ -0,0 +1,257 @@
+// Package jobpost imports job descriptions from job board pages.
+//
+// Pages are fetched server-side through a Fetcher that refuses to connect
+// to private, loopback and link-local addresses (checked on the resolved
+// address at connect time, so DNS tricks and redirects can't get around
+// it), with a deadline and a size cap. Extract then pulls out the posting:
+// schema.org JobPosting JSON-LD when the page has it, otherwise the main
+// content left after stripping navigation and other boilerplate.
+package jobpost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	// defaultMaxSize caps a downloaded page
+	defaultMaxSize = 2 << 20
+	maxRedirects   = 5
+	userAgent      = "Mozilla/5.0 (compatible; ResumeTweaker/1.0; job description import)"
+)
+
+var (
+	// ErrInvalidURL is returned for anything but an absolute http(s) URL
+	ErrInvalidURL = errors.New("invalid URL")
+	// ErrBlocked is returned when the URL resolves to an address the server
+	// must not connect to
+	ErrBlocked = errors.New("address not allowed")
+	// ErrTooLarge is returned for pages over the size cap
+	ErrTooLarge = errors.New("page too large")
+	// ErrNotHTML is returned for responses that aren't web pages
+	ErrNotHTML = errors.New("not an HTML page")
+	// ErrNoContent is returned when no job description could be found
+	ErrNoContent = errors.New("no job description found")
+)
+
+// StatusError is returned when the site answers with an error status
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d", e.Code)
+}
+
+// Fetcher downloads job pages
+type Fetcher struct {
+	Client *http.Client
+	// MaxSize caps the page size in bytes
+	MaxSize int64
+}
+
+// New returns a Fetcher with the SSRF guard, configured from
+// JOB_IMPORT_TIMEOUT and JOB_IMPORT_MAX_SIZE
+func New() *Fetcher {
+	timeout := defaultTimeout
+	if raw := os.Getenv("JOB_IMPORT_TIMEOUT"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			timeout = d
+		} else {
+			log.Printf("[JobImport] Invalid JOB_IMPORT_TIMEOUT %q, using %s", raw, timeout)
+		}
+	}
+	maxSize := int64(defaultMaxSize)
+	if raw := os.Getenv("JOB_IMPORT_MAX_SIZE"); raw != "" {
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil && n > 0 {
+			maxSize = n
+		} else {
+			log.Printf("[JobImport] Invalid JOB_IMPORT_MAX_SIZE %q, using %d", raw, maxSize)
+		}
+	}
+	return &Fetcher{Client: GuardedClient(timeout), MaxSize: maxSize}
+}
+
+// GuardedClient returns an HTTP client that only connects to public
+// addresses on ports 80 and 443, ignores proxy settings (a proxy would
+// connect on our behalf, unchecked) and follows at most a few redirects
+func GuardedClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout / 2,
+		Control: guardConn,
+	}
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout / 2,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return checkURL(req.URL)
+		},
+	}
+}
+
+// guardConn runs after DNS resolution, on the address about to be dialed
+func guardConn(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlocked, address)
+	}
+	if port := addrPort.Port(); port != 80 && port != 443 {
+		return fmt.Errorf("%w: port %d", ErrBlocked, port)
+	}
+	if !PublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlocked, addrPort.Addr())
+	}
+	return nil
+}
+
+// blockedPrefixes are special-purpose ranges netip's predicates don't cover
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, which can reach IPv4 private space
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("2002::/16"),       // 6to4, which embeds an IPv4 address
+	netip.MustParsePrefix("fec0::/10"),       // deprecated site-local
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+}
+
+// PublicAddr reports whether addr is a public unicast address the server may
+// connect to: not loopback, private, link-local (cloud metadata lives at
+// 169.254.169.254), multicast or otherwise special
+func PublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseURL checks a user-supplied URL, adding https:// when the scheme is
+// missing
+func ParseURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	if err := checkURL(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: only http and https are supported", ErrInvalidURL)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("%w: missing host", ErrInvalidURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials in URL", ErrInvalidURL)
+	}
+	return nil
+}
+
+// Fetch downloads a page and returns its body decoded to UTF-8 along with
+// the final URL after redirects
+func (f *Fetcher) Fetch(ctx context.Context, u *url.URL) ([]byte, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, nil, &StatusError{Code: resp.StatusCode}
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil &&
+		mediaType != "text/html" && mediaType != "application/xhtml+xml" && mediaType != "text/plain" {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotHTML, mediaType)
+	}
+	if resp.ContentLength > f.MaxSize {
+		return nil, nil, ErrTooLarge
+	}
+
+	body, err := charset.NewReader(resp.Body, contentType)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(body, f.MaxSize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(data)) > f.MaxSize {
+		return nil, nil, ErrTooLarge
+	}
+	return data, resp.Request.URL, nil
+}
+
+// Import fetches a job page and extracts the posting from it
+func (f *Fetcher) Import(ctx context.Context, rawURL string) (Posting, error) {
+	u, err := ParseURL(rawURL)
+	if err != nil {
+		return Posting{}, err
+	}
+	page, final, err := f.Fetch(ctx, u)
+	if err != nil {
+		return Posting{}, err
+	}
+	posting, err := Extract(page)
+	if err != nil {
+		return Posting{}, err
+	}
+	posting.URL = final.String()
+	return posting, nil
+}
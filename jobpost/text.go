@@ -0,0 +1,225 @@
+package jobpost
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// skippedTags never contain readable text
+var skippedTags = map[atom.Atom]bool{
+	atom.Head: true, atom.Title: true, atom.Script: true, atom.Style: true,
+	atom.Noscript: true, atom.Template: true, atom.Svg: true, atom.Math: true,
+	atom.Iframe: true, atom.Object: true, atom.Embed: true, atom.Canvas: true,
+	atom.Select: true, atom.Textarea: true, atom.Input: true,
+}
+
+// paragraphTags get a blank line around them; other block elements just a
+// line break
+var paragraphTags = map[atom.Atom]bool{
+	atom.P: true, atom.Blockquote: true, atom.Table: true, atom.Hr: true,
+	atom.Ul: true, atom.Ol: true, atom.Dl: true, atom.Pre: true,
+}
+
+var lineTags = map[atom.Atom]bool{
+	atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true,
+	atom.Header: true, atom.Footer: true, atom.Tr: true, atom.Dt: true,
+	atom.Dd: true, atom.Figure: true, atom.Figcaption: true, atom.Address: true,
+	atom.Details: true, atom.Summary: true,
+}
+
+// PlainText converts a job description given as an HTML fragment to text
+// like Extract's. Descriptions that are already plain text keep their line
+// breaks, and HTML that was entity-encoded a second time, as some job
+// boards and sites do, is decoded first.
+func PlainText(src string) string {
+	if !strings.Contains(src, "<") && strings.Contains(src, "&lt;") {
+		src = html.UnescapeString(src)
+	}
+	if !strings.Contains(src, "<") {
+		src = strings.ReplaceAll(src, "\n", "<br>")
+	}
+	node, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return strings.TrimSpace(src)
+	}
+	return text(node)
+}
+
+// text converts HTML to plain text, keeping headings as "## " lines and
+// list items as "- " or "1. " lines so the structure survives in a textarea
+func text(n *html.Node) string {
+	w := &textWriter{}
+	w.walk(n)
+	s := blankLines.ReplaceAllString(w.b.String(), "\n\n")
+	return strings.TrimSpace(s)
+}
+
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+type textWriter struct {
+	b strings.Builder
+	// space is a pending space between inline runs
+	space bool
+	// lists holds the next number of each open list, 0 for bullets
+	lists []int
+	// itemStart is set right after a list marker, where line breaks from
+	// blocks inside the item are dropped
+	itemStart bool
+}
+
+func (w *textWriter) write(s string) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		w.space = w.space || s != ""
+		return
+	}
+	leading := s[0] == ' ' || s[0] == '\t' || s[0] == '\n' || s[0] == '\r' || s[0] == '\f'
+	if (w.space || leading) && w.b.Len() > 0 && !strings.HasSuffix(w.b.String(), "\n") && !w.itemStart {
+		w.b.WriteByte(' ')
+	}
+	w.b.WriteString(strings.Join(fields, " "))
+	last := s[len(s)-1]
+	w.space = last == ' ' || last == '\t' || last == '\n' || last == '\r' || last == '\f'
+	w.itemStart = false
+}
+
+// newline ends the current line with up to n line breaks in a row
+func (w *textWriter) newline(n int) {
+	w.space = false
+	if w.b.Len() == 0 || w.itemStart {
+		return
+	}
+	s := w.b.String()
+	have := len(s) - len(strings.TrimRight(s, "\n"))
+	for ; have < n; have++ {
+		w.b.WriteByte('\n')
+	}
+}
+
+func (w *textWriter) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.walk(c)
+	}
+}
+
+func (w *textWriter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.write(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		w.children(n)
+		return
+	}
+	if skippedTags[n.DataAtom] {
+		return
+	}
+
+	// Blocks inside a list item stay on the item's lines
+	para := 2
+	if len(w.lists) > 0 {
+		para = 1
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		w.newline(1)
+
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		w.newline(para)
+		prefix := "## "
+		if n.DataAtom != atom.H1 && n.DataAtom != atom.H2 {
+			prefix = "### "
+		}
+		heading := oneLine(inlineText(n))
+		if heading != "" {
+			w.b.WriteString(prefix + heading)
+		}
+		w.newline(para)
+
+	case atom.Ul, atom.Ol:
+		w.newline(para)
+		next := 0
+		if n.DataAtom == atom.Ol {
+			next = 1
+			if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+				next = start
+			}
+		}
+		w.lists = append(w.lists, next)
+		w.children(n)
+		w.lists = w.lists[:len(w.lists)-1]
+		w.newline(para)
+
+	case atom.Li:
+		w.newline(1)
+		depth := len(w.lists)
+		if depth == 0 {
+			// A stray <li> outside a list
+			w.lists = append(w.lists, 0)
+			defer func() { w.lists = w.lists[:0] }()
+			depth = 1
+		}
+		marker := "- "
+		if number := w.lists[depth-1]; number > 0 {
+			marker = strconv.Itoa(number) + ". "
+			w.lists[depth-1]++
+		}
+		w.b.WriteString(strings.Repeat("  ", depth-1) + marker)
+		w.itemStart = true
+		w.children(n)
+		w.itemStart = false
+		w.newline(1)
+
+	case atom.Pre:
+		w.newline(para)
+		for _, line := range strings.Split(strings.Trim(inlineText(n), "\n"), "\n") {
+			w.b.WriteString(strings.TrimRight(line, " \t\r") + "\n")
+		}
+		w.newline(para)
+
+	case atom.Td, atom.Th:
+		w.children(n)
+		w.space = true
+
+	default:
+		switch {
+		case paragraphTags[n.DataAtom]:
+			w.newline(para)
+			w.children(n)
+			w.newline(para)
+		case lineTags[n.DataAtom]:
+			w.newline(1)
+			w.children(n)
+			w.newline(1)
+		default:
+			w.children(n)
+		}
+	}
+}
+
+// inlineText concatenates the text inside an element
+func inlineText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch {
+			case c.Type == html.TextNode:
+				b.WriteString(c.Data)
+			case c.Type == html.ElementNode && skippedTags[c.DataAtom]:
+			case c.Type == html.ElementNode && c.DataAtom == atom.Br:
+				b.WriteString("\n")
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package jobs
+
+import (
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Collection is the PocketBase collection holding tweak jobs
+const Collection = "tweak_jobs"
+
+// MaxResultChars bounds a stored result; PocketBase defaults text fields
+// to 5000 characters
+const MaxResultChars = 500000
+
+// SetupCollections creates the tweak_jobs collection if it doesn't exist,
+// and marks jobs left running by a previous process as interrupted
+func SetupCollections(app core.App) error {
+	existing, err := app.FindCollectionByNameOrId(Collection)
+	if err != nil {
+		log.Printf("[Setup] Creating %s collection...", Collection)
+
+		usersCollection, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		collection := core.NewBaseCollection(Collection)
+		collection.Fields.Add(&core.RelationField{
+			Name:          "user",
+			Required:      true,
+			CollectionId:  usersCollection.Id,
+			MaxSelect:     1,
+			CascadeDelete: true,
+		})
+		collection.Fields.Add(&core.SelectField{
+			Name:      "status",
+			Required:  true,
+			MaxSelect: 1,
+			Values:    []string{StatusRunning, StatusDone, StatusFailed, StatusInterrupted},
+		})
+		collection.Fields.Add(&core.TextField{
+			Name: "mode",
+		})
+		collection.Fields.Add(&core.TextField{
+			Name: "served_by",
+		})
+		collection.Fields.Add(&core.TextField{
+			Name: "result",
+			Max:  MaxResultChars,
+		})
+		collection.Fields.Add(&core.TextField{
+			Name: "error",
+		})
+		collection.Fields.Add(&core.TextField{
+			Name: "resume_id",
+		})
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		collection.AddIndex("idx_tweak_jobs_user_created", false, "user, created", "")
+
+		// Users can read their own jobs; only the server writes them
+		collection.ListRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+		collection.ViewRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	// Link to the saved resume, for collections created before it existed
+	if existing.Fields.GetByName("resume_id") == nil {
+		existing.Fields.Add(&core.TextField{Name: "resume_id"})
+		if err := app.Save(existing); err != nil {
+			return err
+		}
+	}
+
+	// Nothing survives a restart, so anything still running was cut off
+	_, err = app.DB().Update(Collection,
+		dbx.Params{"status": StatusInterrupted},
+		dbx.HashExp{"status": StatusRunning},
+	).Execute()
+	return err
+}
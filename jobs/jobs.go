@@ -0,0 +1,395 @@
+// Package jobs runs tweak generations in the background, independent of the
+// request that started them.
+//
+// Output accumulates on a Job as signals and result content, each change
+// bumping the job's version. Identical requests share one running job (see
+// Key), so a double submit or a second tab doesn't pay for a second stream.
+// SSE handlers subscribe by polling Since with the last version they sent,
+// so a client that reconnects with Last-Event-ID gets exactly what it
+// missed. Jobs are persisted to the tweak_jobs collection, so the result is
+// saved even if nobody is watching.
+package jobs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/johnhkchen/resume-tweaker/sse"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Job statuses
+const (
+	StatusRunning     = "running"
+	StatusDone        = "done"
+	StatusFailed      = "failed"
+	StatusInterrupted = "interrupted"
+)
+
+const (
+	// saveInterval is how often partial output is written to the database
+	saveInterval = 5 * time.Second
+	// retainFor is how long a finished job stays in memory for reconnects;
+	// after that it is served from the database
+	retainFor = 10 * time.Minute
+)
+
+// ErrNotFound is returned for unknown jobs and jobs owned by another user
+var ErrNotFound = errors.New("job not found")
+
+// registry holds jobs that are running or recently finished, and indexes
+// running jobs by input key
+var registry = struct {
+	sync.Mutex
+	jobs     map[string]*Job
+	inflight map[string]*Job
+}{jobs: map[string]*Job{}, inflight: map[string]*Job{}}
+
+// Job is a single background generation
+type Job struct {
+	ID     string
+	UserID string
+	key    string
+	mode   string
+
+	app    core.App
+	record *core.Record
+
+	mu      sync.Mutex
+	version int
+	status  string
+	content string
+	// lengths records the content length at each version where it changed
+	// since lastReset, the last time it was replaced rather than appended to
+	lengths   []versionLength
+	lastReset int
+	signals   sse.Signals
+	// signalVersions is the version each signal last changed at
+	signalVersions map[string]int
+	changed        chan struct{}
+	lastSave       time.Time
+}
+
+type versionLength struct {
+	version int
+	length  int
+}
+
+// Update is what a subscriber needs to catch up from an earlier version
+type Update struct {
+	Version int
+	Content string
+	// Known is the length of Content the subscriber already has, or -1 if
+	// the content was replaced since and must be re-sent in full
+	Known int
+	// Signals holds the signals that changed since the earlier version
+	Signals sse.Signals
+	Done    bool
+	// Status is the job status at Version
+	Status string
+}
+
+// Key identifies a request by its user and inputs. Whitespace differences
+// don't count, so a re-pasted resume still matches.
+func Key(userID string, inputs ...string) string {
+	h := sha256.New()
+	h.Write([]byte(userID))
+	for _, in := range inputs {
+		h.Write([]byte{0})
+		h.Write([]byte(strings.Join(strings.Fields(in), " ")))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Join returns the running job for key, if there is one
+func Join(key string) *Job {
+	registry.Lock()
+	defer registry.Unlock()
+	return registry.inflight[key]
+}
+
+// Start creates and registers a running job for userID under key. initial
+// seeds the job's signals, which are replayed to every new subscriber. If a
+// job with the same key started in the meantime, that job is returned with
+// joined set instead.
+func Start(app core.App, userID, mode, key string, initial sse.Signals) (job *Job, joined bool, err error) {
+	startMu.Lock()
+	defer startMu.Unlock()
+	if j := Join(key); j != nil {
+		return j, true, nil
+	}
+
+	collection, err := app.FindCollectionByNameOrId(Collection)
+	if err != nil {
+		return nil, false, err
+	}
+	record := core.NewRecord(collection)
+	record.Set("user", userID)
+	record.Set("mode", mode)
+	record.Set("status", StatusRunning)
+	if err := app.Save(record); err != nil {
+		return nil, false, err
+	}
+
+	j := newJob(app, record, userID)
+	j.key = key
+	j.Signal(initial)
+	j.Signal(sse.Signals{"job_id": j.ID})
+
+	registry.Lock()
+	registry.jobs[j.ID] = j
+	registry.inflight[key] = j
+	registry.Unlock()
+	return j, false, nil
+}
+
+// startMu makes Start's check-then-create atomic per process
+var startMu sync.Mutex
+
+func newJob(app core.App, record *core.Record, userID string) *Job {
+	return &Job{
+		ID:             record.Id,
+		UserID:         userID,
+		mode:           record.GetString("mode"),
+		app:            app,
+		record:         record,
+		status:         StatusRunning,
+		signals:        sse.Signals{},
+		signalVersions: map[string]int{},
+		changed:        make(chan struct{}),
+		lastSave:       time.Now(),
+	}
+}
+
+// Get returns a user's job, from memory while it is running or recently
+// finished and from the database after that
+func Get(app core.App, userID, id string) (*Job, error) {
+	registry.Lock()
+	j, ok := registry.jobs[id]
+	registry.Unlock()
+	if ok {
+		if j.UserID != userID {
+			return nil, ErrNotFound
+		}
+		return j, nil
+	}
+
+	record, err := app.FindRecordById(Collection, id)
+	if err != nil || record.GetString("user") != userID {
+		return nil, ErrNotFound
+	}
+	return load(app, record), nil
+}
+
+// Active returns the user's most recent running job in one of modes, if any
+func Active(userID string, modes ...string) *Job {
+	registry.Lock()
+	defer registry.Unlock()
+
+	var latest *Job
+	for _, j := range registry.jobs {
+		if j.UserID != userID || j.Status() != StatusRunning || !slices.Contains(modes, j.mode) {
+			continue
+		}
+		if latest == nil || j.record.GetDateTime("created").Time().After(latest.record.GetDateTime("created").Time()) {
+			latest = j
+		}
+	}
+	return latest
+}
+
+// load rebuilds a finished job from its record. A job still marked running
+// here was cut off by a restart.
+func load(app core.App, record *core.Record) *Job {
+	j := newJob(app, record, record.GetString("user"))
+	j.status = record.GetString("status")
+	if j.status == StatusRunning {
+		j.status = StatusInterrupted
+	}
+
+	signals := sse.Signals{
+		"job_id":    j.ID,
+		"loading":   false,
+		"served_by": record.GetString("served_by"),
+		"error":     record.GetString("error"),
+		"resume_id": record.GetString("resume_id"),
+	}
+	switch j.status {
+	case StatusDone:
+		signals["step"] = 4
+	case StatusInterrupted:
+		signals["notice"] = "This tweak was interrupted before it finished. Showing what was generated so far."
+	}
+
+	j.setContent(record.GetString("result"))
+	j.mergeSignals(signals)
+	close(j.changed)
+	return j
+}
+
+// Status returns the job's status
+func (j *Job) Status() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
+
+// Content returns the result generated so far
+func (j *Job) Content() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.content
+}
+
+// Signal merges signals into the job state
+func (j *Job) Signal(signals sse.Signals) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status != StatusRunning {
+		return
+	}
+	j.mergeSignals(signals)
+	j.notify()
+}
+
+// SetContent replaces the accumulated result, periodically saving it
+func (j *Job) SetContent(content string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status != StatusRunning || content == j.content {
+		return
+	}
+	j.setContent(content)
+	j.notify()
+
+	if time.Since(j.lastSave) >= saveInterval {
+		j.save()
+	}
+}
+
+// Finish marks the job done, merging the final signals
+func (j *Job) Finish(signals sse.Signals) {
+	j.finish(StatusDone, "", signals)
+}
+
+// Fail marks the job failed with a user-facing message
+func (j *Job) Fail(message string, signals sse.Signals) {
+	j.finish(StatusFailed, message, signals)
+}
+
+func (j *Job) finish(status, message string, signals sse.Signals) {
+	j.mu.Lock()
+	if j.status != StatusRunning {
+		j.mu.Unlock()
+		return
+	}
+
+	if message != "" {
+		j.mergeSignals(sse.Signals{"error": message})
+	}
+	j.mergeSignals(signals)
+	j.mergeSignals(sse.Signals{"loading": false})
+	j.status = status
+	j.record.Set("error", message)
+	j.save()
+	j.notify()
+	j.mu.Unlock()
+
+	// New identical requests start fresh from here on
+	registry.Lock()
+	if registry.inflight[j.key] == j {
+		delete(registry.inflight, j.key)
+	}
+	registry.Unlock()
+
+	time.AfterFunc(retainFor, func() {
+		registry.Lock()
+		delete(registry.jobs, j.ID)
+		registry.Unlock()
+	})
+}
+
+// Since returns everything that changed after version, and a channel that
+// is closed on the next change. The channel is already closed once the job
+// is finished.
+func (j *Job) Since(version int) (Update, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	u := Update{
+		Version: j.version,
+		Content: j.content,
+		Known:   j.lengthAt(version),
+		Signals: sse.Signals{},
+		Done:    j.status != StatusRunning,
+		Status:  j.status,
+	}
+	for key, v := range j.signalVersions {
+		if v > version {
+			u.Signals[key] = j.signals[key]
+		}
+	}
+	return u, j.changed
+}
+
+// lengthAt returns the content length at version, or -1 if the content was
+// replaced after it
+func (j *Job) lengthAt(version int) int {
+	if version < j.lastReset {
+		return -1
+	}
+	i := sort.Search(len(j.lengths), func(i int) bool { return j.lengths[i].version > version })
+	if i == 0 {
+		return 0
+	}
+	return j.lengths[i-1].length
+}
+
+func (j *Job) mergeSignals(signals sse.Signals) {
+	j.version++
+	for key, value := range signals {
+		j.signals[key] = value
+		j.signalVersions[key] = j.version
+	}
+}
+
+func (j *Job) setContent(content string) {
+	j.version++
+	if !strings.HasPrefix(content, j.content) {
+		j.lastReset = j.version
+		j.lengths = j.lengths[:0]
+	}
+	j.lengths = append(j.lengths, versionLength{version: j.version, length: len(content)})
+	j.content = content
+}
+
+// notify wakes subscribers waiting in Since
+func (j *Job) notify() {
+	close(j.changed)
+	if j.status == StatusRunning {
+		j.changed = make(chan struct{})
+	}
+}
+
+// save writes the job's progress to its record. Callers hold j.mu.
+func (j *Job) save() {
+	j.record.Set("status", j.status)
+	j.record.Set("result", j.content)
+	if servedBy, ok := j.signals["served_by"].(string); ok {
+		j.record.Set("served_by", servedBy)
+	}
+	if resumeID, ok := j.signals["resume_id"].(string); ok {
+		j.record.Set("resume_id", resumeID)
+	}
+	if err := j.app.Save(j.record); err != nil {
+		log.Printf("[Jobs] Warning: failed to save job %s: %v", j.ID, err)
+	}
+	j.lastSave = time.Now()
+}
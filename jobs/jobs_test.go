@@ -0,0 +1,149 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/johnhkchen/resume-tweaker/sse"
+	"github.com/johnhkchen/resume-tweaker/testapp"
+)
+
+// startJob starts a job for a fresh user in a test app
+func startJob(t *testing.T) *Job {
+	t.Helper()
+	app := testapp.New(t, SetupCollections)
+	user := testapp.User(t, app)
+
+	job, joined, err := Start(app, user.Id, "tweak", Key(user.Id, t.Name()), sse.Signals{"step": 0})
+	if err != nil || joined {
+		t.Fatalf("Start = %v, joined %v", err, joined)
+	}
+	return job
+}
+
+func TestSinceDeltas(t *testing.T) {
+	j := startJob(t)
+
+	// Start merged the initial signals, then job_id
+	u, _ := j.Since(0)
+	if u.Version != 2 || u.Known != 0 || u.Content != "" {
+		t.Fatalf("Since(0) = %+v", u)
+	}
+	if u.Signals["step"] != 0 || u.Signals["job_id"] != j.ID {
+		t.Fatalf("Since(0) signals = %v", u.Signals)
+	}
+
+	j.SetContent("Hello")       // v3
+	j.SetContent("Hello world") // v4
+	j.Signal(sse.Signals{"step": 3})
+
+	tests := []struct {
+		since   int
+		known   int
+		signals []string
+	}{
+		{since: 0, known: 0, signals: []string{"step", "job_id"}},
+		{since: 2, known: 0, signals: []string{"step"}},
+		{since: 3, known: len("Hello"), signals: []string{"step"}},
+		{since: 4, known: len("Hello world"), signals: []string{"step"}},
+		{since: 5, known: len("Hello world")},
+	}
+	for _, tt := range tests {
+		u, _ := j.Since(tt.since)
+		if u.Version != 5 || u.Content != "Hello world" || u.Known != tt.known {
+			t.Errorf("Since(%d) = version %d, known %d; want 5, %d", tt.since, u.Version, u.Known, tt.known)
+		}
+		if len(u.Signals) != len(tt.signals) {
+			t.Errorf("Since(%d) signals = %v, want %v", tt.since, u.Signals, tt.signals)
+		}
+		for _, key := range tt.signals {
+			if _, ok := u.Signals[key]; !ok {
+				t.Errorf("Since(%d) missing signal %q", tt.since, key)
+			}
+		}
+	}
+}
+
+func TestSinceAfterContentReplaced(t *testing.T) {
+	j := startJob(t)
+	j.SetContent("Draft from the first model") // v3
+	j.SetContent("")                           // v4: fell back, content reset
+	j.SetContent("Second")                     // v5
+	j.SetContent("Second model")               // v6
+
+	for since, want := range map[int]int{
+		0: -1, // had content from before the reset
+		2: -1,
+		3: -1,
+		4: 0,
+		5: len("Second"),
+		6: len("Second model"),
+	} {
+		if got := j.lengthAt(since); got != want {
+			t.Errorf("lengthAt(%d) = %d, want %d", since, got, want)
+		}
+		if u, _ := j.Since(since); u.Known != want {
+			t.Errorf("Since(%d).Known = %d, want %d", since, u.Known, want)
+		}
+	}
+}
+
+func TestLengthAtBeforeAnyContent(t *testing.T) {
+	j := startJob(t)
+	if got := j.lengthAt(0); got != 0 {
+		t.Fatalf("lengthAt(0) on an empty job = %d, want 0", got)
+	}
+	j.Signal(sse.Signals{"step": 1}) // v3
+	j.SetContent("abc")              // v4
+	if got := j.lengthAt(3); got != 0 {
+		t.Fatalf("lengthAt before the first content = %d, want 0", got)
+	}
+	if got := j.lengthAt(99); got != 3 {
+		t.Fatalf("lengthAt past the latest version = %d, want 3", got)
+	}
+}
+
+func TestSinceNotifiesAndFinishes(t *testing.T) {
+	j := startJob(t)
+
+	_, changed := j.Since(0)
+	select {
+	case <-changed:
+		t.Fatal("changed closed before any change")
+	default:
+	}
+	j.SetContent("Result")
+	select {
+	case <-changed:
+	default:
+		t.Fatal("changed not closed by SetContent")
+	}
+
+	j.Finish(sse.Signals{"step": 4})
+	u, changed := j.Since(0)
+	if !u.Done || u.Status != StatusDone || u.Signals["loading"] != false {
+		t.Fatalf("Since after Finish = %+v", u)
+	}
+	select {
+	case <-changed:
+	default:
+		t.Fatal("changed not closed on a finished job")
+	}
+
+	// A finished job ignores late output
+	j.SetContent("Late")
+	if u, _ := j.Since(0); u.Content != "Result" {
+		t.Fatalf("content changed after Finish: %q", u.Content)
+	}
+}
+
+func TestFailRecordsError(t *testing.T) {
+	j := startJob(t)
+	j.Fail("Something went wrong", nil)
+	u, _ := j.Since(2)
+	if u.Status != StatusFailed || u.Signals["error"] != "Something went wrong" {
+		t.Fatalf("Since after Fail = %+v", u)
+	}
+	if Join(j.key) != nil {
+		t.Fatal("failed job still joinable")
+	}
+}
@@ -0,0 +1,732 @@
+package jsonresume
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johnhkchen/resume-tweaker/export"
+)
+
+// Import validates a resume.json and renders it as markdown
+func Import(data []byte) (string, error) {
+	r, err := Parse(data)
+	if err != nil {
+		return "", err
+	}
+	return Markdown(r), nil
+}
+
+// Export converts a markdown resume to an indented, schema-valid resume.json
+func Export(doc export.Document) ([]byte, error) {
+	r := FromDocument(doc)
+	r.Schema = SchemaURL
+	if err := Validate(r); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// sectionKinds maps words in a ## heading to the section they introduce.
+// The first match wins, so "volunteer experience" is volunteering, not work.
+var sectionKinds = []struct{ word, kind string }{
+	{"volunteer", "volunteer"},
+	{"summary", "summary"},
+	{"profile", "summary"},
+	{"about", "summary"},
+	{"objective", "summary"},
+	{"education", "education"},
+	{"academic", "education"},
+	{"project", "projects"},
+	{"award", "awards"},
+	{"honor", "awards"},
+	{"achievement", "awards"},
+	{"certif", "certificates"},
+	{"licens", "certificates"},
+	{"publication", "publications"},
+	{"programming language", "skills"},
+	{"language", "languages"},
+	{"skill", "skills"},
+	{"technolog", "skills"},
+	{"competenc", "skills"},
+	{"interest", "interests"},
+	{"hobb", "interests"},
+	{"reference", "references"},
+	{"experience", "work"},
+	{"employment", "work"},
+	{"work", "work"},
+	{"career", "work"},
+}
+
+func sectionKind(title string) string {
+	title = strings.ToLower(title)
+	for _, k := range sectionKinds {
+		if strings.Contains(title, k.word) {
+			return k.kind
+		}
+	}
+	return ""
+}
+
+// entry is a ### heading and the blocks under it
+type entry struct {
+	title  string
+	blocks []export.Block
+}
+
+// FromDocument reads a markdown resume into JSON Resume sections. Sections
+// are recognized by their ## heading; ones the schema has no place for are
+// kept in meta.sections as markdown.
+func FromDocument(doc export.Document) Resume {
+	var r Resume
+	basics := &Basics{}
+
+	// Split the document at ## headings; the part before the first one is
+	// the header with the name and contact details
+	type section struct {
+		title  string
+		blocks []export.Block
+	}
+	var header []export.Block
+	var sections []section
+	for _, b := range doc.Blocks {
+		switch {
+		case b.Kind == export.Heading && b.Level == 1 && basics.Name == "" && len(sections) == 0:
+			basics.Name = plain(b.Text)
+		case b.Kind == export.Heading && b.Level <= 2:
+			// Later level-1 headings are taken as sections too
+			sections = append(sections, section{title: plain(b.Text)})
+		case len(sections) == 0:
+			header = append(header, b)
+		default:
+			s := &sections[len(sections)-1]
+			s.blocks = append(s.blocks, b)
+		}
+	}
+
+	readHeader(basics, header)
+
+	for _, s := range sections {
+		switch sectionKind(s.title) {
+		case "summary":
+			basics.Summary = joinNonEmpty("\n\n", basics.Summary, blocksMarkdown(s.blocks))
+		case "work":
+			for _, e := range entries(s.blocks) {
+				r.Work = append(r.Work, readWork(e))
+			}
+		case "volunteer":
+			for _, e := range entries(s.blocks) {
+				w := readWork(e)
+				r.Volunteer = append(r.Volunteer, Volunteer{
+					Organization: w.Name, Position: w.Position, URL: w.URL,
+					StartDate: w.StartDate, EndDate: w.EndDate,
+					Summary: joinNonEmpty("\n\n", w.Description, w.Summary), Highlights: w.Highlights,
+				})
+			}
+		case "education":
+			for _, e := range entries(s.blocks) {
+				r.Education = append(r.Education, readEducation(e))
+			}
+		case "projects":
+			for _, e := range entries(s.blocks) {
+				r.Projects = append(r.Projects, readProject(e))
+			}
+		case "skills":
+			for _, it := range items(s.blocks) {
+				skill := Skill{Name: it.name, Level: it.paren, Keywords: keywords(joinNonEmpty(", ", it.secondary, it.rest))}
+				if it.plain && it.secondary == "" && it.rest == "" && strings.Contains(it.name, ",") {
+					// A bare list such as "Go, Python, SQL"
+					skill = Skill{Level: it.paren, Keywords: keywords(it.name)}
+				}
+				r.Skills = append(r.Skills, skill)
+			}
+		case "languages":
+			for _, it := range items(s.blocks) {
+				r.Languages = append(r.Languages, Language{Language: it.name, Fluency: firstOf(it.paren, plain(it.rest))})
+			}
+		case "interests":
+			for _, it := range items(s.blocks) {
+				r.Interests = append(r.Interests, Interest{Name: it.name, Keywords: keywords(it.rest)})
+			}
+		case "references":
+			for _, it := range items(s.blocks) {
+				r.References = append(r.References, Reference{Name: it.name, Reference: it.rest})
+			}
+		case "awards":
+			for _, it := range items(s.blocks) {
+				date, awarder := itemDate(it)
+				r.Awards = append(r.Awards, Award{Title: it.name, Date: date, Awarder: joinNonEmpty(", ", it.secondary, awarder), Summary: it.rest})
+			}
+		case "certificates":
+			for _, it := range items(s.blocks) {
+				date, issuer := itemDate(it)
+				r.Certificates = append(r.Certificates, Certificate{Name: it.name, URL: it.url, Issuer: joinNonEmpty(", ", it.secondary, issuer), Date: date})
+			}
+		case "publications":
+			for _, it := range items(s.blocks) {
+				date, publisher := itemDate(it)
+				r.Publications = append(r.Publications, Publication{
+					Name: it.name, URL: it.url, Publisher: joinNonEmpty(", ", it.secondary, publisher),
+					ReleaseDate: date, Summary: it.rest,
+				})
+			}
+		default:
+			if r.Meta == nil {
+				r.Meta = &Meta{}
+			}
+			r.Meta.Sections = append(r.Meta.Sections, Section{Title: s.title, Content: blocksMarkdown(s.blocks)})
+		}
+	}
+
+	if basics.Name != "" || basics.Label != "" || basics.Email != "" || basics.Phone != "" ||
+		basics.URL != "" || basics.Summary != "" || basics.Location != nil || len(basics.Profiles) > 0 {
+		r.Basics = basics
+	}
+	return r
+}
+
+var (
+	emailRe    = regexp.MustCompile(`^[^\s@<>()]+@[^\s@<>()]+\.[^\s@<>()]+$`)
+	phoneRe    = regexp.MustCompile(`^\+?[\d\s().-]+$`)
+	linkOnlyRe = regexp.MustCompile(`^\[([^\]]+)\]\(([^)\s]+)\)$`)
+	bareURLRe  = regexp.MustCompile(`^(?i)(https?://)?(www\.)?[a-z0-9-]+(\.[a-z0-9-]+)*\.[a-z]{2,}(/\S*)?$`)
+	contactSep = regexp.MustCompile(`\s+[|·•⋅]\s+`)
+	// locationRe matches a short "City, Region" line
+	locationRe = regexp.MustCompile(`^[A-Z][\pL .'-]+(, [\pL\d .'-]+){1,3}$`)
+	postalRe   = regexp.MustCompile(`^(.*?)\s+(\d{4,6}(?:-\d{4})?|[A-Z]\d[A-Z] ?\d[A-Z]\d)$`)
+)
+
+// networks names profile sites by host
+var networks = map[string]string{
+	"linkedin.com":      "LinkedIn",
+	"github.com":        "GitHub",
+	"gitlab.com":        "GitLab",
+	"twitter.com":       "Twitter",
+	"x.com":             "X",
+	"stackoverflow.com": "Stack Overflow",
+	"medium.com":        "Medium",
+	"dribbble.com":      "Dribbble",
+	"behance.net":       "Behance",
+	"kaggle.com":        "Kaggle",
+	"bitbucket.org":     "Bitbucket",
+}
+
+// readHeader fills in the label, contact details, profiles and any summary
+// text from the lines between the name and the first section
+func readHeader(basics *Basics, blocks []export.Block) {
+	var summary []string
+	for _, b := range blocks {
+		if b.Kind != export.Paragraph && b.Kind != export.ListItem {
+			continue
+		}
+		for _, line := range strings.Split(b.Text, "\n") {
+			line = strings.TrimSpace(line)
+			parts := contactSep.Split(line, -1)
+			var rest []string
+			for _, part := range parts {
+				if !readContact(basics, part) {
+					rest = append(rest, part)
+				}
+			}
+
+			if len(rest) == len(parts) {
+				// Not a contact line: a label, a location or summary text
+				text := plain(line)
+				switch {
+				case basics.Label == "" && len(summary) == 0 && len(parts) == 1 && len(text) <= 100:
+					basics.Label = text
+				case basics.Location == nil && locationRe.MatchString(text) && len(text) <= 60:
+					basics.Location = parseLocation(text)
+				default:
+					summary = append(summary, line)
+				}
+				continue
+			}
+			// What's left on a contact line is usually the location
+			for _, part := range rest {
+				if text := plain(part); basics.Location == nil && len(text) <= 60 {
+					basics.Location = parseLocation(text)
+				} else {
+					summary = append(summary, part)
+				}
+			}
+		}
+	}
+	basics.Summary = strings.Join(summary, "\n")
+}
+
+// readContact takes an email, phone number, URL or profile from one part
+// of a contact line, reporting whether it was one
+func readContact(basics *Basics, part string) bool {
+	text, href := plain(part), ""
+	if m := linkOnlyRe.FindStringSubmatch(part); m != nil {
+		text, href = plain(m[1]), m[2]
+	} else if bareURLRe.MatchString(text) && !emailRe.MatchString(text) {
+		href = text
+	}
+
+	switch {
+	case strings.HasPrefix(href, "mailto:"):
+		text, href = strings.TrimPrefix(href, "mailto:"), ""
+		fallthrough
+	case href == "" && emailRe.MatchString(text):
+		if basics.Email == "" && validEmail(text) {
+			basics.Email = text
+			return true
+		}
+		return false
+	case href == "" && phoneRe.MatchString(text) && countDigits(text) >= 7:
+		if basics.Phone == "" {
+			basics.Phone = text
+			return true
+		}
+		return false
+	case href != "":
+		if !strings.Contains(href, "://") {
+			href = "https://" + href
+		}
+		u, err := url.Parse(href)
+		if err != nil || u.Host == "" {
+			return false
+		}
+		host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+		if network, ok := networks[host]; ok {
+			basics.Profiles = append(basics.Profiles, Profile{Network: network, Username: username(u), URL: href})
+			return true
+		}
+		if basics.URL == "" {
+			basics.URL = href
+			return true
+		}
+		basics.Profiles = append(basics.Profiles, Profile{Network: text, Username: username(u), URL: href})
+		return true
+	}
+
+	// "GitHub: jdoe"
+	if network, user, ok := strings.Cut(text, ": "); ok && !strings.Contains(user, " ") {
+		for _, name := range networks {
+			if strings.EqualFold(name, network) {
+				basics.Profiles = append(basics.Profiles, Profile{Network: name, Username: user})
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// username takes the last path segment of a profile URL, e.g. "jdoe" from
+// https://www.linkedin.com/in/jdoe/
+func username(u *url.URL) string {
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+	if len(segments) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(segments[len(segments)-1], "@")
+}
+
+// parseLocation reads "Address, City, Region Postcode, CC"; shorter forms
+// drop parts from the left
+func parseLocation(text string) *Location {
+	parts := strings.Split(text, ", ")
+	loc := &Location{}
+	if n := len(parts); n >= 3 && len(parts[n-1]) == 2 && strings.ToUpper(parts[n-1]) == parts[n-1] {
+		loc.CountryCode = parts[n-1]
+		parts = parts[:n-1]
+	}
+	if n := len(parts); n >= 2 {
+		loc.Region = parts[n-1]
+		if m := postalRe.FindStringSubmatch(loc.Region); m != nil {
+			loc.Region, loc.PostalCode = m[1], m[2]
+		}
+		parts = parts[:n-1]
+	}
+	if n := len(parts); n >= 1 {
+		loc.City = parts[n-1]
+		loc.Address = strings.Join(parts[:n-1], ", ")
+	}
+	return loc
+}
+
+// entries groups a section's blocks under its ### headings. Blocks before
+// the first heading form an untitled entry.
+func entries(blocks []export.Block) []entry {
+	var out []entry
+	for _, b := range blocks {
+		if b.Kind == export.Heading && b.Level == 3 {
+			out = append(out, entry{title: plain(b.Text)})
+			continue
+		}
+		if len(out) == 0 {
+			out = append(out, entry{})
+		}
+		out[len(out)-1].blocks = append(out[len(out)-1].blocks, b)
+	}
+	return out
+}
+
+// entryText is an entry's content sorted into the parts every dated entry
+// has
+type entryText struct {
+	start, end, location, url string
+	// role is a bold line under the heading, e.g. a position when the
+	// heading is the company
+	role string
+	// italic is a paragraph set wholly in italics
+	italic     string
+	paragraphs []string
+	list       []string
+	// labeled holds "Label: value" lines by lowercase label
+	labeled map[string]string
+}
+
+// readEntry sorts an entry's blocks. Lines starting with one of labels and
+// ": " are kept in labeled.
+func readEntry(e entry, labels ...string) entryText {
+	t := entryText{labeled: map[string]string{}}
+	datesFound := false
+	for _, b := range e.blocks {
+		switch b.Kind {
+		case export.ListItem:
+			t.list = append(t.list, b.Text)
+		case export.Paragraph, export.Heading:
+			var para []string
+			for _, line := range strings.Split(b.Text, "\n") {
+				line = strings.TrimSpace(line)
+				if !datesFound && t.readMeta(line) {
+					datesFound = true
+					continue
+				}
+				if label, value, ok := strings.Cut(plain(line), ": "); ok && containsFold(labels, label) {
+					t.labeled[strings.ToLower(label)] = value
+					continue
+				}
+				if spans := export.Spans(line); len(spans) == 1 && spans[0].Bold && t.role == "" && len(para) == 0 {
+					t.role = spans[0].Text
+					continue
+				}
+				para = append(para, line)
+			}
+			text := strings.Join(para, "\n")
+			if spans := export.Spans(text); len(spans) == 1 && spans[0].Italic && t.italic == "" && len(t.paragraphs) == 0 {
+				t.italic = spans[0].Text
+				continue
+			}
+			if text != "" {
+				t.paragraphs = append(t.paragraphs, text)
+			}
+		}
+	}
+	return t
+}
+
+// readMeta reads a date line such as "Jan 2020 – Present | Remote |
+// [acme.com](https://acme.com)", reporting whether line was one. An undated
+// entry's meta line is just the link.
+func (t *entryText) readMeta(line string) bool {
+	parts := contactSep.Split(line, -1)
+	dates := -1
+	for i, part := range parts {
+		if start, end, ok := parseRange(plain(part)); ok {
+			t.start, t.end, dates = start, end, i
+			break
+		}
+	}
+	if dates < 0 {
+		if m := linkOnlyRe.FindStringSubmatch(line); m != nil && validURI(m[2]) {
+			t.url = m[2]
+			return true
+		}
+		return false
+	}
+	for i, part := range parts {
+		if i == dates {
+			continue
+		}
+		if m := linkOnlyRe.FindStringSubmatch(part); m != nil && validURI(m[2]) && t.url == "" {
+			t.url = m[2]
+			continue
+		}
+		if spans := export.Spans(part); len(spans) == 1 && spans[0].Bold && t.role == "" {
+			t.role = spans[0].Text
+			continue
+		}
+		t.location = joinNonEmpty(", ", t.location, plain(part))
+	}
+	return true
+}
+
+func readWork(e entry) Work {
+	t := readEntry(e)
+	position, name := splitTitle(e.title)
+	if t.role != "" {
+		if name == "" {
+			name = position
+		}
+		position = t.role
+	}
+	return Work{
+		Name: name, Position: position, Location: t.location, URL: t.url,
+		StartDate: t.start, EndDate: t.end,
+		Description: t.italic, Summary: strings.Join(t.paragraphs, "\n\n"), Highlights: t.list,
+	}
+}
+
+func readEducation(e entry) Education {
+	t := readEntry(e, "score", "gpa")
+	ed := Education{
+		Institution: e.title, URL: t.url, StartDate: t.start, EndDate: t.end,
+		Score: firstOf(t.labeled["score"], t.labeled["gpa"]), Courses: t.list,
+	}
+	degree := firstOf(t.role, t.italic)
+	if degree == "" && len(t.paragraphs) > 0 {
+		degree = plain(t.paragraphs[0])
+	}
+	if degree == "" {
+		// "B.S. in Computer Science | 2014" puts the degree on the date line
+		degree = t.location
+	}
+	if studyType, area, ok := strings.Cut(degree, " in "); ok {
+		ed.StudyType, ed.Area = studyType, area
+	} else if studyType, area, ok := strings.Cut(degree, ", "); ok {
+		ed.StudyType, ed.Area = studyType, area
+	} else {
+		ed.StudyType = degree
+	}
+	return ed
+}
+
+func readProject(e entry) Project {
+	t := readEntry(e, "roles", "keywords", "technologies", "tech stack", "stack", "entity", "type")
+	description := t.paragraphs
+	if t.italic != "" {
+		description = append([]string{t.italic}, description...)
+	}
+	return Project{
+		Name: e.title, URL: t.url, StartDate: t.start, EndDate: t.end,
+		Description: strings.Join(description, "\n\n"), Highlights: t.list,
+		Roles:    keywords(firstOf(t.labeled["roles"], t.role)),
+		Keywords: keywords(firstOf(t.labeled["keywords"], t.labeled["technologies"], t.labeled["tech stack"], t.labeled["stack"])),
+		Entity:   t.labeled["entity"],
+		Type:     t.labeled["type"],
+	}
+}
+
+// titleSeps split an entry heading into position and organization
+var titleSeps = []string{" at ", " @ ", " — ", " – ", " | ", ", "}
+
+func splitTitle(title string) (position, org string) {
+	for _, sep := range titleSeps {
+		if p, o, ok := strings.Cut(title, sep); ok {
+			return strings.TrimSpace(p), strings.TrimSpace(o)
+		}
+	}
+	return title, ""
+}
+
+// listItem is a one-line entry such as "**AWS Solutions Architect**, Amazon
+// (2021)" or "Go, Python, SQL"
+type listItem struct {
+	name, url, secondary, paren, rest string
+	// plain is set when the name wasn't bold or a link
+	plain bool
+}
+
+var itemRe = regexp.MustCompile(`^(?:\*\*(.+?)\*\*|\[([^\]]+)\]\(([^)\s]+)\))(?:,\s*([^():]+?))?(?:\s*\(([^()]*)\))?(?::\s*(.*))?$`)
+
+var parenRe = regexp.MustCompile(`^(.+?)\s*\(([^()]*)\)$`)
+
+// boldColonRe matches "**Label:**", which is written as "**Label**:"
+var boldColonRe = regexp.MustCompile(`^\*\*([^*]+?):\*\*`)
+
+// items reads each list item, and each line of any paragraph, as a
+// listItem
+func items(blocks []export.Block) []listItem {
+	var out []listItem
+	for _, b := range blocks {
+		var lines []string
+		switch b.Kind {
+		case export.ListItem:
+			lines = []string{b.Text}
+		case export.Paragraph, export.Heading:
+			lines = strings.Split(b.Text, "\n")
+		}
+		for _, line := range lines {
+			line = boldColonRe.ReplaceAllString(strings.TrimSpace(line), "**$1**:")
+			if line == "" {
+				continue
+			}
+			if m := itemRe.FindStringSubmatch(line); m != nil {
+				it := listItem{name: plain(m[1] + m[2]), secondary: plain(m[4]), paren: plain(m[5]), rest: m[6]}
+				if validURI(m[3]) {
+					it.url = m[3]
+				}
+				out = append(out, it)
+				continue
+			}
+			// "Languages: Go, Python", "English (Native)", "English - Native"
+			// or just text
+			it := listItem{name: plain(line), plain: true}
+			if name, rest, ok := strings.Cut(line, ": "); ok {
+				it.name, it.rest = plain(name), rest
+			} else if m := parenRe.FindStringSubmatch(it.name); m != nil {
+				it.name, it.paren = m[1], m[2]
+			} else if name, rest, ok := strings.Cut(it.name, " - "); ok {
+				it.name, it.rest = name, rest
+			}
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// itemDate reads the date in an item's parentheses; anything else there is
+// returned as extra text
+func itemDate(it listItem) (date, other string) {
+	if it.paren == "" {
+		return "", ""
+	}
+	if d, ok := ParseDate(it.paren); ok && d != "" {
+		return d, ""
+	}
+	return "", it.paren
+}
+
+// keywords splits a comma or semicolon separated list
+func keywords(s string) []string {
+	var out []string
+	for _, k := range strings.FieldsFunc(plain(s), func(r rune) bool { return r == ',' || r == ';' }) {
+		if k = strings.TrimSpace(k); k != "" {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+var rangeSepRe = regexp.MustCompile(`\s*[–—]\s*|\s+-\s+|\s+to\s+`)
+
+// parseRange reads "Mar 2020 – Present" or a single date as ISO 8601 start
+// and end dates; an open end is ""
+func parseRange(s string) (start, end string, ok bool) {
+	parts := rangeSepRe.Split(strings.TrimSpace(s), -1)
+	switch len(parts) {
+	case 1:
+		d, ok := ParseDate(parts[0])
+		if !ok || d == "" {
+			return "", "", false
+		}
+		return d, d, true
+	case 2:
+		start, ok1 := ParseDate(parts[0])
+		end, ok2 := ParseDate(parts[1])
+		if !ok1 || !ok2 || start == "" {
+			return "", "", false
+		}
+		return start, end, true
+	}
+	return "", "", false
+}
+
+var (
+	presentWords  = []string{"present", "current", "now", "today", "ongoing"}
+	numericDateRe = regexp.MustCompile(`^(\d{1,2})/(\d{4})$`)
+	dateLayouts   = []struct{ layout, format string }{
+		{"Jan 2, 2006", "2006-01-02"},
+		{"January 2, 2006", "2006-01-02"},
+		{"Jan 2006", "2006-01"},
+		{"January 2006", "2006-01"},
+		{"Jan. 2006", "2006-01"},
+		{"2006-01-02", "2006-01-02"},
+		{"2006-01", "2006-01"},
+		{"2006", "2006"},
+	}
+)
+
+// ParseDate reads a date as ISO 8601 at its own precision. "Present" and
+// similar parse as "".
+func ParseDate(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if containsFold(presentWords, s) {
+		return "", true
+	}
+	if m := numericDateRe.FindStringSubmatch(s); m != nil {
+		month, _ := strconv.Atoi(m[1])
+		if month >= 1 && month <= 12 {
+			return m[2] + "-" + twoDigits(month), true
+		}
+	}
+	// "Sept" is common but not a Go layout month
+	s = strings.Replace(s, "Sept ", "Sep ", 1)
+	for _, l := range dateLayouts {
+		if t, err := time.Parse(l.layout, s); err == nil && dateRe.MatchString(t.Format(l.format)) {
+			return t.Format(l.format), true
+		}
+	}
+	return "", false
+}
+
+func twoDigits(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
+
+// blocksMarkdown writes blocks back out as markdown
+func blocksMarkdown(blocks []export.Block) string {
+	var b strings.Builder
+	for i, block := range blocks {
+		if i > 0 {
+			if block.Kind == export.ListItem && blocks[i-1].Kind == export.ListItem {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		switch block.Kind {
+		case export.Heading:
+			b.WriteString(strings.Repeat("#", block.Level) + " " + block.Text)
+		case export.ListItem:
+			b.WriteString(strings.Repeat("  ", block.Level))
+			if block.Ordered {
+				b.WriteString(strconv.Itoa(block.Number) + ". ")
+			} else {
+				b.WriteString("- ")
+			}
+			b.WriteString(block.Text)
+		case export.Rule:
+			b.WriteString("---")
+		case export.Code:
+			b.WriteString("```\n" + block.Text + "\n```")
+		default:
+			b.WriteString(block.Text)
+		}
+	}
+	return b.String()
+}
+
+// plain strips inline markdown and surrounding space
+func plain(s string) string {
+	return strings.TrimSpace(export.PlainText(s))
+}
+
+func countDigits(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
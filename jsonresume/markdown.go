@@ -0,0 +1,359 @@
+package jsonresume
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Section titles used in the markdown, in the order they're written
+const (
+	titleSummary      = "Summary"
+	titleWork         = "Experience"
+	titleVolunteer    = "Volunteering"
+	titleEducation    = "Education"
+	titleSkills       = "Skills"
+	titleProjects     = "Projects"
+	titleAwards       = "Awards"
+	titleCertificates = "Certificates"
+	titlePublications = "Publications"
+	titleLanguages    = "Languages"
+	titleInterests    = "Interests"
+	titleReferences   = "References"
+)
+
+// Markdown renders a resume as the markdown the tweaker consumes. Entries
+// with dates (work, volunteering, education, projects) get a ### heading and
+// a date line; shorter items become one list item each.
+func Markdown(r Resume) string {
+	var b mdBuilder
+
+	if basics := r.Basics; basics != nil {
+		if basics.Name != "" {
+			b.block("# " + oneLine(basics.Name))
+		}
+		if basics.Label != "" {
+			b.block(oneLine(basics.Label))
+		}
+		var contact []string
+		for _, s := range []string{basics.Email, basics.Phone} {
+			if s != "" {
+				contact = append(contact, s)
+			}
+		}
+		if basics.URL != "" {
+			contact = append(contact, mdLink(displayURL(basics.URL), basics.URL))
+		}
+		if loc := locationText(basics.Location); loc != "" {
+			contact = append(contact, loc)
+		}
+		b.line(contact)
+
+		var profiles []string
+		for _, p := range basics.Profiles {
+			switch {
+			case p.URL != "":
+				profiles = append(profiles, mdLink(firstOf(p.Network, p.Username, displayURL(p.URL)), p.URL))
+			case p.Network != "" && p.Username != "":
+				profiles = append(profiles, p.Network+": "+p.Username)
+			}
+		}
+		b.line(profiles)
+
+		if basics.Summary != "" {
+			b.section(titleSummary)
+			b.block(basics.Summary)
+		}
+	}
+
+	if len(r.Work) > 0 {
+		b.section(titleWork)
+		for _, w := range r.Work {
+			b.entry(joinNonEmpty(" at ", w.Position, w.Name), dateRange(w.StartDate, w.EndDate), w.Location, w.URL)
+			if w.Description != "" {
+				b.block("*" + oneLine(w.Description) + "*")
+			}
+			b.block(w.Summary)
+			b.list(w.Highlights)
+		}
+	}
+
+	if len(r.Volunteer) > 0 {
+		b.section(titleVolunteer)
+		for _, v := range r.Volunteer {
+			b.entry(joinNonEmpty(" at ", v.Position, v.Organization), dateRange(v.StartDate, v.EndDate), "", v.URL)
+			b.block(v.Summary)
+			b.list(v.Highlights)
+		}
+	}
+
+	if len(r.Education) > 0 {
+		b.section(titleEducation)
+		for _, e := range r.Education {
+			b.entry(e.Institution, dateRange(e.StartDate, e.EndDate), "", e.URL)
+			b.block(oneLine(joinNonEmpty(" in ", e.StudyType, e.Area)))
+			if e.Score != "" {
+				b.block("Score: " + oneLine(e.Score))
+			}
+			b.list(e.Courses)
+		}
+	}
+
+	if len(r.Skills) > 0 {
+		b.section(titleSkills)
+		var items []string
+		for _, s := range r.Skills {
+			items = append(items, item(s.Name, "", "", s.Level, strings.Join(s.Keywords, ", ")))
+		}
+		b.list(items)
+	}
+
+	if len(r.Projects) > 0 {
+		b.section(titleProjects)
+		for _, p := range r.Projects {
+			b.entry(p.Name, dateRange(p.StartDate, p.EndDate), "", p.URL)
+			b.block(p.Description)
+			b.list(p.Highlights)
+			var details []string
+			for _, d := range []struct{ label, value string }{
+				{"Roles", strings.Join(p.Roles, ", ")},
+				{"Keywords", strings.Join(p.Keywords, ", ")},
+				{"Entity", p.Entity},
+				{"Type", p.Type},
+			} {
+				if d.value != "" {
+					details = append(details, d.label+": "+oneLine(d.value))
+				}
+			}
+			b.block(strings.Join(details, "\n"))
+		}
+	}
+
+	if len(r.Awards) > 0 {
+		b.section(titleAwards)
+		var items []string
+		for _, a := range r.Awards {
+			items = append(items, item(a.Title, "", a.Awarder, displayDate(a.Date), a.Summary))
+		}
+		b.list(items)
+	}
+
+	if len(r.Certificates) > 0 {
+		b.section(titleCertificates)
+		var items []string
+		for _, c := range r.Certificates {
+			items = append(items, item(c.Name, c.URL, c.Issuer, displayDate(c.Date), ""))
+		}
+		b.list(items)
+	}
+
+	if len(r.Publications) > 0 {
+		b.section(titlePublications)
+		var items []string
+		for _, p := range r.Publications {
+			items = append(items, item(p.Name, p.URL, p.Publisher, displayDate(p.ReleaseDate), p.Summary))
+		}
+		b.list(items)
+	}
+
+	if len(r.Languages) > 0 {
+		b.section(titleLanguages)
+		var items []string
+		for _, l := range r.Languages {
+			items = append(items, item(l.Language, "", "", l.Fluency, ""))
+		}
+		b.list(items)
+	}
+
+	if len(r.Interests) > 0 {
+		b.section(titleInterests)
+		var items []string
+		for _, i := range r.Interests {
+			items = append(items, item(i.Name, "", "", "", strings.Join(i.Keywords, ", ")))
+		}
+		b.list(items)
+	}
+
+	if len(r.References) > 0 {
+		b.section(titleReferences)
+		var items []string
+		for _, ref := range r.References {
+			items = append(items, item(ref.Name, "", "", "", ref.Reference))
+		}
+		b.list(items)
+	}
+
+	if r.Meta != nil {
+		for _, s := range r.Meta.Sections {
+			b.section(s.Title)
+			b.block(s.Content)
+		}
+	}
+
+	return b.String()
+}
+
+// mdBuilder collects markdown blocks separated by blank lines
+type mdBuilder struct {
+	blocks []string
+}
+
+func (b *mdBuilder) block(text string) {
+	if text = strings.TrimSpace(text); text != "" {
+		b.blocks = append(b.blocks, text)
+	}
+}
+
+func (b *mdBuilder) section(title string) {
+	b.block("## " + oneLine(title))
+}
+
+// line writes parts on one line separated by " | "
+func (b *mdBuilder) line(parts []string) {
+	b.block(strings.Join(parts, " | "))
+}
+
+// entry writes a ### heading and its date line
+func (b *mdBuilder) entry(title, dates, location, url string) {
+	b.block("### " + oneLine(title))
+	var meta []string
+	for _, s := range []string{dates, oneLine(location)} {
+		if s != "" {
+			meta = append(meta, s)
+		}
+	}
+	if url != "" {
+		meta = append(meta, mdLink(displayURL(url), url))
+	}
+	b.line(meta)
+}
+
+func (b *mdBuilder) list(items []string) {
+	var lines []string
+	for _, s := range items {
+		if s = oneLine(s); s != "" {
+			lines = append(lines, "- "+s)
+		}
+	}
+	b.block(strings.Join(lines, "\n"))
+}
+
+func (b *mdBuilder) String() string {
+	return strings.Join(b.blocks, "\n\n") + "\n"
+}
+
+// item formats a short entry as "**name**, secondary (paren): rest", with
+// the name linked when url is set
+func item(name, url, secondary, paren, rest string) string {
+	var s string
+	switch {
+	case name != "" && url != "":
+		s = mdLink(oneLine(name), url)
+	case name != "":
+		s = "**" + oneLine(name) + "**"
+	}
+	if secondary != "" {
+		s = joinNonEmpty(", ", s, oneLine(secondary))
+	}
+	if paren != "" {
+		s = joinNonEmpty(" ", s, "("+oneLine(paren)+")")
+	}
+	if rest != "" {
+		if s != "" {
+			s += ": "
+		}
+		s += oneLine(rest)
+	}
+	return s
+}
+
+// linkEscaper encodes the characters that would end a markdown link target
+var linkEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29")
+
+func mdLink(text, url string) string {
+	return "[" + text + "](" + linkEscaper.Replace(url) + ")"
+}
+
+// displayURL drops the scheme and trailing slash for display
+func displayURL(url string) string {
+	for _, prefix := range []string{"https://", "http://", "mailto:"} {
+		url = strings.TrimPrefix(url, prefix)
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(url, "www."), "/")
+}
+
+func locationText(l *Location) string {
+	if l == nil {
+		return ""
+	}
+	region := joinNonEmpty(" ", l.Region, l.PostalCode)
+	var parts []string
+	for _, s := range []string{l.Address, l.City, region, l.CountryCode} {
+		if s = oneLine(s); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+var monthNames = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+// displayDate formats an ISO 8601 date as "2020", "Mar 2020" or
+// "Mar 5, 2020"
+func displayDate(iso string) string {
+	parts := strings.Split(iso, "-")
+	if len(parts) < 2 {
+		return iso
+	}
+	month, err := strconv.Atoi(parts[1])
+	if err != nil || month < 1 || month > 12 {
+		return iso
+	}
+	if len(parts) == 3 {
+		day, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return iso
+		}
+		return fmt.Sprintf("%s %d, %s", monthNames[month-1], day, parts[0])
+	}
+	return monthNames[month-1] + " " + parts[0]
+}
+
+// dateRange formats a start and end date; an open end is "Present"
+func dateRange(start, end string) string {
+	switch {
+	case start == "" && end == "":
+		return ""
+	case start == "":
+		return displayDate(end)
+	case start == end:
+		return displayDate(start)
+	case end == "":
+		return displayDate(start) + " – Present"
+	}
+	return displayDate(start) + " – " + displayDate(end)
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var out []string
+	for _, s := range parts {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return strings.Join(out, sep)
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// oneLine collapses whitespace, including newlines, to single spaces
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,160 @@
+// Package jsonresume converts between JSON Resume (https://jsonresume.org,
+// schema v1.0.0) and the markdown resumes the tweaker works on.
+//
+// Import validates a resume.json against the schema and renders it as
+// markdown; FromDocument reads a tweaked markdown resume back into the
+// schema's sections. Markdown is laid out so the two round-trip: headings,
+// date lines and list items map onto fields one to one.
+package jsonresume
+
+// SchemaURL identifies the schema version in exported files
+const SchemaURL = "https://raw.githubusercontent.com/jsonresume/resume-schema/v1.0.0/schema.json"
+
+// Resume is a JSON Resume document
+type Resume struct {
+	Schema       string        `json:"$schema,omitempty"`
+	Basics       *Basics       `json:"basics,omitempty"`
+	Work         []Work        `json:"work,omitempty"`
+	Volunteer    []Volunteer   `json:"volunteer,omitempty"`
+	Education    []Education   `json:"education,omitempty"`
+	Awards       []Award       `json:"awards,omitempty"`
+	Certificates []Certificate `json:"certificates,omitempty"`
+	Publications []Publication `json:"publications,omitempty"`
+	Skills       []Skill       `json:"skills,omitempty"`
+	Languages    []Language    `json:"languages,omitempty"`
+	Interests    []Interest    `json:"interests,omitempty"`
+	References   []Reference   `json:"references,omitempty"`
+	Projects     []Project     `json:"projects,omitempty"`
+	Meta         *Meta         `json:"meta,omitempty"`
+}
+
+type Basics struct {
+	Name     string    `json:"name,omitempty"`
+	Label    string    `json:"label,omitempty"`
+	Image    string    `json:"image,omitempty"`
+	Email    string    `json:"email,omitempty"`
+	Phone    string    `json:"phone,omitempty"`
+	URL      string    `json:"url,omitempty"`
+	Summary  string    `json:"summary,omitempty"`
+	Location *Location `json:"location,omitempty"`
+	Profiles []Profile `json:"profiles,omitempty"`
+}
+
+type Location struct {
+	Address     string `json:"address,omitempty"`
+	PostalCode  string `json:"postalCode,omitempty"`
+	City        string `json:"city,omitempty"`
+	CountryCode string `json:"countryCode,omitempty"`
+	Region      string `json:"region,omitempty"`
+}
+
+type Profile struct {
+	Network  string `json:"network,omitempty"`
+	Username string `json:"username,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+type Work struct {
+	Name        string   `json:"name,omitempty"`
+	Location    string   `json:"location,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Position    string   `json:"position,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	StartDate   string   `json:"startDate,omitempty"`
+	EndDate     string   `json:"endDate,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	Highlights  []string `json:"highlights,omitempty"`
+}
+
+type Volunteer struct {
+	Organization string   `json:"organization,omitempty"`
+	Position     string   `json:"position,omitempty"`
+	URL          string   `json:"url,omitempty"`
+	StartDate    string   `json:"startDate,omitempty"`
+	EndDate      string   `json:"endDate,omitempty"`
+	Summary      string   `json:"summary,omitempty"`
+	Highlights   []string `json:"highlights,omitempty"`
+}
+
+type Education struct {
+	Institution string   `json:"institution,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Area        string   `json:"area,omitempty"`
+	StudyType   string   `json:"studyType,omitempty"`
+	StartDate   string   `json:"startDate,omitempty"`
+	EndDate     string   `json:"endDate,omitempty"`
+	Score       string   `json:"score,omitempty"`
+	Courses     []string `json:"courses,omitempty"`
+}
+
+type Award struct {
+	Title   string `json:"title,omitempty"`
+	Date    string `json:"date,omitempty"`
+	Awarder string `json:"awarder,omitempty"`
+	Summary string `json:"summary,omitempty"`
+}
+
+type Certificate struct {
+	Name   string `json:"name,omitempty"`
+	Date   string `json:"date,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Issuer string `json:"issuer,omitempty"`
+}
+
+type Publication struct {
+	Name        string `json:"name,omitempty"`
+	Publisher   string `json:"publisher,omitempty"`
+	ReleaseDate string `json:"releaseDate,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Summary     string `json:"summary,omitempty"`
+}
+
+type Skill struct {
+	Name     string   `json:"name,omitempty"`
+	Level    string   `json:"level,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+type Language struct {
+	Language string `json:"language,omitempty"`
+	Fluency  string `json:"fluency,omitempty"`
+}
+
+type Interest struct {
+	Name     string   `json:"name,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+type Reference struct {
+	Name      string `json:"name,omitempty"`
+	Reference string `json:"reference,omitempty"`
+}
+
+type Project struct {
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Highlights  []string `json:"highlights,omitempty"`
+	Keywords    []string `json:"keywords,omitempty"`
+	StartDate   string   `json:"startDate,omitempty"`
+	EndDate     string   `json:"endDate,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Entity      string   `json:"entity,omitempty"`
+	Type        string   `json:"type,omitempty"`
+}
+
+// Meta is the schema's metadata block. Sections carries any resume sections
+// the schema has no place for, so an export doesn't drop them; the schema
+// allows extra properties here.
+type Meta struct {
+	Canonical    string    `json:"canonical,omitempty"`
+	Version      string    `json:"version,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	Sections     []Section `json:"sections,omitempty"`
+}
+
+// Section is a titled block of markdown
+type Section struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
@@ -0,0 +1,272 @@
+package jsonresume
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxProblems caps how many problems a ValidationError reports
+const maxProblems = 10
+
+// ValidationError lists where a document breaks the schema, e.g.
+// `work[1].startDate: "March 2020" is not a date (use YYYY, YYYY-MM or YYYY-MM-DD)`
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid JSON Resume: " + strings.Join(e.Problems, "; ")
+}
+
+// kind is the type a schema property must have
+type kind int
+
+const (
+	kString kind = iota
+	kDate
+	kEmail
+	kURI
+	kStrings
+	kObject
+	kObjects
+)
+
+// prop describes one schema property; fields is set for objects
+type prop struct {
+	kind   kind
+	fields map[string]prop
+}
+
+var (
+	str  = prop{kind: kString}
+	date = prop{kind: kDate}
+	uri  = prop{kind: kURI}
+	strs = prop{kind: kStrings}
+)
+
+func object(fields map[string]prop) prop  { return prop{kind: kObject, fields: fields} }
+func objects(fields map[string]prop) prop { return prop{kind: kObjects, fields: fields} }
+
+// schema mirrors resume-schema v1.0.0. Only the top level rejects unknown
+// properties, as the schema does.
+var schema = map[string]prop{
+	"$schema": uri,
+	"basics": object(map[string]prop{
+		"name": str, "label": str, "image": str, "email": {kind: kEmail}, "phone": str,
+		"url": uri, "summary": str,
+		"location": object(map[string]prop{
+			"address": str, "postalCode": str, "city": str, "countryCode": str, "region": str,
+		}),
+		"profiles": objects(map[string]prop{"network": str, "username": str, "url": uri}),
+	}),
+	"work": objects(map[string]prop{
+		"name": str, "location": str, "description": str, "position": str, "url": uri,
+		"startDate": date, "endDate": date, "summary": str, "highlights": strs,
+	}),
+	"volunteer": objects(map[string]prop{
+		"organization": str, "position": str, "url": uri,
+		"startDate": date, "endDate": date, "summary": str, "highlights": strs,
+	}),
+	"education": objects(map[string]prop{
+		"institution": str, "url": uri, "area": str, "studyType": str,
+		"startDate": date, "endDate": date, "score": str, "courses": strs,
+	}),
+	"awards":       objects(map[string]prop{"title": str, "date": date, "awarder": str, "summary": str}),
+	"certificates": objects(map[string]prop{"name": str, "date": date, "url": uri, "issuer": str}),
+	"publications": objects(map[string]prop{
+		"name": str, "publisher": str, "releaseDate": date, "url": uri, "summary": str,
+	}),
+	"skills":     objects(map[string]prop{"name": str, "level": str, "keywords": strs}),
+	"languages":  objects(map[string]prop{"language": str, "fluency": str}),
+	"interests":  objects(map[string]prop{"name": str, "keywords": strs}),
+	"references": objects(map[string]prop{"name": str, "reference": str}),
+	"projects": objects(map[string]prop{
+		"name": str, "description": str, "highlights": strs, "keywords": strs,
+		"startDate": date, "endDate": date, "url": uri, "roles": strs, "entity": str, "type": str,
+	}),
+	"meta": object(map[string]prop{"canonical": uri, "version": str, "lastModified": str}),
+}
+
+// misnamed suggests the schema's name for sections people often call
+// something else
+var misnamed = map[string]string{
+	"experience":     "work",
+	"employment":     "work",
+	"jobs":           "work",
+	"skill":          "skills",
+	"certifications": "certificates",
+	"project":        "projects",
+	"basic":          "basics",
+}
+
+// dateRe is the schema's iso8601 pattern
+var dateRe = regexp.MustCompile(`^([1-2][0-9]{3}-[0-1][0-9]-[0-3][0-9]|[1-2][0-9]{3}-[0-1][0-9]|[1-2][0-9]{3})$`)
+
+// Parse validates a resume.json against the schema and decodes it. Schema
+// violations come back as a *ValidationError.
+func Parse(data []byte) (Resume, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return Resume{}, &ValidationError{Problems: []string{"not valid JSON: " + err.Error()}}
+	}
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return Resume{}, &ValidationError{Problems: []string{"the top level must be an object"}}
+	}
+
+	var problems []string
+	for _, key := range sortedKeys(root) {
+		p, ok := schema[key]
+		if !ok {
+			msg := fmt.Sprintf("unknown section %q", key)
+			if name, ok := misnamed[strings.ToLower(key)]; ok {
+				msg += fmt.Sprintf(" (did you mean %q?)", name)
+			}
+			problems = append(problems, msg)
+			continue
+		}
+		check(key, root[key], p, &problems)
+	}
+	if len(problems) > 0 {
+		if len(problems) > maxProblems {
+			more := len(problems) - maxProblems
+			problems = append(problems[:maxProblems], fmt.Sprintf("and %d more", more))
+		}
+		return Resume{}, &ValidationError{Problems: problems}
+	}
+
+	var r Resume
+	if err := json.Unmarshal(data, &r); err != nil {
+		return Resume{}, &ValidationError{Problems: []string{err.Error()}}
+	}
+	return r, nil
+}
+
+// Validate checks a Resume against the schema, e.g. before exporting it
+func Validate(r Resume) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = Parse(data)
+	return err
+}
+
+func check(path string, v any, p prop, problems *[]string) {
+	if v == nil {
+		// The schema's properties aren't nullable
+		*problems = append(*problems, path+": must not be null")
+		return
+	}
+	fail := func(format string, args ...any) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	switch p.kind {
+	case kString, kDate, kEmail, kURI:
+		s, ok := v.(string)
+		if !ok {
+			fail("must be a string, not %s", typeName(v))
+			return
+		}
+		switch {
+		case p.kind == kDate && !dateRe.MatchString(s):
+			fail("%q is not a date (use YYYY, YYYY-MM or YYYY-MM-DD)", s)
+		case p.kind == kEmail && !validEmail(s):
+			fail("%q is not an email address", s)
+		case p.kind == kURI && !validURI(s):
+			fail("%q is not a URL (include the scheme, e.g. https://)", s)
+		}
+
+	case kStrings:
+		list, ok := v.([]any)
+		if !ok {
+			fail("must be a list of strings, not %s", typeName(v))
+			return
+		}
+		for i, item := range list {
+			if _, ok := item.(string); !ok {
+				*problems = append(*problems, fmt.Sprintf("%s[%d]: must be a string, not %s", path, i, typeName(item)))
+			}
+		}
+
+	case kObject:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			fail("must be an object, not %s", typeName(v))
+			return
+		}
+		checkFields(path, obj, p.fields, problems)
+
+	case kObjects:
+		list, ok := v.([]any)
+		if !ok {
+			fail("must be a list, not %s", typeName(v))
+			return
+		}
+		for i, item := range list {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			obj, ok := item.(map[string]any)
+			if !ok {
+				*problems = append(*problems, fmt.Sprintf("%s: must be an object, not %s", itemPath, typeName(item)))
+				continue
+			}
+			checkFields(itemPath, obj, p.fields, problems)
+		}
+	}
+}
+
+// checkFields checks the known properties of an object; others are allowed
+func checkFields(path string, obj map[string]any, fields map[string]prop, problems *[]string) {
+	for _, key := range sortedKeys(obj) {
+		if p, ok := fields[key]; ok {
+			check(path+"."+key, obj[key], p, problems)
+		}
+	}
+}
+
+func validEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+func validURI(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != ""
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "a string"
+	case json.Number:
+		return "a number"
+	case bool:
+		return "true/false"
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "an object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,278 @@
+package jsonresume
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/johnhkchen/resume-tweaker/export"
+)
+
+// problems returns the problems Parse reports for doc, or fails
+func problems(t *testing.T, doc string) []string {
+	t.Helper()
+	_, err := Parse([]byte(doc))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Parse(%s) = %v, want a *ValidationError", doc, err)
+	}
+	return verr.Problems
+}
+
+func TestParseProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{
+			name: "string for a list",
+			doc:  `{"work": "Acme"}`,
+			want: []string{`work: must be a list, not a string`},
+		},
+		{
+			name: "number for a string",
+			doc:  `{"basics": {"name": 42}}`,
+			want: []string{`basics.name: must be a string, not a number`},
+		},
+		{
+			name: "object for a list of strings",
+			doc:  `{"skills": [{"name": "Go", "keywords": {"a": 1}}]}`,
+			want: []string{`skills[0].keywords: must be a list of strings, not an object`},
+		},
+		{
+			name: "number in a list of strings",
+			doc:  `{"work": [{"highlights": ["Led billing", true]}]}`,
+			want: []string{`work[0].highlights[1]: must be a string, not true/false`},
+		},
+		{
+			name: "list item not an object",
+			doc:  `{"education": ["MIT"]}`,
+			want: []string{`education[0]: must be an object, not a string`},
+		},
+		{
+			name: "null",
+			doc:  `{"basics": {"email": null}}`,
+			want: []string{`basics.email: must not be null`},
+		},
+		{
+			name: "bad email",
+			doc:  `{"basics": {"email": "jane at example.com"}}`,
+			want: []string{`basics.email: "jane at example.com" is not an email address`},
+		},
+		{
+			name: "email with a display name",
+			doc:  `{"basics": {"email": "Jane <jane@example.com>"}}`,
+			want: []string{`basics.email: "Jane <jane@example.com>" is not an email address`},
+		},
+		{
+			name: "URI without a scheme",
+			doc:  `{"basics": {"url": "janedoe.dev"}}`,
+			want: []string{`basics.url: "janedoe.dev" is not a URL (include the scheme, e.g. https://)`},
+		},
+		{
+			name: "nested URI",
+			doc:  `{"basics": {"profiles": [{"network": "GitHub", "url": "github.com/jane"}]}}`,
+			want: []string{`basics.profiles[0].url: "github.com/jane" is not a URL (include the scheme, e.g. https://)`},
+		},
+		{
+			name: "bad date",
+			doc:  `{"work": [{"name": "Acme", "startDate": "March 2020"}]}`,
+			want: []string{`work[0].startDate: "March 2020" is not a date (use YYYY, YYYY-MM or YYYY-MM-DD)`},
+		},
+		{
+			name: "several, in key order",
+			doc:  `{"work": [{"url": "acme", "endDate": "now"}], "basics": {"email": "x"}}`,
+			want: []string{
+				`basics.email: "x" is not an email address`,
+				`work[0].endDate: "now" is not a date (use YYYY, YYYY-MM or YYYY-MM-DD)`,
+				`work[0].url: "acme" is not a URL (include the scheme, e.g. https://)`,
+			},
+		},
+		{
+			name: "unknown section",
+			doc:  `{"hobbies": []}`,
+			want: []string{`unknown section "hobbies"`},
+		},
+		{
+			name: "misnamed section",
+			doc:  `{"Experience": [], "skill": []}`,
+			want: []string{`unknown section "Experience" (did you mean "work"?)`, `unknown section "skill" (did you mean "skills"?)`},
+		},
+		{
+			name: "not an object",
+			doc:  `["work"]`,
+			want: []string{`the top level must be an object`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := problems(t, tt.doc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("problems = %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNotJSON(t *testing.T) {
+	got := problems(t, `{"basics": `)
+	if len(got) != 1 || !strings.HasPrefix(got[0], "not valid JSON: ") {
+		t.Fatalf("problems = %q", got)
+	}
+}
+
+func TestParseCapsProblems(t *testing.T) {
+	var work []string
+	for range maxProblems + 3 {
+		work = append(work, `{"startDate": "soon"}`)
+	}
+	got := problems(t, `{"work": [`+strings.Join(work, ",")+`]}`)
+	if len(got) != maxProblems+1 || got[maxProblems] != "and 3 more" {
+		t.Fatalf("problems = %q", got)
+	}
+}
+
+func TestParseAllowsUnknownFields(t *testing.T) {
+	// Only the top level is closed; themes and tools add fields to entries
+	r, err := Parse([]byte("\xef\xbb\xbf" + `{
+		"basics": {"name": "Jane Doe", "pronouns": "she/her", "location": {"city": "Berlin", "planet": "Earth"}},
+		"work": [{"name": "Acme", "position": "Engineer", "team": "Billing", "startDate": "2020-03"}],
+		"meta": {"theme": "elegant"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Basics.Name != "Jane Doe" || r.Basics.Location.City != "Berlin" || r.Work[0].StartDate != "2020-03" {
+		t.Fatalf("Parse = %+v", r)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		r    Resume
+		want string
+	}{
+		{"valid", Resume{Basics: &Basics{Name: "Jane", Email: "jane@example.com", URL: "https://jane.dev"}}, ""},
+		{"bad email", Resume{Basics: &Basics{Email: "jane@"}}, `invalid JSON Resume: basics.email: "jane@" is not an email address`},
+		{"bad URI", Resume{Projects: []Project{{Name: "Tool", URL: "tool.dev"}}}, `invalid JSON Resume: projects[0].url: "tool.dev" is not a URL (include the scheme, e.g. https://)`},
+		{"bad date", Resume{Education: []Education{{EndDate: "2020/06"}}}, `invalid JSON Resume: education[0].endDate: "2020/06" is not a date (use YYYY, YYYY-MM or YYYY-MM-DD)`},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.r)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("%s: Validate = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// fullResume fills every section the markdown carries
+const fullResume = `{
+  "basics": {
+    "name": "Jane Doe",
+    "label": "Staff Engineer",
+    "email": "jane@example.com",
+    "phone": "+1 555 010 0199",
+    "url": "https://janedoe.dev",
+    "summary": "Backend engineer who ships billing systems.",
+    "location": {"city": "Berlin", "region": "Berlin", "countryCode": "DE"},
+    "profiles": [{"network": "GitHub", "username": "janedoe", "url": "https://github.com/janedoe"}]
+  },
+  "work": [
+    {
+      "name": "Acme",
+      "position": "Staff Engineer",
+      "location": "Remote",
+      "url": "https://acme.example",
+      "startDate": "2020-03",
+      "summary": "Owned the billing platform.",
+      "highlights": ["Cut invoice latency 40%", "Led a team of 5"]
+    },
+    {
+      "name": "Initech",
+      "position": "Engineer",
+      "startDate": "2016",
+      "endDate": "2020-02",
+      "highlights": ["Built the reporting pipeline"]
+    }
+  ],
+  "education": [
+    {
+      "institution": "TU Berlin",
+      "area": "Computer Science",
+      "studyType": "MSc",
+      "startDate": "2014",
+      "endDate": "2016",
+      "courses": ["Distributed Systems"]
+    }
+  ],
+  "skills": [
+    {"name": "Languages", "keywords": ["Go", "Rust", "SQL"]},
+    {"name": "Infrastructure", "keywords": ["Kubernetes", "Postgres"]}
+  ],
+  "languages": [{"language": "German", "fluency": "Native"}],
+  "projects": [
+    {
+      "name": "ledger",
+      "url": "https://github.com/janedoe/ledger",
+      "description": "Double-entry accounting library.",
+      "highlights": ["2k stars"]
+    }
+  ]
+}`
+
+func TestRoundTrip(t *testing.T) {
+	in, err := Parse([]byte(fullResume))
+	if err != nil {
+		t.Fatal(err)
+	}
+	md, err := Import([]byte(fullResume))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Export(export.Parse(md))
+	if err != nil {
+		t.Fatalf("Export: %v\nmarkdown:\n%s", err, md)
+	}
+	out, err := Parse(data)
+	if err != nil {
+		t.Fatalf("exported resume.json doesn't validate: %v\n%s", err, data)
+	}
+	if out.Schema != SchemaURL {
+		t.Errorf("$schema = %q, want %q", out.Schema, SchemaURL)
+	}
+	out.Schema = ""
+
+	if !reflect.DeepEqual(in, out) {
+		want, _ := json.MarshalIndent(in, "", "  ")
+		got, _ := json.MarshalIndent(out, "", "  ")
+		t.Fatalf("round trip changed the resume\nmarkdown:\n%s\ngot:\n%s\nwant:\n%s", md, got, want)
+	}
+}
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		text string
+		want Location
+	}{
+		{"Berlin", Location{City: "Berlin"}},
+		// Two letters after the city read as a region: "Austin, TX" is
+		// far more common than a country with no region
+		{"Austin, TX", Location{City: "Austin", Region: "TX"}},
+		{"Berlin, DE", Location{City: "Berlin", Region: "DE"}},
+		{"Berlin, Berlin, DE", Location{City: "Berlin", Region: "Berlin", CountryCode: "DE"}},
+		{"Austin, TX 78701, US", Location{City: "Austin", Region: "TX", PostalCode: "78701", CountryCode: "US"}},
+		{"1 Main St, Austin, TX 78701, US", Location{Address: "1 Main St", City: "Austin", Region: "TX", PostalCode: "78701", CountryCode: "US"}},
+	}
+	for _, tt := range tests {
+		if got := parseLocation(tt.text); *got != tt.want {
+			t.Errorf("parseLocation(%q) = %+v, want %+v", tt.text, *got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Package lang detects the language of resumes and job descriptions and
+// describes the CV conventions of each supported language.
+package lang
+
+import (
+	"log"
+	"strings"
+	"unicode"
+)
+
+// Language is a supported resume language
+type Language struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	NativeName string `json:"native_name"`
+	// Conventions guides localization when translating into this language
+	Conventions string `json:"-"`
+}
+
+// Supported lists the languages we detect and can write resumes in
+var Supported = []Language{
+	{
+		Code:       "en",
+		Name:       "English",
+		NativeName: "English",
+		Conventions: `US/UK resume norms. Sections: Summary, Experience, Skills, Education.
+Dates as "Jan 2020 – Mar 2023". No photo, date of birth, marital status or nationality.
+Use concise bullet points starting with action verbs; omit personal pronouns.`,
+	},
+	{
+		Code:       "de",
+		Name:       "German",
+		NativeName: "Deutsch",
+		Conventions: `German Lebenslauf norms. Sections: Persönliche Daten, Profil, Berufserfahrung, Ausbildung, Kenntnisse (Sprachkenntnisse, IT-Kenntnisse).
+Dates as "01/2020 – 03/2023" (MM/JJJJ), reverse chronological. A tabular, factual style is expected.
+Keep personal details the candidate provided (e.g. Geburtsdatum, Staatsangehörigkeit) but never invent them.
+Rate language skills with CEFR levels (e.g. "Englisch – C1") where the source gives a level.
+Use formal, noun-heavy phrasing; avoid first person.`,
+	},
+	{
+		Code:       "fr",
+		Name:       "French",
+		NativeName: "Français",
+		Conventions: `French CV norms. Sections: Profil, Expérience professionnelle, Formation, Compétences, Langues, Centres d'intérêt.
+Dates as "janv. 2020 – mars 2023" or "01/2020 – 03/2023". Keep it to one or two pages.
+Translate degree names with their French equivalent where one exists (e.g. "Master" / "Licence"), otherwise keep the original with a short gloss.
+Use infinitive or noun phrases for bullet points; avoid first person.`,
+	},
+	{
+		Code:       "es",
+		Name:       "Spanish",
+		NativeName: "Español",
+		Conventions: `Spanish currículum vitae norms. Sections: Perfil profesional, Experiencia profesional, Formación académica, Competencias, Idiomas.
+Dates as "ene. 2020 – mar. 2023" or "01/2020 – 03/2023", reverse chronological.
+Rate languages with CEFR levels where the source gives a level.
+Use past-tense action verbs (e.g. "Lideré", "Desarrollé") or noun phrases consistently.`,
+	},
+}
+
+// Lookup returns the supported language with the given code
+func Lookup(code string) (Language, bool) {
+	for _, l := range Supported {
+		if l.Code == code {
+			return l, true
+		}
+	}
+	return Language{}, false
+}
+
+// stopwords are short, frequent words that identify each language
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "in", "for", "with", "on", "is", "are", "we", "you", "our", "your", "as", "at", "by", "an", "be", "will", "this", "that", "from", "have"},
+	"de": {"der", "die", "das", "und", "in", "zu", "mit", "von", "für", "auf", "ist", "sind", "wir", "sie", "ein", "eine", "einer", "den", "dem", "des", "bei", "als", "auch", "im", "oder", "nicht", "unsere", "ihre"},
+	"fr": {"le", "la", "les", "et", "de", "des", "du", "un", "une", "en", "pour", "avec", "sur", "est", "sont", "nous", "vous", "au", "aux", "dans", "par", "qui", "que", "votre", "notre", "ou"},
+	"es": {"el", "la", "los", "las", "y", "de", "del", "en", "un", "una", "para", "con", "por", "es", "son", "nuestro", "nuestra", "su", "sus", "que", "al", "como", "o", "se", "lo"},
+}
+
+// hints are characters that only occur (among supported languages) in one
+var hints = map[rune]string{
+	'ä': "de", 'ö': "de", 'ü': "de", 'ß': "de",
+	'è': "fr", 'ê': "fr", 'à': "fr", 'ç': "fr", 'œ': "fr", 'û': "fr", 'ù': "fr", 'ë': "fr", 'î': "fr",
+	'ñ': "es", '¿': "es", '¡': "es", 'ó': "es", 'í': "es", 'á': "es", 'ú': "es",
+}
+
+// minScore is the minimum evidence needed to call a language
+const minScore = 3
+
+// Detector names the language text is written in as an ISO 639-1 code,
+// usually by asking a model
+type Detector func(text string) (string, error)
+
+// sampleRunes bounds the text a Detector sees; a few paragraphs settle the
+// language as well as a whole resume does
+const sampleRunes = 1500
+
+// minDetectWords is the least text worth asking a Detector about
+const minDetectWords = 5
+
+// DetectWith asks detect for the language of text, falling back to Detect
+// when detect is nil or fails. Like Detect, it returns "" for languages
+// that aren't supported.
+func DetectWith(text string, detect Detector) string {
+	if detect == nil || len(strings.Fields(text)) < minDetectWords {
+		return Detect(text)
+	}
+	sample := text
+	if runes := []rune(text); len(runes) > sampleRunes {
+		sample = string(runes[:sampleRunes])
+	}
+	code, err := detect(sample)
+	if err != nil {
+		log.Printf("[Lang] Detecting a language failed, using stopwords: %v", err)
+		return Detect(text)
+	}
+	if l, ok := Lookup(strings.ToLower(strings.TrimSpace(code))); ok {
+		return l.Code
+	}
+	return ""
+}
+
+// Detect returns the language code of text, or "" if it is too short or
+// not one of the supported languages. It counts stopwords, so it needs no
+// model.
+func Detect(text string) string {
+	sets := make(map[string]map[string]bool, len(stopwords))
+	for code, words := range stopwords {
+		set := make(map[string]bool, len(words))
+		for _, w := range words {
+			set[w] = true
+		}
+		sets[code] = set
+	}
+
+	scores := map[string]float64{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		for code, set := range sets {
+			if set[word] {
+				scores[code]++
+			}
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		if code, ok := hints[r]; ok {
+			scores[code] += 0.5
+		}
+	}
+
+	best, bestScore, second := "", 0.0, 0.0
+	for _, l := range Supported {
+		s := scores[l.Code]
+		if s > bestScore {
+			best, second, bestScore = l.Code, bestScore, s
+		} else if s > second {
+			second = s
+		}
+	}
+	// Require a clear winner; mixed-language text stays undetected
+	if bestScore < minScore || bestScore < second*1.2 {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,95 @@
+package lang
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// samples are short job descriptions in each supported language
+var samples = map[string]string{
+	"en": "We are looking for a senior engineer to join our payments team. You will work with Go and PostgreSQL and help us scale the platform.",
+	"de": "Wir suchen eine erfahrene Entwicklerin für unser Team in Berlin. Sie arbeiten mit Go und PostgreSQL an der Plattform und sind für die Qualität zuständig.",
+	"fr": "Nous recherchons un ingénieur pour notre équipe à Paris. Vous travaillerez avec Go et PostgreSQL sur la plateforme de paiement et serez responsable de la qualité.",
+	"es": "Buscamos una ingeniera para nuestro equipo en Madrid. Trabajarás con Go y PostgreSQL en la plataforma de pagos y serás responsable de la calidad del servicio.",
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"english", samples["en"], "en"},
+		{"german", samples["de"], "de"},
+		{"french", samples["fr"], "fr"},
+		{"spanish", samples["es"], "es"},
+		{"german resume", "Berufserfahrung\n\n### Softwareentwickler, Acme GmbH\n- Entwicklung von Microservices mit Go und Kubernetes für die Zahlungsabwicklung\n- Einführung der Testautomatisierung im gesamten Team", "de"},
+		{"too short", "Go developer", ""},
+		{"empty", "", ""},
+		{"no stopwords", "Go Kubernetes PostgreSQL Terraform AWS GCP Docker", ""},
+		{"unsupported", "Cerchiamo uno sviluppatore esperto per il nostro gruppo di Milano.", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.text); got != tt.want {
+				t.Errorf("Detect = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectWith(t *testing.T) {
+	failing := func(string) (string, error) { return "", errors.New("model unavailable") }
+	answer := func(code string) Detector {
+		return func(string) (string, error) { return code, nil }
+	}
+	tests := []struct {
+		name   string
+		text   string
+		detect Detector
+		want   string
+	}{
+		{"model", samples["en"], answer("de"), "de"},
+		{"model answer is normalized", samples["fr"], answer(" FR\n"), "fr"},
+		{"unsupported language", samples["en"], answer("it"), ""},
+		{"model can't tell", samples["en"], answer("unknown"), ""},
+		{"no model falls back", samples["es"], nil, "es"},
+		{"failing model falls back", samples["de"], failing, "de"},
+		{"short text skips the model", "Go developer", answer("de"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectWith(tt.text, tt.detect); got != tt.want {
+				t.Errorf("DetectWith = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectWithSendsASample(t *testing.T) {
+	text := strings.Repeat("Wir suchen für unser Team eine Entwicklerin. ", 200)
+	var sent string
+	DetectWith(text, func(sample string) (string, error) {
+		sent = sample
+		return "de", nil
+	})
+	if n := utf8.RuneCountInString(sent); n != sampleRunes {
+		t.Errorf("detector saw %d runes, want %d", n, sampleRunes)
+	}
+	if !utf8.ValidString(sent) || !strings.HasPrefix(text, sent) {
+		t.Errorf("detector saw %q, want the start of the text", sent)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, l := range Supported {
+		if got, ok := Lookup(l.Code); !ok || got.Name != l.Name {
+			t.Errorf("Lookup(%q) = %+v, %v", l.Code, got, ok)
+		}
+	}
+	if _, ok := Lookup("it"); ok {
+		t.Error("Lookup found an unsupported language")
+	}
+}
@@ -0,0 +1,188 @@
+// Package linkedin reads the data export LinkedIn members can download
+// (Settings > Data privacy > Get a copy of your data): a ZIP of CSV files
+// such as Profile.csv, Positions.csv, Education.csv and Skills.csv.
+//
+// Parse turns the archive into a JSON Resume, which serves as the user's
+// master profile; jsonresume.Markdown renders it as a baseline resume.
+package linkedin
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxCSVSize caps each CSV read from the archive; an export's profile files
+// are a few kilobytes, so anything near this is not one
+const maxCSVSize = 5 << 20
+
+var (
+	// ErrNotExport is returned for archives without any of the profile CSVs
+	ErrNotExport = errors.New("not a LinkedIn data export")
+	// ErrEmpty is returned when the export has no profile data in it
+	ErrEmpty = errors.New("LinkedIn export has no profile data")
+)
+
+// profileFiles are the CSVs Parse reads, lower-cased
+var profileFiles = []string{
+	"profile.csv", "positions.csv", "education.csv", "skills.csv",
+	"email addresses.csv", "phonenumbers.csv", "certifications.csv",
+	"languages.csv", "projects.csv", "honors.csv", "publications.csv",
+	"volunteering.csv", "courses.csv",
+}
+
+// IsExport reports whether a zip archive looks like a LinkedIn data export
+func IsExport(zr *zip.Reader) bool {
+	for _, f := range zr.File {
+		switch strings.ToLower(path.Base(f.Name)) {
+		case "profile.csv", "positions.csv":
+			return true
+		}
+	}
+	return false
+}
+
+// export holds the rows of each CSV in the archive, keyed by lower-cased
+// file name
+type export map[string][]row
+
+// row maps column names to values
+type row map[string]string
+
+// get returns the first non-empty value among columns; LinkedIn has renamed
+// a few over the years
+func (r row) get(columns ...string) string {
+	for _, c := range columns {
+		if v := strings.TrimSpace(r[c]); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func readExport(data []byte) (export, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	if !IsExport(zr) {
+		return nil, ErrNotExport
+	}
+
+	files := export{}
+	for _, f := range zr.File {
+		name := strings.ToLower(path.Base(f.Name))
+		if !contains(profileFiles, name) || f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, maxCSVSize+1))
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(content) > maxCSVSize {
+			continue
+		}
+		rows, err := readCSV(content)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = rows
+	}
+	return files, nil
+}
+
+// Trim rebuilds an export with only the CSVs Parse reads. The full export
+// also holds messages, connections, ad data and the like, none of which
+// should be kept with the user's resume.
+func Trim(data []byte) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	if !IsExport(zr) {
+		return nil, ErrNotExport
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range zr.File {
+		if !contains(profileFiles, strings.ToLower(path.Base(f.Name))) || f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, maxCSVSize+1))
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(content) > maxCSVSize {
+			continue
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.Name, Method: zip.Deflate, Modified: f.Modified})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// readCSV reads a CSV with a header row. Some files open with a "Notes:"
+// paragraph above the header, which is skipped.
+func readCSV(content []byte) ([]row, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	if i := bytes.Index(content, []byte("\n\n")); i >= 0 && bytes.HasPrefix(bytes.TrimSpace(content), []byte("Notes:")) {
+		content = content[i+2:]
+	}
+
+	r := csv.NewReader(bytes.NewReader(content))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	rows := make([]row, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		rw := row{}
+		for i, value := range rec {
+			if i < len(header) {
+				rw[strings.TrimSpace(header[i])] = value
+			}
+		}
+		rows = append(rows, rw)
+	}
+	return rows, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
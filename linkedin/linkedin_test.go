@@ -0,0 +1,239 @@
+package linkedin
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/johnhkchen/resume-tweaker/jsonresume"
+)
+
+// buildZip zips files, keeping their order
+func buildZip(t *testing.T, files ...[2]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(f[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// exportFixture is a cut-down export as LinkedIn ships it: a BOM on
+// Profile.csv, a "Notes:" preamble on Positions.csv, CRLF line endings,
+// the newer Education.csv column names, and files that aren't profile data
+var exportFixture = [][2]string{
+	{"Profile.csv", "\ufeffFirst Name,Last Name,Maiden Name,Address,Birth Date,Headline,Summary,Industry,Zip Code,Geo Location,Twitter Handles,Websites,Instant Messengers\r\n" +
+		"Jane,Doe,,,,Staff Engineer at Acme,Backend engineer.,Software,,\"Berlin, Germany\",[janedoe],[PORTFOLIO:https://janedoe.dev],\r\n"},
+	{"Positions.csv", "Notes:\r\n\"This file lists your positions, most recent first.\"\r\n\r\n" +
+		"Company Name,Title,Description,Location,Started On,Finished On\r\n" +
+		"Acme,Staff Engineer,\"Owns billing.\n- Cut invoice latency 40%\n- Led a team of 5\",Remote,Mar 2020,\r\n" +
+		"Initech,Engineer,,Berlin,2016,Feb 2020\r\n"},
+	{"Education.csv", "School Name,Start Date,End Date,Notes,Degree Name,Activities,Field of Study\r\n" +
+		"TU Berlin,2014,2016,,MSc,,Computer Science\r\n"},
+	{"Skills.csv", "Name\r\nGo\r\nRust\r\n"},
+	{"Email Addresses.csv", "Email Address,Confirmed,Primary,Updated On\r\nold@example.com,Yes,No,\r\njane@example.com,Yes,Yes,\r\n"},
+	{"messages.csv", "CONVERSATION ID,FROM,TO,CONTENT\r\n1,Jane Doe,Sam Roe,Private message\r\n"},
+	{"Connections.csv", "Notes:\r\n\"Some emails are hidden.\"\r\n\r\nFirst Name,Last Name,Email Address\r\nSam,Roe,sam@example.com\r\n"},
+	{"Rich Media/photo.jpg", "\xff\xd8\xff"},
+}
+
+var wantFixture = jsonresume.Resume{
+	Basics: &jsonresume.Basics{
+		Name:     "Jane Doe",
+		Label:    "Staff Engineer at Acme",
+		Email:    "jane@example.com",
+		URL:      "https://janedoe.dev",
+		Summary:  "Backend engineer.",
+		Location: &jsonresume.Location{City: "Berlin, Germany"},
+		Profiles: []jsonresume.Profile{{Network: "Twitter", Username: "janedoe", URL: "https://twitter.com/janedoe"}},
+	},
+	Work: []jsonresume.Work{
+		{Name: "Acme", Position: "Staff Engineer", Location: "Remote", StartDate: "2020-03", Summary: "Owns billing.", Highlights: []string{"Cut invoice latency 40%", "Led a team of 5"}},
+		{Name: "Initech", Position: "Engineer", Location: "Berlin", StartDate: "2016", EndDate: "2020-02"},
+	},
+	Education: []jsonresume.Education{{Institution: "TU Berlin", StudyType: "MSc", Area: "Computer Science", StartDate: "2014", EndDate: "2016"}},
+	Skills:    []jsonresume.Skill{{Name: "Go"}, {Name: "Rust"}},
+}
+
+func TestParse(t *testing.T) {
+	got, err := Parse(buildZip(t, exportFixture...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, wantFixture) {
+		t.Fatalf("Parse =\n%+v\nwant\n%+v", got, wantFixture)
+	}
+}
+
+func TestParseOlderColumnNames(t *testing.T) {
+	data := buildZip(t,
+		[2]string{"Basic_LinkedInDataExport/Positions.csv", "Company Name,Title,Description,Location,Started On,Finished On\nAcme,Engineer,,,2019,\n"},
+		[2]string{"Basic_LinkedInDataExport/Education.csv", "School Name,Started On,Finished On,Degree Name,Field Of Study\nMIT,2010,2014,BSc,Physics\n"},
+	)
+	got, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := jsonresume.Education{Institution: "MIT", StudyType: "BSc", Area: "Physics", StartDate: "2010", EndDate: "2014"}
+	if len(got.Education) != 1 || !reflect.DeepEqual(got.Education[0], want) {
+		t.Fatalf("Education = %+v, want %+v", got.Education, want)
+	}
+	if len(got.Work) != 1 || got.Work[0].StartDate != "2019" {
+		t.Fatalf("Work = %+v", got.Work)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse(buildZip(t, [2]string{"messages.csv", "FROM,TO\n"})); !errors.Is(err, ErrNotExport) {
+		t.Errorf("archive without profile CSVs: %v, want ErrNotExport", err)
+	}
+	if _, err := Parse(buildZip(t, [2]string{"Profile.csv", "First Name,Last Name\n"})); !errors.Is(err, ErrEmpty) {
+		t.Errorf("export without data: %v, want ErrEmpty", err)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []row
+	}{
+		{
+			name:    "plain",
+			content: "Name,Level\nGo,Expert\n",
+			want:    []row{{"Name": "Go", "Level": "Expert"}},
+		},
+		{
+			name:    "byte order mark",
+			content: "\ufeffName\nGo\n",
+			want:    []row{{"Name": "Go"}},
+		},
+		{
+			name:    "notes preamble",
+			content: "Notes:\n\"When exporting your connection data, you may notice that some of the email addresses are missing.\"\n\nFirst Name,Last Name\nSam,Roe\n",
+			want:    []row{{"First Name": "Sam", "Last Name": "Roe"}},
+		},
+		{
+			name:    "notes preamble with BOM and CRLF",
+			content: "\ufeffNotes:\r\n\"Hidden emails.\"\r\n\r\nFirst Name\r\nSam\r\n",
+			want:    []row{{"First Name": "Sam"}},
+		},
+		{
+			name:    "blank line without notes",
+			content: "Name\nGo\n\nRust\n",
+			want:    []row{{"Name": "Go"}, {"Name": "Rust"}},
+		},
+		{
+			name:    "padded header",
+			content: "Company Name , Title\nAcme,Engineer\n",
+			want:    []row{{"Company Name": "Acme", "Title": "Engineer"}},
+		},
+		{
+			name:    "ragged rows",
+			content: "A,B\n1\n2,3,4\n",
+			want:    []row{{"A": "1"}, {"A": "2", "B": "3"}},
+		},
+		{
+			name:    "multi-line and stray quotes",
+			content: "Title,Description\nLead,\"Line one\nLine \"\"two\"\"\"\nDev,5\" display\n",
+			want:    []row{{"Title": "Lead", "Description": "Line one\nLine \"two\""}, {"Title": "Dev", "Description": "5\" display"}},
+		},
+		{
+			name:    "empty",
+			content: "",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readCSV([]byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("readCSV = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRowGetFallsBackToRenamedColumns(t *testing.T) {
+	r := row{"Start Date": " ", "Started On": "2019", "Field of Study": "Physics"}
+	if got := r.get("Start Date", "Started On"); got != "2019" {
+		t.Errorf("get = %q, want the older column when the newer is blank", got)
+	}
+	if got := r.get("Field Of Study", "Field of Study"); got != "Physics" {
+		t.Errorf("get = %q, want the renamed column", got)
+	}
+	if got := r.get("Missing"); got != "" {
+		t.Errorf("get = %q for a missing column", got)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	data := buildZip(t, exportFixture...)
+	trimmed, err := Trim(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(trimmed), int64(len(trimmed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	want := []string{"Education.csv", "Email Addresses.csv", "Positions.csv", "Profile.csv", "Skills.csv"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("trimmed export holds %q, want %q", names, want)
+	}
+
+	// Files are copied byte for byte, so the profile reads the same
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, orig := range exportFixture {
+			if orig[0] == f.Name && orig[1] != string(content) {
+				t.Errorf("%s changed in the trimmed export", f.Name)
+			}
+		}
+	}
+	got, err := Parse(trimmed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, wantFixture) {
+		t.Fatalf("Parse of the trimmed export =\n%+v\nwant\n%+v", got, wantFixture)
+	}
+
+	if _, err := Trim(buildZip(t, [2]string{"messages.csv", "FROM,TO\n"})); !errors.Is(err, ErrNotExport) {
+		t.Errorf("Trim of a non-export = %v, want ErrNotExport", err)
+	}
+}
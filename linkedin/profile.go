@@ -0,0 +1,280 @@
+package linkedin
+
+import (
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/johnhkchen/resume-tweaker/jsonresume"
+)
+
+// Parse reads a LinkedIn data export into a JSON Resume
+func Parse(data []byte) (jsonresume.Resume, error) {
+	files, err := readExport(data)
+	if err != nil {
+		return jsonresume.Resume{}, err
+	}
+
+	r := jsonresume.Resume{Basics: basics(files)}
+	for _, p := range files["positions.csv"] {
+		summary, highlights := splitDescription(p.get("Description"))
+		start, end := dateRange(p.get("Started On"), p.get("Finished On"))
+		r.Work = append(r.Work, jsonresume.Work{
+			Name:       p.get("Company Name"),
+			Position:   p.get("Title"),
+			Location:   p.get("Location"),
+			StartDate:  start,
+			EndDate:    end,
+			Summary:    summary,
+			Highlights: highlights,
+		})
+	}
+	for _, v := range files["volunteering.csv"] {
+		summary, highlights := splitDescription(v.get("Description"))
+		start, end := dateRange(v.get("Started On"), v.get("Finished On"))
+		r.Volunteer = append(r.Volunteer, jsonresume.Volunteer{
+			Organization: v.get("Company Name", "Organization"),
+			Position:     v.get("Role"),
+			StartDate:    start,
+			EndDate:      end,
+			Summary:      summary,
+			Highlights:   highlights,
+		})
+	}
+	for _, e := range files["education.csv"] {
+		start, end := dateRange(e.get("Start Date", "Started On"), e.get("End Date", "Finished On"))
+		r.Education = append(r.Education, jsonresume.Education{
+			Institution: e.get("School Name"),
+			StudyType:   e.get("Degree Name"),
+			Area:        e.get("Field Of Study", "Field of Study"),
+			StartDate:   start,
+			EndDate:     end,
+		})
+	}
+	// Courses aren't tied to a school in the export; with one school
+	// there's no doubt which
+	var courses []string
+	for _, c := range files["courses.csv"] {
+		if name := c.get("Name"); name != "" {
+			courses = append(courses, name)
+		}
+	}
+	if len(courses) > 0 && len(r.Education) == 1 {
+		r.Education[0].Courses = courses
+	} else if len(courses) > 0 {
+		r.Meta = &jsonresume.Meta{Sections: []jsonresume.Section{{
+			Title:   "Courses",
+			Content: "- " + strings.Join(courses, "\n- "),
+		}}}
+	}
+	for _, s := range files["skills.csv"] {
+		if name := s.get("Name"); name != "" {
+			r.Skills = append(r.Skills, jsonresume.Skill{Name: name})
+		}
+	}
+	for _, c := range files["certifications.csv"] {
+		date, _ := dateRange(c.get("Started On"), "")
+		r.Certificates = append(r.Certificates, jsonresume.Certificate{
+			Name:   c.get("Name"),
+			Issuer: c.get("Authority"),
+			Date:   date,
+			URL:    webURL(c.get("Url", "URL")),
+		})
+	}
+	for _, l := range files["languages.csv"] {
+		if name := l.get("Name"); name != "" {
+			r.Languages = append(r.Languages, jsonresume.Language{Language: name, Fluency: l.get("Proficiency")})
+		}
+	}
+	for _, p := range files["projects.csv"] {
+		start, end := dateRange(p.get("Started On"), p.get("Finished On"))
+		r.Projects = append(r.Projects, jsonresume.Project{
+			Name:        p.get("Title"),
+			Description: p.get("Description"),
+			URL:         webURL(p.get("Url", "URL")),
+			StartDate:   start,
+			EndDate:     end,
+		})
+	}
+	for _, h := range files["honors.csv"] {
+		date, _ := dateRange(h.get("Issued On"), "")
+		r.Awards = append(r.Awards, jsonresume.Award{
+			Title:   h.get("Title"),
+			Summary: h.get("Description"),
+			Date:    date,
+		})
+	}
+	for _, p := range files["publications.csv"] {
+		date, _ := dateRange(p.get("Published On"), "")
+		r.Publications = append(r.Publications, jsonresume.Publication{
+			Name:        p.get("Name"),
+			Publisher:   p.get("Publisher"),
+			ReleaseDate: date,
+			URL:         webURL(p.get("Url", "URL")),
+			Summary:     p.get("Description"),
+		})
+	}
+
+	if r.Basics == nil && len(r.Work) == 0 && len(r.Education) == 0 && len(r.Skills) == 0 {
+		return jsonresume.Resume{}, ErrEmpty
+	}
+	return r, nil
+}
+
+// basics reads the name, headline and contact details
+func basics(files export) *jsonresume.Basics {
+	b := &jsonresume.Basics{}
+	if rows := files["profile.csv"]; len(rows) > 0 {
+		p := rows[0]
+		b.Name = strings.Join(strings.Fields(p.get("First Name")+" "+p.get("Last Name")), " ")
+		b.Label = p.get("Headline")
+		b.Summary = p.get("Summary")
+		if loc := p.get("Geo Location", "Location"); loc != "" {
+			b.Location = &jsonresume.Location{City: loc}
+		}
+		if u := webURL(p.get("Public Profile URL", "Profile URL")); u != "" {
+			b.Profiles = append(b.Profiles, jsonresume.Profile{Network: "LinkedIn", URL: u, Username: profileUsername(u)})
+		}
+		// Websites look like "[PORTFOLIO:https://a.dev,BLOG:https://b.dev]";
+		// the first becomes the main URL
+		for _, u := range urlRe.FindAllString(p.get("Websites"), -1) {
+			if u = webURL(u); u == "" {
+				continue
+			}
+			if b.URL == "" {
+				b.URL = u
+			} else {
+				b.Profiles = append(b.Profiles, jsonresume.Profile{URL: u})
+			}
+		}
+		for _, handle := range strings.FieldsFunc(p.get("Twitter Handles"), func(r rune) bool {
+			return r == '[' || r == ']' || r == ',' || unicode.IsSpace(r)
+		}) {
+			handle = strings.TrimPrefix(handle, "@")
+			b.Profiles = append(b.Profiles, jsonresume.Profile{Network: "Twitter", Username: handle, URL: "https://twitter.com/" + handle})
+		}
+	}
+
+	// The primary address if one is marked, otherwise the first
+	for _, e := range files["email addresses.csv"] {
+		addr := e.get("Email Address")
+		if _, err := mail.ParseAddress(addr); err != nil {
+			continue
+		}
+		if b.Email == "" || strings.EqualFold(e.get("Primary"), "yes") {
+			b.Email = addr
+		}
+	}
+	for _, p := range files["phonenumbers.csv"] {
+		if number := p.get("Number"); number != "" {
+			b.Phone = number
+			break
+		}
+	}
+
+	if b.Name == "" && b.Label == "" && b.Summary == "" && b.Email == "" && b.Phone == "" {
+		return nil
+	}
+	return b
+}
+
+var urlRe = regexp.MustCompile(`https?://[^\s,\]]+`)
+
+// webURL returns s if it's an absolute http(s) URL, adding the scheme
+// LinkedIn sometimes leaves off
+func webURL(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return u.String()
+}
+
+// profileUsername takes the member name from linkedin.com/in/<name>
+func profileUsername(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+	name, ok := strings.CutPrefix(strings.Trim(parsed.Path, "/"), "in/")
+	if !ok || strings.Contains(name, "/") {
+		return ""
+	}
+	return name
+}
+
+// dateRange converts LinkedIn dates such as "Mar 2020" or "2019" to ISO
+// 8601. An unreadable start drops both, since the schema needs valid dates.
+func dateRange(start, end string) (string, string) {
+	s, ok := parseDate(start)
+	if !ok {
+		return "", ""
+	}
+	e, ok := parseDate(end)
+	if !ok {
+		e = ""
+	}
+	return s, e
+}
+
+// usDateLayouts are the numeric dates some exports use
+var usDateLayouts = []string{"1/2/06", "1/2/2006"}
+
+func parseDate(s string) (string, bool) {
+	if s == "" {
+		return "", true
+	}
+	if d, ok := jsonresume.ParseDate(s); ok {
+		return d, true
+	}
+	for _, layout := range usDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02"), true
+		}
+	}
+	return "", false
+}
+
+// splitDescription separates a position description into its prose and
+// bullet points, which become the summary and highlights
+func splitDescription(desc string) (string, []string) {
+	var prose []string
+	var highlights []string
+	for _, line := range strings.Split(desc, "\n") {
+		line = strings.TrimSpace(line)
+		if item, ok := bulletItem(line); ok {
+			if item != "" {
+				highlights = append(highlights, item)
+			}
+			continue
+		}
+		prose = append(prose, line)
+	}
+	return strings.TrimSpace(strings.Join(prose, "\n")), highlights
+}
+
+// bulletItem strips a list marker such as "•" or "- " off the start of line
+func bulletItem(line string) (string, bool) {
+	r, n := utf8.DecodeRuneInString(line)
+	switch r {
+	case '•', '●', '◦', '▪', '·', '‣', '➢', '►', '✓', '✔':
+		return strings.TrimSpace(line[n:]), true
+	case '-', '*', '–', '—':
+		// "-5%" isn't an item
+		if rest := line[n:]; rest == "" || rest[0] == ' ' {
+			return strings.TrimSpace(rest), true
+		}
+	}
+	return line, false
+}
@@ -0,0 +1,130 @@
+// Package llm holds the resilience policy around BAML calls: which models
+// to try in which order, how long each stage may take, and a circuit breaker
+// per model so a failing upstream is skipped instead of waited on.
+package llm
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Path labels reported to the UI as the "served_by" signal
+const (
+	PathSonnet  = "claude-sonnet"
+	PathHaiku   = "claude-haiku"
+	PathOffline = "offline"
+	PathDemo    = "demo"
+)
+
+// Route is one model in the fallback chain
+type Route struct {
+	// Client is the BAML client name from baml_src/clients.baml
+	Client string
+	// Path is the label reported to the UI
+	Path    string
+	Breaker *Breaker
+}
+
+// Deadlines bound each stage of an LLM call
+type Deadlines struct {
+	// FirstToken is how long to wait for the stream to produce anything
+	FirstToken time.Duration
+	// Idle is the longest gap allowed between tokens once output has started
+	Idle time.Duration
+	// Total caps the whole generation
+	Total time.Duration
+}
+
+var (
+	// Sonnet first for quality; Haiku backs it up
+	routes = []Route{
+		{Client: "ClaudeSonnet", Path: PathSonnet, Breaker: newBreakerFromEnv("ClaudeSonnet")},
+		{Client: "ClaudeHaiku", Path: PathHaiku, Breaker: newBreakerFromEnv("ClaudeHaiku")},
+	}
+	deadlines = Deadlines{
+		FirstToken: envDuration("LLM_FIRST_TOKEN_TIMEOUT", 20*time.Second),
+		Idle:       envDuration("LLM_IDLE_TIMEOUT", 30*time.Second),
+		Total:      envDuration("LLM_TOTAL_TIMEOUT", 2*time.Minute),
+	}
+)
+
+// Routes returns the model fallback chain, best model first
+func Routes() []Route {
+	return routes
+}
+
+// StageDeadlines returns the configured per-stage deadlines
+func StageDeadlines() Deadlines {
+	return deadlines
+}
+
+// ErrUnavailable is returned by Fallback when every route failed or had its
+// circuit open; callers fall back to offline suggestions
+var ErrUnavailable = errors.New("no model available")
+
+// Fallback calls call with each route in turn, skipping routes whose
+// breaker is open, until one succeeds, and records each outcome on the
+// route's breaker. It returns the route that succeeded, ErrUnavailable if
+// none did, or ctx's error if ctx ended during a call, which doesn't count
+// against the route.
+func Fallback(ctx context.Context, routes []Route, call func(Route) error) (Route, error) {
+	for _, route := range routes {
+		if !route.Breaker.Allow() {
+			log.Printf("[LLM] Skipping %s: circuit open", route.Client)
+			continue
+		}
+
+		err := call(route)
+		if ctx.Err() != nil {
+			route.Breaker.Release()
+			return Route{}, ctx.Err()
+		}
+		if err != nil {
+			route.Breaker.Failure()
+			log.Printf("[LLM] %s failed, falling back: %v", route.Client, err)
+			continue
+		}
+
+		route.Breaker.Success()
+		return route, nil
+	}
+	return Route{}, ErrUnavailable
+}
+
+// BreakerStates returns the current state of each model's breaker
+func BreakerStates() map[string]string {
+	states := make(map[string]string, len(routes))
+	for _, r := range routes {
+		states[r.Client] = r.Breaker.State()
+	}
+	return states
+}
+
+func newBreakerFromEnv(name string) *Breaker {
+	threshold := 5
+	if raw := os.Getenv("LLM_BREAKER_THRESHOLD"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			threshold = n
+		} else {
+			log.Printf("[LLM] Invalid LLM_BREAKER_THRESHOLD %q, using %d", raw, threshold)
+		}
+	}
+	return NewBreaker(name, threshold, envDuration("LLM_BREAKER_COOLDOWN", time.Minute))
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		log.Printf("[LLM] Invalid %s %q, using %s", key, raw, fallback)
+		return fallback
+	}
+	return d
+}
@@ -0,0 +1,201 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+const testCooldown = 20 * time.Millisecond
+
+func TestBreakerOpensAtThreshold(t *testing.T) {
+	b := NewBreaker("test", 3, time.Hour)
+	for i := range 2 {
+		if !b.Allow() {
+			t.Fatalf("call %d rejected while closed", i)
+		}
+		b.Failure()
+		if got := b.State(); got != StateClosed {
+			t.Fatalf("after %d failures: state %s, want closed", i+1, got)
+		}
+	}
+	b.Failure()
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("after 3 failures: state %s, want open", got)
+	}
+	if b.Allow() {
+		t.Fatal("open breaker allowed a call")
+	}
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	b := NewBreaker("test", 2, time.Hour)
+	b.Failure()
+	b.Success()
+	b.Failure()
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("state %s, want closed: failures should not add up across a success", got)
+	}
+}
+
+func TestBreakerHalfOpenTrialSucceeds(t *testing.T) {
+	b := NewBreaker("test", 1, testCooldown)
+	b.Failure()
+	time.Sleep(testCooldown)
+
+	if got := b.State(); got != StateHalfOpen {
+		t.Fatalf("after cooldown: state %s, want half_open", got)
+	}
+	if !b.Allow() {
+		t.Fatal("half-open breaker rejected the trial call")
+	}
+	if b.Allow() {
+		t.Fatal("half-open breaker allowed a second call during the trial")
+	}
+	b.Success()
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("after trial success: state %s, want closed", got)
+	}
+	if !b.Allow() || !b.Allow() {
+		t.Fatal("closed breaker rejected a call")
+	}
+}
+
+func TestBreakerHalfOpenTrialFails(t *testing.T) {
+	b := NewBreaker("test", 5, testCooldown)
+	for range 5 {
+		b.Failure()
+	}
+	time.Sleep(testCooldown)
+	if !b.Allow() {
+		t.Fatal("half-open breaker rejected the trial call")
+	}
+
+	// One failed trial re-opens it, whatever the threshold
+	b.Failure()
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("after trial failure: state %s, want open", got)
+	}
+	if b.Allow() {
+		t.Fatal("re-opened breaker allowed a call before its cooldown")
+	}
+}
+
+func TestBreakerReleaseFreesTrial(t *testing.T) {
+	b := NewBreaker("test", 1, testCooldown)
+	b.Failure()
+	time.Sleep(testCooldown)
+	if !b.Allow() {
+		t.Fatal("half-open breaker rejected the trial call")
+	}
+	b.Release()
+	if got := b.State(); got != StateHalfOpen {
+		t.Fatalf("after release: state %s, want half_open", got)
+	}
+	if !b.Allow() {
+		t.Fatal("released trial slot was not handed out again")
+	}
+}
+
+func testRoutes() []Route {
+	return []Route{
+		{Client: "Primary", Path: PathSonnet, Breaker: NewBreaker("Primary", 1, time.Hour)},
+		{Client: "Backup", Path: PathHaiku, Breaker: NewBreaker("Backup", 1, time.Hour)},
+	}
+}
+
+func TestRoutesSonnetThenHaiku(t *testing.T) {
+	want := []struct{ client, path string }{
+		{"ClaudeSonnet", PathSonnet},
+		{"ClaudeHaiku", PathHaiku},
+	}
+	got := Routes()
+	if len(got) != len(want) {
+		t.Fatalf("%d routes, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].Client != w.client || got[i].Path != w.path {
+			t.Errorf("route %d = %s (%s), want %s (%s)", i, got[i].Client, got[i].Path, w.client, w.path)
+		}
+		if got[i].Breaker == nil {
+			t.Errorf("route %d has no breaker", i)
+		}
+	}
+}
+
+func TestFallbackUsesPrimary(t *testing.T) {
+	routes := testRoutes()
+	var tried []string
+	route, err := Fallback(context.Background(), routes, func(r Route) error {
+		tried = append(tried, r.Client)
+		return nil
+	})
+	if err != nil || route.Client != "Primary" {
+		t.Fatalf("Fallback = %s, %v; want Primary", route.Client, err)
+	}
+	if len(tried) != 1 {
+		t.Fatalf("tried %v, want only the primary", tried)
+	}
+}
+
+func TestFallbackFallsBack(t *testing.T) {
+	routes := testRoutes()
+	route, err := Fallback(context.Background(), routes, func(r Route) error {
+		if r.Client == "Primary" {
+			return errors.New("overloaded")
+		}
+		return nil
+	})
+	if err != nil || route.Client != "Backup" {
+		t.Fatalf("Fallback = %s, %v; want Backup", route.Client, err)
+	}
+	if got := routes[0].Breaker.State(); got != StateOpen {
+		t.Fatalf("primary breaker %s, want open", got)
+	}
+
+	// With the primary's circuit open it isn't tried at all
+	var tried []string
+	Fallback(context.Background(), routes, func(r Route) error {
+		tried = append(tried, r.Client)
+		return nil
+	})
+	if len(tried) != 1 || tried[0] != "Backup" {
+		t.Fatalf("tried %v, want only Backup", tried)
+	}
+}
+
+func TestFallbackOfflineWhenAllFail(t *testing.T) {
+	routes := testRoutes()
+	fail := func(Route) error { return errors.New("down") }
+	if _, err := Fallback(context.Background(), routes, fail); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("err = %v, want ErrUnavailable", err)
+	}
+
+	// Every circuit is now open, so nothing is called
+	called := false
+	_, err := Fallback(context.Background(), routes, func(Route) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, ErrUnavailable) || called {
+		t.Fatalf("err = %v, called = %v; want ErrUnavailable without a call", err, called)
+	}
+}
+
+func TestFallbackContextDoneIsNotAFailure(t *testing.T) {
+	routes := testRoutes()
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := Fallback(ctx, routes, func(Route) error {
+		cancel()
+		return context.Canceled
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	for _, r := range routes {
+		if got := r.Breaker.State(); got != StateClosed {
+			t.Fatalf("%s breaker %s, want closed", r.Client, got)
+		}
+	}
+}
@@ -4,20 +4,36 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"slices"
 
+	"github.com/johnhkchen/resume-tweaker/batch"
+	"github.com/johnhkchen/resume-tweaker/extract"
 	"github.com/johnhkchen/resume-tweaker/handlers"
+	"github.com/johnhkchen/resume-tweaker/jobboard"
+	"github.com/johnhkchen/resume-tweaker/jobs"
+	"github.com/johnhkchen/resume-tweaker/profile"
+	"github.com/johnhkchen/resume-tweaker/quota"
+	"github.com/johnhkchen/resume-tweaker/settings"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 // setupCollections creates the resumes collection if it doesn't exist
 func setupCollections(app core.App) error {
 	// Check if resumes collection exists
-	_, err := app.FindCollectionByNameOrId("resumes")
+	existing, err := app.FindCollectionByNameOrId("resumes")
 	if err == nil {
 		log.Println("[Setup] resumes collection already exists")
+		if updateResumeFields(existing) {
+			log.Println("[Setup] Updating resumes collection fields...")
+			if err := app.Save(existing); err != nil {
+				return err
+			}
+			return backfillUploadKind(app)
+		}
 		return nil
 	}
 
@@ -51,13 +67,14 @@ func setupCollections(app core.App) error {
 		Name:     "tweaked_content",
 		Required: false,
 	})
+	updateResumeFields(collection)
 
 	// Set API rules - users can only access their own resumes
-	collection.ListRule = ptrStr(`@request.auth.id != "" && user = @request.auth.id`)
-	collection.ViewRule = ptrStr(`@request.auth.id != "" && user = @request.auth.id`)
-	collection.CreateRule = ptrStr(`@request.auth.id != ""`)
-	collection.UpdateRule = ptrStr(`@request.auth.id != "" && user = @request.auth.id`)
-	collection.DeleteRule = ptrStr(`@request.auth.id != "" && user = @request.auth.id`)
+	collection.ListRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+	collection.ViewRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+	collection.CreateRule = types.Pointer(`@request.auth.id != ""`)
+	collection.UpdateRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+	collection.DeleteRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
 
 	if err := app.Save(collection); err != nil {
 		return err
@@ -67,8 +84,63 @@ func setupCollections(app core.App) error {
 	return nil
 }
 
-func ptrStr(s string) *string {
-	return &s
+// updateResumeFields lets resumes hold uploads and finished tweaks: a kind
+// telling them apart, a protected file field for an uploaded original, room
+// for long resumes and results, and no job description requirement since
+// there isn't one at upload time or when translating. It reports whether
+// the collection changed.
+func updateResumeFields(collection *core.Collection) bool {
+	changed := false
+	kinds := []string{handlers.ResumeKindTweak, handlers.ResumeKindUpload}
+	kind, ok := collection.Fields.GetByName("kind").(*core.SelectField)
+	if !ok {
+		kind = &core.SelectField{Name: "kind", MaxSelect: 1}
+		collection.Fields.Add(kind)
+		changed = true
+	}
+	if !slices.Equal(kind.Values, kinds) {
+		kind.Values = kinds
+		changed = true
+	}
+	file, ok := collection.Fields.GetByName("source_file").(*core.FileField)
+	if !ok {
+		file = &core.FileField{Name: "source_file", MaxSelect: 1, Protected: true}
+		collection.Fields.Add(file)
+		changed = true
+	}
+	if file.MaxSize != extract.MaxSize || !slices.Equal(file.MimeTypes, extract.MimeTypes) {
+		file.MaxSize = extract.MaxSize
+		file.MimeTypes = slices.Clone(extract.MimeTypes)
+		changed = true
+	}
+	if f, ok := collection.Fields.GetByName("original_content").(*core.TextField); ok && f.Max < handlers.MaxInputBytes {
+		f.Max = handlers.MaxInputBytes
+		changed = true
+	}
+	if f, ok := collection.Fields.GetByName("tweaked_content").(*core.TextField); ok && f.Max < jobs.MaxResultChars {
+		f.Max = jobs.MaxResultChars
+		changed = true
+	}
+	if f, ok := collection.Fields.GetByName("job_description").(*core.TextField); ok && f.Required {
+		f.Required = false
+		changed = true
+	}
+	return changed
+}
+
+// backfillUploadKind marks uploads saved before resumes had a kind: records
+// with a source file and no result
+func backfillUploadKind(app core.App) error {
+	res, err := app.DB().NewQuery(
+		"UPDATE resumes SET kind = {:upload} WHERE kind = '' AND source_file != '' AND tweaked_content = ''",
+	).Bind(map[string]any{"upload": handlers.ResumeKindUpload}).Execute()
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		log.Printf("[Setup] Marked %d existing resumes as uploads", n)
+	}
+	return nil
 }
 
 // cookieToAuthHeader middleware reads pb_auth cookie and sets Authorization header
@@ -108,6 +180,25 @@ func main() {
 		if err := setupCollections(app); err != nil {
 			log.Printf("[Setup] Warning: failed to setup collections: %v", err)
 		}
+		if err := quota.SetupCollections(app); err != nil {
+			log.Printf("[Setup] Warning: failed to setup quota collections: %v", err)
+		}
+		if err := settings.SetupCollections(app); err != nil {
+			log.Printf("[Setup] Warning: failed to setup settings collection: %v", err)
+		}
+		if err := jobs.SetupCollections(app); err != nil {
+			log.Printf("[Setup] Warning: failed to setup tweak_jobs collection: %v", err)
+		}
+		if err := jobboard.SetupCollections(app); err != nil {
+			log.Printf("[Setup] Warning: failed to setup saved_jobs collection: %v", err)
+		}
+		jobboard.ScheduleRefresh(app)
+		if err := profile.SetupCollections(app); err != nil {
+			log.Printf("[Setup] Warning: failed to setup profiles collection: %v", err)
+		}
+		if err := batch.SetupCollections(app); err != nil {
+			log.Printf("[Setup] Warning: failed to setup batch collections: %v", err)
+		}
 
 		// Configure GitHub OAuth from env vars
 		if clientId := os.Getenv("GITHUB_CLIENT_ID"); clientId != "" {
@@ -180,12 +271,46 @@ func main() {
 		appRoutes.BindFunc(requireAuthWithRedirect) // Check if authenticated, redirect if not
 		appRoutes.GET("/tweak", handlers.HandleTweakPagePB)
 		appRoutes.POST("/tweak/stream", handlers.HandleTweakStreamPB)
+		appRoutes.GET("/tweak/jobs/{id}/stream", handlers.HandleTweakJobStreamPB)
+		appRoutes.POST("/resumes/upload", handlers.HandleResumeUploadPB)
+		appRoutes.POST("/profile/resume", handlers.HandleProfileResumePB)
+		appRoutes.POST("/job-descriptions/import", handlers.HandleJobImportPB)
+		appRoutes.POST("/job-boards/list", handlers.HandleJobBoardListPB)
+		appRoutes.POST("/job-boards/save", handlers.HandleJobBoardSavePB)
+		appRoutes.POST("/jobs/{id}/use", handlers.HandleSavedJobUsePB)
+		appRoutes.GET("/resumes/{id}/export.pdf", handlers.HandleExportPDFPB)
+		appRoutes.GET("/resumes/{id}/export.docx", handlers.HandleExportDOCXPB)
+		appRoutes.GET("/resumes/{id}/export.json", handlers.HandleExportJSONPB)
+		appRoutes.GET("/resumes/{id}/export.tex", handlers.HandleExportLaTeXPB)
+		appRoutes.GET("/resumes/{id}/export.zip", handlers.HandleExportLaTeXZipPB)
+		appRoutes.GET("/batches", handlers.HandleBatchesPagePB)
+		appRoutes.POST("/batches", handlers.HandleCreateBatchPB)
+		appRoutes.GET("/batches/{id}", handlers.HandleBatchPagePB)
+		appRoutes.GET("/batches/{id}/stream", handlers.HandleBatchStreamPB)
+		appRoutes.POST("/batches/{id}/retry", handlers.HandleRetryBatchPB)
+		appRoutes.GET("/batches/{id}/export.zip", handlers.HandleBatchExportPB)
 
 		// API routes for saving data
 		api := se.Router.Group("/api/v1")
 		api.Bind(apis.RequireAuth())
 		api.POST("/resumes", handlers.HandleCreateResumePB)
 		api.GET("/resumes", handlers.HandleListResumesPB)
+		api.GET("/jobs", handlers.HandleListJobsPB)
+		api.GET("/profile", handlers.HandleGetProfilePB)
+		api.GET("/batches", handlers.HandleListBatchesPB)
+		api.POST("/batches", handlers.HandleCreateBatchAPIPB)
+		api.GET("/batches/{id}", handlers.HandleGetBatchPB)
+		api.GET("/quota", handlers.HandleQuotaStatusPB)
+		api.GET("/settings", handlers.HandleGetSettingsPB)
+		api.PUT("/settings", handlers.HandleUpdateSettingsPB)
+
+		// Operational metrics (superusers only)
+		admin := se.Router.Group("/api/v1/admin")
+		admin.Bind(apis.RequireSuperuserAuth())
+		admin.GET("/llm-queue", handlers.HandleLLMQueueStatsPB)
+		admin.POST("/jobs/refresh", handlers.HandleRefreshJobsPB)
+		admin.PUT("/quotas/{userId}", handlers.HandleSetQuotaOverridePB)
+		admin.DELETE("/quotas/{userId}", handlers.HandleClearQuotaOverridePB)
 
 		return se.Next()
 	})
@@ -0,0 +1,271 @@
+// Package markdown renders the small markdown subset the models write
+// (headings, lists, emphasis, code, links, rules) to HTML.
+//
+// Every piece of source text is HTML-escaped before it is wrapped in tags,
+// so the output only ever contains markup this package emits itself. It is
+// safe to render partial documents mid-stream: unclosed emphasis is left as
+// literal text until its closing marker arrives.
+//
+// Pages should call Render rather than ToHTML: it also runs the output
+// through Sanitize, an allowlist filter that catches anything the renderer
+// gets wrong and can be applied to HTML from other sources.
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	headingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	bulletRe  = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	orderedRe = regexp.MustCompile(`^(\s*)\d{1,9}[.)]\s+(.*)$`)
+	ruleRe    = regexp.MustCompile(`^\s{0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	fenceRe   = regexp.MustCompile("^\\s*(```|~~~)")
+	quoteRe   = regexp.MustCompile(`^\s*>\s?(.*)$`)
+)
+
+// ToHTML renders markdown source to HTML
+func ToHTML(src string) string {
+	r := renderer{}
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if m := fenceRe.FindStringSubmatch(line); m != nil {
+			r.closeBlocks()
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), m[1]) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			r.b.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			r.closeBlocks()
+			continue
+		}
+
+		if ruleRe.MatchString(line) {
+			r.closeBlocks()
+			r.b.WriteString("<hr>\n")
+			continue
+		}
+
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			r.closeBlocks()
+			tag := "h" + string(rune('0'+len(m[1])))
+			r.b.WriteString("<" + tag + ">" + Inline(m[2]) + "</" + tag + ">\n")
+			continue
+		}
+
+		if m := bulletRe.FindStringSubmatch(line); m != nil {
+			r.listItem("ul", len(m[1]), m[2])
+			continue
+		}
+		if m := orderedRe.FindStringSubmatch(line); m != nil {
+			r.listItem("ol", len(m[1]), m[2])
+			continue
+		}
+
+		if m := quoteRe.FindStringSubmatch(line); m != nil {
+			r.closeList()
+			if !r.inQuote {
+				r.closeParagraph()
+				r.b.WriteString("<blockquote>")
+				r.inQuote = true
+			} else {
+				r.b.WriteString("<br>")
+			}
+			r.b.WriteString(Inline(m[1]))
+			continue
+		}
+
+		// Indented continuation of a list item
+		if len(r.lists) > 0 && strings.HasPrefix(line, " ") {
+			r.b.WriteString(" " + Inline(strings.TrimSpace(line)))
+			continue
+		}
+
+		r.closeList()
+		r.closeQuote()
+		if r.inParagraph {
+			r.b.WriteString("<br>")
+		} else {
+			r.b.WriteString("<p>")
+			r.inParagraph = true
+		}
+		r.b.WriteString(Inline(strings.TrimSpace(line)))
+	}
+
+	r.closeBlocks()
+	return r.b.String()
+}
+
+// renderer tracks open block elements
+type renderer struct {
+	b           strings.Builder
+	lists       []list
+	inParagraph bool
+	inQuote     bool
+}
+
+// list is an open <ul> or <ol> at an indent level
+type list struct {
+	tag    string
+	indent int
+}
+
+func (r *renderer) listItem(tag string, indent int, text string) {
+	r.closeParagraph()
+	r.closeQuote()
+
+	// Close deeper lists, and a sibling list of a different kind
+	for len(r.lists) > 0 {
+		top := r.lists[len(r.lists)-1]
+		if top.indent > indent || (top.indent == indent && top.tag != tag) {
+			r.popList()
+			continue
+		}
+		break
+	}
+
+	if len(r.lists) == 0 || r.lists[len(r.lists)-1].indent < indent {
+		r.b.WriteString("<" + tag + ">")
+		r.lists = append(r.lists, list{tag: tag, indent: indent})
+	} else {
+		r.b.WriteString("</li>")
+	}
+	r.b.WriteString("<li>" + Inline(text))
+}
+
+func (r *renderer) popList() {
+	top := r.lists[len(r.lists)-1]
+	r.lists = r.lists[:len(r.lists)-1]
+	r.b.WriteString("</li></" + top.tag + ">")
+	if len(r.lists) == 0 {
+		r.b.WriteString("\n")
+	}
+}
+
+func (r *renderer) closeList() {
+	for len(r.lists) > 0 {
+		r.popList()
+	}
+}
+
+func (r *renderer) closeParagraph() {
+	if r.inParagraph {
+		r.b.WriteString("</p>\n")
+		r.inParagraph = false
+	}
+}
+
+func (r *renderer) closeQuote() {
+	if r.inQuote {
+		r.b.WriteString("</blockquote>\n")
+		r.inQuote = false
+	}
+}
+
+func (r *renderer) closeBlocks() {
+	r.closeParagraph()
+	r.closeQuote()
+	r.closeList()
+}
+
+var (
+	codeSpanRe = regexp.MustCompile("`([^`]+)`")
+	linkRe     = regexp.MustCompile(`\[([^\]]+)\]\(([^()\s]+)\)`)
+	autolinkRe = regexp.MustCompile(`(?:https?://|mailto:)[^\s<>"']+[^\s<>"'.,;:!?)]`)
+	strongRe   = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__`)
+	emRe       = regexp.MustCompile(`\*(\S(?:.*?\S)?)\*|\b_(\S(?:.*?\S)?)_\b`)
+)
+
+// Inline renders inline markdown (code, links, bold, italic) in a single
+// line of text. The text is escaped first, so only the emitted tags are
+// markup. Links with a scheme other than http, https or mailto are left as
+// plain text.
+func Inline(text string) string {
+	// Pull code spans and links out first so their contents aren't
+	// formatted. NUL bytes delimit the placeholders, so drop any in the
+	// source.
+	text = strings.ReplaceAll(text, "\x00", "")
+	var spans []string
+	hold := func(span string) string {
+		spans = append(spans, span)
+		return "\x00" + strconv.Itoa(len(spans)-1) + "\x00"
+	}
+
+	text = codeSpanRe.ReplaceAllStringFunc(text, func(m string) string {
+		return hold("<code>" + html.EscapeString(m[1:len(m)-1]) + "</code>")
+	})
+	text = linkRe.ReplaceAllStringFunc(text, func(m string) string {
+		sub := linkRe.FindStringSubmatch(m)
+		if !SafeURL(sub[2]) {
+			return m
+		}
+		return hold(link(sub[2], emphasis(html.EscapeString(sub[1]))))
+	})
+	text = autolinkRe.ReplaceAllStringFunc(text, func(m string) string {
+		if !SafeURL(m) {
+			return m
+		}
+		return hold(link(m, html.EscapeString(m)))
+	})
+
+	text = emphasis(html.EscapeString(text))
+
+	for i, span := range spans {
+		text = strings.Replace(text, "\x00"+strconv.Itoa(i)+"\x00", span, 1)
+	}
+	return text
+}
+
+// emphasis wraps bold and italic markers in already-escaped text
+func emphasis(text string) string {
+	text = strongRe.ReplaceAllString(text, "<strong>$1$2</strong>")
+	return emRe.ReplaceAllString(text, "<em>$1$2</em>")
+}
+
+// link renders an anchor to a URL already checked with SafeURL
+func link(href, label string) string {
+	return `<a href="` + html.EscapeString(href) + `" rel="noopener noreferrer nofollow" target="_blank">` + label + "</a>"
+}
+
+// StableLength returns how many bytes at the start of a partial document
+// are complete blocks: everything up to the last blank line outside a code
+// fence. Text appended later can't change how those blocks render, so
+// ToHTML(src[:n]) + ToHTML(src[n:]) == ToHTML(src).
+func StableLength(src string) int {
+	stable, offset := 0, 0
+	var fence string
+	for _, line := range strings.SplitAfter(src, "\n") {
+		offset += len(line)
+		if !strings.HasSuffix(line, "\n") {
+			// Unterminated last line
+			break
+		}
+		if fence != "" {
+			if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				fence = ""
+			}
+			continue
+		}
+		if m := fenceRe.FindStringSubmatch(line); m != nil {
+			fence = m[1]
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			stable = offset
+		}
+	}
+	return stable
+}
@@ -0,0 +1,102 @@
+package markdown
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	const rel = ` rel="noopener noreferrer nofollow" target="_blank"`
+	tests := []struct {
+		name, in, want string
+	}{
+		// Scripts and event handlers
+		{"script", `<p>Hi<script>alert(1)</script></p>`, `<p>Hi</p>`},
+		{"img onerror", `<img src=x onerror=alert(1)>Hi`, `Hi`},
+		{"split script tag", `<scr<script>ipt>alert(1)</script>`, `ipt&gt;alert(1)`},
+
+		// Link schemes
+		{"javascript link", `<a href="javascript:alert(1)">x</a>`, `<a>x</a>`},
+		{"data link", `<a href="data:text/html;base64,PHNjcmlwdD4=">x</a>`, `<a>x</a>`},
+		{"vbscript link", `<a href="vbscript:msgbox(1)">x</a>`, `<a>x</a>`},
+		{"mixed-case scheme", `<a href="JaVaScRiPt:alert(1)">x</a>`, `<a>x</a>`},
+		{"decimal entity scheme", `<a href="&#106;avascript:alert(1)">x</a>`, `<a>x</a>`},
+		{"hex entity scheme", `<a href="&#x6A;avascript&colon;alert(1)">x</a>`, `<a>x</a>`},
+		{"tab in scheme", `<a href="java&#x09;script:alert(1)">x</a>`, `<a>x</a>`},
+		{
+			"https link",
+			`<a href=" HTTPS://example.com/a?b=1&amp;c=2 " onclick="x()" title="t">x</a>`,
+			`<a href="HTTPS://example.com/a?b=1&amp;c=2" title="t"` + rel + `>x</a>`,
+		},
+		{"mailto link", `<a href="mailto:jane@example.com">mail</a>`, `<a href="mailto:jane@example.com"` + rel + `>mail</a>`},
+
+		// Attributes
+		{
+			"data and on attributes",
+			`<p data-on-load="@get('/x')" data-text="$x" onclick="x()" class="c" style="color:red">Hi</p>`,
+			`<p>Hi</p>`,
+		},
+
+		// Dropped elements and their contents
+		{"svg", `<svg onload=alert(1)><script>alert(1)</script><text>x</text></svg>After`, `After`},
+		{"unclosed svg", `<svg><p>inside</p>`, ``},
+		{"iframe", `<iframe src="https://evil.example"></iframe>After`, `After`},
+		{"nested iframe", `<iframe><iframe></iframe>inner</iframe>After`, `innerAfter`},
+		{"comment", `<!-- comment --><p>x</p><br/><hr>`, `<p>x</p><br><hr>`},
+
+		// Balancing
+		{"misnested", `<strong><em>Bold italic</strong> text`, `<strong><em>Bold italic</em></strong> text`},
+		{"unclosed", `<p>Unclosed <strong>bold`, `<p>Unclosed <strong>bold</strong></p>`},
+		{"stray end tags", `<ul><li>One<li>Two</ul></p></div>`, `<ul><li>One<li>Two</li></li></ul>`},
+	}
+	for _, tt := range tests {
+		if got := Sanitize(tt.in); got != tt.want {
+			t.Errorf("%s: Sanitize(%q)\n got %q\nwant %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"script", `<script>alert(1)</script>`, "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{"img onerror", `<img src=x onerror=alert(1)>`, "<p>&lt;img src=x onerror=alert(1)&gt;</p>\n"},
+		{"javascript link", `[click](javascript:alert(1))`, "<p>[click](javascript:alert(1))</p>\n"},
+		{"mixed-case scheme", `[click](JAVASCRIPT:alert(1))`, "<p>[click](JAVASCRIPT:alert(1))</p>\n"},
+		{"data link", `[click](data:text/html,hi)`, "<p>[click](data:text/html,hi)</p>\n"},
+		{"vbscript link", `[click](vbscript:msgbox)`, "<p>[click](vbscript:msgbox)</p>\n"},
+		{"entity scheme", `[click](&#106;avascript:alert(1))`, "<p>[click](&amp;#106;avascript:alert(1))</p>\n"},
+		{
+			"quoted href",
+			`[site](https://example.com/?q="x")`,
+			`<p><a href="https://example.com/?q=&#34;x&#34;" rel="noopener noreferrer nofollow" target="_blank">site</a></p>` + "\n",
+		},
+		{"inline html", `**bold <em>x</em>**`, "<p><strong>bold &lt;em&gt;x&lt;/em&gt;</strong></p>\n"},
+		{"data attribute", `- a <div data-on-click="x">b</div>`, "<ul><li>a &lt;div data-on-click=&#34;x&#34;&gt;b&lt;/div&gt;</li></ul>\n"},
+	}
+	for _, tt := range tests {
+		if got := Render(tt.in); got != tt.want {
+			t.Errorf("%s: Render(%q)\n got %q\nwant %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestStableLength(t *testing.T) {
+	tests := []struct {
+		src  string
+		want int
+	}{
+		{"# Jane", 0},
+		{"# Jane\n\nBuilt", len("# Jane\n\n")},
+		{"# Jane\n\n```\ncode\n\nmore", len("# Jane\n\n")},
+		{"```\ncode\n\n```\n\nAfter", len("```\ncode\n\n```\n\n")},
+	}
+	for _, tt := range tests {
+		n := StableLength(tt.src)
+		if n != tt.want {
+			t.Errorf("StableLength(%q) = %d, want %d", tt.src, n, tt.want)
+			continue
+		}
+		if got, want := ToHTML(tt.src[:n])+ToHTML(tt.src[n:]), ToHTML(tt.src); got != want {
+			t.Errorf("split render of %q = %q, want %q", tt.src, got, want)
+		}
+	}
+}
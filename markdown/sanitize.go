@@ -0,0 +1,156 @@
+package markdown
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// allowedTags maps each element Sanitize keeps to the attributes it may
+// carry. Everything else is stripped - notably every data-* attribute, which
+// Datastar would otherwise evaluate as an expression.
+var allowedTags = map[string][]string{
+	"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil,
+	"p": nil, "br": nil, "hr": nil,
+	"ul": nil, "ol": nil, "li": nil,
+	"strong": nil, "b": nil, "em": nil, "i": nil,
+	"code": nil, "pre": nil, "blockquote": nil,
+	"a": {"href", "title"},
+}
+
+// droppedTags are removed together with everything inside them
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true,
+	"embed": true, "template": true, "noscript": true, "textarea": true,
+	"title": true, "svg": true, "math": true, "select": true,
+}
+
+// voidTags never have an end tag
+var voidTags = map[string]bool{"br": true, "hr": true}
+
+// Render converts markdown to HTML that is safe to insert into a page. Use
+// it for anything user-visible: results, history and share pages.
+func Render(src string) string {
+	return Sanitize(ToHTML(src))
+}
+
+// Sanitize filters HTML down to the allowlisted elements and attributes,
+// escaping all text. Links must be http, https or mailto and are rewritten
+// to open in a new tab with rel="noopener noreferrer nofollow". Tags are
+// balanced, so an unclosed element can't swallow the rest of the page.
+func Sanitize(src string) string {
+	z := html.NewTokenizer(strings.NewReader(src))
+	var b strings.Builder
+	var open []string
+	dropping := 0
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				b.WriteString("</" + open[i] + ">")
+			}
+			return b.String()
+
+		case html.TextToken:
+			if dropping == 0 {
+				b.WriteString(html.EscapeString(string(z.Text())))
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if droppedTags[tok.Data] {
+				if tt == html.StartTagToken {
+					dropping++
+				}
+				continue
+			}
+			attrs, ok := allowedTags[tok.Data]
+			if dropping > 0 || !ok {
+				continue
+			}
+			b.WriteString(startTag(tok, attrs))
+			if !voidTags[tok.Data] && tt == html.StartTagToken {
+				open = append(open, tok.Data)
+			}
+
+		case html.EndTagToken:
+			tok := z.Token()
+			if droppedTags[tok.Data] {
+				if dropping > 0 {
+					dropping--
+				}
+				continue
+			}
+			if dropping > 0 {
+				continue
+			}
+			// Close back to the matching open tag; ignore stray end tags
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != tok.Data {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+		// Comments and doctypes are dropped
+	}
+}
+
+// startTag writes an allowlisted start tag with only its permitted attributes
+func startTag(tok html.Token, allowed []string) string {
+	var b strings.Builder
+	b.WriteString("<" + tok.Data)
+
+	link := false
+	for _, attr := range tok.Attr {
+		if !contains(allowed, attr.Key) {
+			continue
+		}
+		if attr.Key == "href" {
+			if !SafeURL(attr.Val) {
+				continue
+			}
+			attr.Val = strings.TrimSpace(attr.Val)
+			link = true
+		}
+		b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	if link {
+		b.WriteString(` rel="noopener noreferrer nofollow" target="_blank"`)
+	}
+
+	b.WriteString(">")
+	return b.String()
+}
+
+// SafeURL reports whether a link target uses an allowed scheme (http,
+// https or mailto)
+func SafeURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.Host != ""
+	case "mailto":
+		return u.Opaque != ""
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+// Package offline produces rule-based resume suggestions when no LLM is
+// reachable. It compares job description keywords against the resume and
+// flags common weaknesses in bullet points.
+package offline
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxKeywords caps how many job description keywords are considered
+const maxKeywords = 15
+
+var (
+	wordPattern   = regexp.MustCompile(`[A-Za-z][A-Za-z0-9+#./-]*[A-Za-z0-9+#]|[A-Za-z]`)
+	numberPattern = regexp.MustCompile(`\d`)
+	bulletPattern = regexp.MustCompile(`^\s*([-*•]|\d+[.)])\s+`)
+)
+
+// weakPhrases maps passive resume phrasing to stronger alternatives
+var weakPhrases = map[string]string{
+	"responsible for": "Led / Owned",
+	"worked on":       "Built / Delivered",
+	"helped":          "Drove / Enabled",
+	"assisted with":   "Contributed to",
+	"involved in":     "Executed",
+	"duties included": "Delivered",
+}
+
+var stopwords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`a about above across after again all also an and any are as at
+		be been before being below between both but by can could did do does doing down during each
+		etc every few for from further had has have having he her here hers him his how i if in into
+		is it its itself just least less like made make many may me might more most much must my no
+		nor not now of off on once only or other our ours out over own per plus role same she should
+		so some such than that the their them then there these they this those through to too under
+		until up upon us very via was we well were what when where which while who whom why will with
+		within without would you your yours able ability across candidate candidates company experience
+		experiences including include includes job jobs looking new position preferred required
+		requirements responsibilities strong team teams work working years year you'll we're ideal
+		opportunity join skills skill knowledge understanding day days using use`) {
+		stopwords[w] = true
+	}
+}
+
+// Suggest builds markdown suggestions for tailoring resume to jobDesc
+func Suggest(resume, jobDesc string) string {
+	keywords := Keywords(jobDesc)
+	resumeLower := strings.ToLower(resume)
+
+	var missing, covered []string
+	for _, kw := range keywords {
+		if containsWord(resumeLower, strings.ToLower(kw)) {
+			covered = append(covered, kw)
+		} else {
+			missing = append(missing, kw)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("## Quick Suggestions\n\n")
+	b.WriteString("*Our AI service is temporarily unavailable, so these suggestions come from a keyword analysis of the job description. Try again in a few minutes for a full rewrite.*\n\n")
+
+	if len(keywords) > 0 {
+		fmt.Fprintf(&b, "**Keyword match:** %d of %d key terms from the job description appear in your resume.\n\n", len(covered), len(keywords))
+	}
+
+	if len(missing) > 0 {
+		b.WriteString("### Keywords to work in\n\n")
+		b.WriteString("Add these where they honestly reflect your experience:\n\n")
+		for _, kw := range missing {
+			fmt.Fprintf(&b, "- %s\n", kw)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(covered) > 0 {
+		b.WriteString("### Already covered\n\n")
+		b.WriteString("Make sure these are prominent, ideally in your summary or first bullets:\n\n")
+		for _, kw := range covered {
+			fmt.Fprintf(&b, "- %s\n", kw)
+		}
+		b.WriteString("\n")
+	}
+
+	if tips := bulletTips(resume); len(tips) > 0 {
+		b.WriteString("### Strengthen your bullets\n\n")
+		for _, tip := range tips {
+			fmt.Fprintf(&b, "- %s\n", tip)
+		}
+		b.WriteString("\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// Keywords returns the most frequent meaningful terms in text, keeping the
+// casing of their first appearance (so "TypeScript" stays "TypeScript")
+func Keywords(text string) []string {
+	type term struct {
+		display string
+		count   int
+		first   int
+	}
+	terms := map[string]*term{}
+
+	for i, word := range wordPattern.FindAllString(text, -1) {
+		word = strings.TrimRight(word, ".-/")
+		key := strings.ToLower(word)
+		if len(key) < 2 || stopwords[key] {
+			continue
+		}
+		// Single lowercase words need to be reasonably specific to count
+		if len(key) < 4 && !hasUpperOrSymbol(word) {
+			continue
+		}
+		t, ok := terms[key]
+		if !ok {
+			t = &term{display: word, first: i}
+			terms[key] = t
+		}
+		t.count++
+		// Technical terms are usually capitalized; weight them up
+		if hasUpperOrSymbol(word) {
+			t.count++
+		}
+	}
+
+	list := make([]*term, 0, len(terms))
+	for _, t := range terms {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].count != list[j].count {
+			return list[i].count > list[j].count
+		}
+		return list[i].first < list[j].first
+	})
+
+	var keywords []string
+	for _, t := range list {
+		if len(keywords) == maxKeywords {
+			break
+		}
+		keywords = append(keywords, t.display)
+	}
+	return keywords
+}
+
+// bulletTips flags unquantified bullets and weak phrasing
+func bulletTips(resume string) []string {
+	var tips []string
+
+	bullets, unquantified := 0, 0
+	for _, line := range strings.Split(resume, "\n") {
+		if !bulletPattern.MatchString(line) {
+			continue
+		}
+		bullets++
+		if !numberPattern.MatchString(line) {
+			unquantified++
+		}
+	}
+	if bullets > 0 && unquantified > bullets/2 {
+		tips = append(tips, fmt.Sprintf("%d of %d bullets have no numbers. Add metrics such as %%, $, time saved or team size.", unquantified, bullets))
+	}
+
+	lower := strings.ToLower(resume)
+	phrases := make([]string, 0, len(weakPhrases))
+	for phrase := range weakPhrases {
+		phrases = append(phrases, phrase)
+	}
+	sort.Strings(phrases)
+	for _, phrase := range phrases {
+		if strings.Contains(lower, phrase) {
+			tips = append(tips, fmt.Sprintf("Replace \"%s\" with an action verb (%s).", phrase, weakPhrases[phrase]))
+		}
+	}
+
+	if bullets == 0 {
+		tips = append(tips, "Use bullet points for each role so achievements are easy to scan.")
+	}
+	return tips
+}
+
+func containsWord(haystack, word string) bool {
+	for start := 0; ; {
+		i := strings.Index(haystack[start:], word)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(word)
+		before := i == 0 || !isWordRune(rune(haystack[i-1]))
+		after := end == len(haystack) || !isWordRune(rune(haystack[end]))
+		if before && after {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func hasUpperOrSymbol(word string) bool {
+	for _, r := range word {
+		if unicode.IsUpper(r) || strings.ContainsRune("+#.", r) {
+			return true
+		}
+	}
+	return false
+}
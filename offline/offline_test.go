@@ -0,0 +1,72 @@
+package offline
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testJob = `Senior Backend Engineer
+We use Go, PostgreSQL and Kubernetes. Go experience is a must; Kubernetes
+in production is a plus. You will mentor engineers.`
+
+const testResume = `Jane Doe
+- Responsible for Go services handling 2M requests a day
+- Worked on PostgreSQL migrations
+- Helped the team`
+
+func TestKeywords(t *testing.T) {
+	got := Keywords(testJob)
+	for _, want := range []string{"Go", "PostgreSQL", "Kubernetes"} {
+		if !slices.Contains(got, want) {
+			t.Errorf("Keywords missing %q: %v", want, got)
+		}
+	}
+	// Repeated, capitalised terms rank first
+	if got[0] != "Go" && got[0] != "Kubernetes" {
+		t.Errorf("top keyword %q, want Go or Kubernetes", got[0])
+	}
+	for _, stop := range []string{"We", "use", "will", "You"} {
+		if slices.Contains(got, stop) {
+			t.Errorf("Keywords kept stopword %q", stop)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	got := Suggest(testResume, testJob)
+
+	section := func(heading string) string {
+		_, rest, ok := strings.Cut(got, "### "+heading+"\n")
+		if !ok {
+			t.Fatalf("no %q section in:\n%s", heading, got)
+		}
+		body, _, _ := strings.Cut(rest, "### ")
+		return body
+	}
+
+	if missing := section("Keywords to work in"); !strings.Contains(missing, "- Kubernetes\n") || strings.Contains(missing, "- Go\n") {
+		t.Errorf("missing keywords section wrong:\n%s", missing)
+	}
+	if covered := section("Already covered"); !strings.Contains(covered, "- Go\n") || !strings.Contains(covered, "- PostgreSQL\n") {
+		t.Errorf("covered keywords section wrong:\n%s", covered)
+	}
+	tips := section("Strengthen your bullets")
+	for _, want := range []string{
+		"2 of 3 bullets have no numbers",
+		`Replace "helped"`,
+		`Replace "responsible for"`,
+		`Replace "worked on"`,
+	} {
+		if !strings.Contains(tips, want) {
+			t.Errorf("tips missing %q:\n%s", want, tips)
+		}
+	}
+}
+
+func TestSuggestWithoutBullets(t *testing.T) {
+	got := Suggest("Jane Doe, Go developer", "Go developer")
+	if !strings.Contains(got, "Use bullet points") {
+		t.Errorf("no bullet-point tip for a resume without bullets:\n%s", got)
+	}
+}
@@ -0,0 +1,112 @@
+// Package profile stores each user's master profile: everything known about
+// their career as a JSON Resume, kept in the profiles collection. Uploads of
+// structured data (a LinkedIn export or a JSON Resume) replace it, and the
+// baseline resume for tweaking is rendered from it.
+package profile
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/johnhkchen/resume-tweaker/jsonresume"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Collection is the PocketBase collection holding master profiles
+const Collection = "profiles"
+
+// maxProfileSize bounds the stored JSON
+const maxProfileSize = 2 << 20
+
+// Profile is a user's master profile
+type Profile struct {
+	Resume jsonresume.Resume
+	// Source is where it came from: "linkedin" or "jsonresume"
+	Source string
+}
+
+// Get loads a user's master profile, reporting false if they have none
+func Get(app core.App, userID string) (Profile, bool) {
+	record, err := app.FindFirstRecordByData(Collection, "user", userID)
+	if err != nil {
+		return Profile{}, false
+	}
+	var r jsonresume.Resume
+	if err := record.UnmarshalJSONField("data", &r); err != nil {
+		log.Printf("[Profile] Warning: unreadable profile for %s: %v", userID, err)
+		return Profile{}, false
+	}
+	return Profile{Resume: r, Source: record.GetString("source")}, true
+}
+
+// Save creates or replaces a user's master profile
+func Save(app core.App, userID string, p Profile) error {
+	data, err := json.Marshal(p.Resume)
+	if err != nil {
+		return err
+	}
+	record, err := app.FindFirstRecordByData(Collection, "user", userID)
+	if err != nil {
+		collection, err := app.FindCollectionByNameOrId(Collection)
+		if err != nil {
+			return err
+		}
+		record = core.NewRecord(collection)
+		record.Set("user", userID)
+	}
+	record.Set("source", p.Source)
+	record.Set("data", json.RawMessage(data))
+	return app.Save(record)
+}
+
+// Markdown renders a master profile as the baseline resume
+func (p Profile) Markdown() string {
+	return jsonresume.Markdown(p.Resume)
+}
+
+// SetupCollections creates the profiles collection if it doesn't exist
+func SetupCollections(app core.App) error {
+	if _, err := app.FindCollectionByNameOrId(Collection); err == nil {
+		return nil
+	}
+
+	log.Printf("[Setup] Creating %s collection...", Collection)
+
+	usersCollection, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		return err
+	}
+
+	collection := core.NewBaseCollection(Collection)
+	collection.Fields.Add(&core.RelationField{
+		Name:          "user",
+		Required:      true,
+		CollectionId:  usersCollection.Id,
+		MaxSelect:     1,
+		CascadeDelete: true,
+	})
+	collection.Fields.Add(&core.TextField{
+		Name: "source",
+	})
+	collection.Fields.Add(&core.JSONField{
+		Name:    "data",
+		MaxSize: maxProfileSize,
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		OnCreate: true,
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	collection.AddIndex("idx_profiles_user", true, "user", "")
+
+	// Users can read their own profile; uploads write it
+	collection.ListRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+	collection.ViewRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+
+	return app.Save(collection)
+}
@@ -0,0 +1,56 @@
+package profile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/johnhkchen/resume-tweaker/jsonresume"
+	"github.com/johnhkchen/resume-tweaker/testapp"
+)
+
+func TestSaveReplacesProfile(t *testing.T) {
+	app := testapp.New(t, SetupCollections)
+	userID := testapp.User(t, app).Id
+
+	if _, ok := Get(app, userID); ok {
+		t.Fatal("Get found a profile before one was saved")
+	}
+
+	first := Profile{Source: "linkedin", Resume: jsonresume.Resume{
+		Basics: &jsonresume.Basics{Name: "Jane Doe"},
+		Work:   []jsonresume.Work{{Name: "Acme", Position: "Engineer", StartDate: "2020-03"}},
+	}}
+	if err := Save(app, userID, first); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := Get(app, userID)
+	if !ok || !reflect.DeepEqual(got, first) {
+		t.Fatalf("Get = %+v, %v, want %+v", got, ok, first)
+	}
+
+	second := Profile{Source: "jsonresume", Resume: jsonresume.Resume{
+		Basics: &jsonresume.Basics{Name: "Jane Doe", Email: "jane@example.com"},
+	}}
+	if err := Save(app, userID, second); err != nil {
+		t.Fatal(err)
+	}
+	got, ok = Get(app, userID)
+	if !ok || !reflect.DeepEqual(got, second) {
+		t.Fatalf("Get after replacing = %+v, %v, want %+v", got, ok, second)
+	}
+	if n, err := app.CountRecords(Collection); err != nil || n != 1 {
+		t.Fatalf("%d profiles after two saves (%v), want 1", n, err)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	p := Profile{Resume: jsonresume.Resume{
+		Basics: &jsonresume.Basics{Name: "Jane Doe"},
+		Skills: []jsonresume.Skill{{Name: "Go"}},
+	}}
+	md := p.Markdown()
+	if !strings.HasPrefix(md, "# Jane Doe\n") || !strings.Contains(md, "## Skills") {
+		t.Fatalf("Markdown =\n%s", md)
+	}
+}
@@ -0,0 +1,84 @@
+package quota
+
+import (
+	"log"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// SetupCollections creates the usage_events and quota_overrides collections
+// if they don't exist
+func SetupCollections(app core.App) error {
+	usersCollection, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		return err
+	}
+
+	if _, err := app.FindCollectionByNameOrId(UsageCollection); err != nil {
+		log.Printf("[Setup] Creating %s collection...", UsageCollection)
+
+		collection := core.NewBaseCollection(UsageCollection)
+		collection.Fields.Add(&core.RelationField{
+			Name:          "user",
+			Required:      true,
+			CollectionId:  usersCollection.Id,
+			MaxSelect:     1,
+			CascadeDelete: true,
+		})
+		collection.Fields.Add(&core.TextField{
+			Name: "endpoint",
+		})
+		collection.Fields.Add(&core.NumberField{
+			Name:    "tokens",
+			OnlyInt: true,
+		})
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+		collection.AddIndex("idx_usage_events_user_created", false, "user, created", "")
+
+		// Users can see their own usage; only the server writes it
+		collection.ListRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+		collection.ViewRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+	}
+
+	if _, err := app.FindCollectionByNameOrId(OverridesCollection); err != nil {
+		log.Printf("[Setup] Creating %s collection...", OverridesCollection)
+
+		collection := core.NewBaseCollection(OverridesCollection)
+		collection.Fields.Add(&core.RelationField{
+			Name:          "user",
+			Required:      true,
+			CollectionId:  usersCollection.Id,
+			MaxSelect:     1,
+			CascadeDelete: true,
+		})
+		collection.Fields.Add(&core.NumberField{
+			Name:    "tweaks_per_day",
+			OnlyInt: true,
+		})
+		collection.Fields.Add(&core.NumberField{
+			Name:    "tokens_per_month",
+			OnlyInt: true,
+		})
+		collection.Fields.Add(&core.TextField{
+			Name: "note",
+		})
+		collection.AddIndex("idx_quota_overrides_user", true, "user", "")
+
+		// Users can view their own override; only superusers can change it
+		collection.ViewRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,281 @@
+// Package quota enforces per-user LLM usage limits.
+//
+// Usage is recorded as one row per LLM request in the usage_events
+// collection and counted against daily tweak and monthly token limits.
+// Defaults come from the environment and can be overridden per user in the
+// quota_overrides collection (superusers only).
+package quota
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// UsageCollection stores one record per LLM request
+	UsageCollection = "usage_events"
+	// OverridesCollection stores per-user limit overrides
+	OverridesCollection = "quota_overrides"
+
+	// KindTweaksPerDay identifies the daily tweak limit
+	KindTweaksPerDay = "tweaks_per_day"
+	// KindTokensPerMonth identifies the monthly token limit
+	KindTokensPerMonth = "tokens_per_month"
+
+	defaultTweaksPerDay   = 20
+	defaultTokensPerMonth = 500000
+)
+
+// Limits are the quotas applied to a user. Zero means unlimited.
+type Limits struct {
+	TweaksPerDay   int64 `json:"tweaks_per_day"`
+	TokensPerMonth int64 `json:"tokens_per_month"`
+}
+
+// Status describes a user's current usage against their limits
+type Status struct {
+	Limits
+	TweaksUsed  int64     `json:"tweaks_used"`
+	TokensUsed  int64     `json:"tokens_used"`
+	TweaksReset time.Time `json:"tweaks_reset_at"`
+	TokensReset time.Time `json:"tokens_reset_at"`
+	Overridden  bool      `json:"overridden"`
+}
+
+// TweaksRemaining returns how many tweaks are left today, or -1 if unlimited
+func (s Status) TweaksRemaining() int64 {
+	if s.TweaksPerDay == 0 {
+		return -1
+	}
+	return max(s.TweaksPerDay-s.TweaksUsed, 0)
+}
+
+// TokensRemaining returns how many tokens are left this month, or -1 if unlimited
+func (s Status) TokensRemaining() int64 {
+	if s.TokensPerMonth == 0 {
+		return -1
+	}
+	return max(s.TokensPerMonth-s.TokensUsed, 0)
+}
+
+// ExceededError is returned when a user has run out of quota
+type ExceededError struct {
+	Kind    string
+	Limit   int64
+	Used    int64
+	ResetAt time.Time
+}
+
+func (e *ExceededError) Error() string {
+	switch e.Kind {
+	case KindTokensPerMonth:
+		return fmt.Sprintf("Monthly token quota exceeded (%d of %d used). Resets %s.", e.Used, e.Limit, e.ResetAt.Format(time.RFC1123))
+	default:
+		return fmt.Sprintf("Daily tweak limit reached (%d of %d used). Resets %s.", e.Used, e.Limit, e.ResetAt.Format(time.RFC1123))
+	}
+}
+
+// DefaultLimits reads the global limits from QUOTA_TWEAKS_PER_DAY and
+// QUOTA_TOKENS_PER_MONTH
+func DefaultLimits() Limits {
+	return Limits{
+		TweaksPerDay:   envInt("QUOTA_TWEAKS_PER_DAY", defaultTweaksPerDay),
+		TokensPerMonth: envInt("QUOTA_TOKENS_PER_MONTH", defaultTokensPerMonth),
+	}
+}
+
+// LimitsFor returns the limits for a user, applying any override
+func LimitsFor(app core.App, userID string) (Limits, bool) {
+	limits := DefaultLimits()
+	record, err := app.FindFirstRecordByData(OverridesCollection, "user", userID)
+	if err != nil {
+		return limits, false
+	}
+	return Limits{
+		TweaksPerDay:   int64(record.GetInt("tweaks_per_day")),
+		TokensPerMonth: int64(record.GetInt("tokens_per_month")),
+	}, true
+}
+
+// GetStatus counts a user's usage in the current day and month
+func GetStatus(app core.App, userID string, now time.Time) (Status, error) {
+	limits, overridden := LimitsFor(app, userID)
+
+	now = now.UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	status := Status{
+		Limits:      limits,
+		TweaksReset: dayStart.AddDate(0, 0, 1),
+		TokensReset: monthStart.AddDate(0, 1, 0),
+		Overridden:  overridden,
+	}
+
+	tweaks, err := app.CountRecords(UsageCollection,
+		dbx.HashExp{"user": userID},
+		dbx.NewExp("created >= {:start}", dbx.Params{"start": dayStart.Format(types.DefaultDateLayout)}),
+	)
+	if err != nil {
+		return status, err
+	}
+	status.TweaksUsed = tweaks
+
+	var tokens struct {
+		Total int64 `db:"total"`
+	}
+	err = app.RecordQuery(UsageCollection).
+		Select("COALESCE(SUM(tokens), 0) AS total").
+		AndWhere(dbx.HashExp{"user": userID}).
+		AndWhere(dbx.NewExp("created >= {:start}", dbx.Params{"start": monthStart.Format(types.DefaultDateLayout)})).
+		One(&tokens)
+	if err != nil {
+		return status, err
+	}
+	status.TokensUsed = tokens.Total
+
+	return status, nil
+}
+
+// Check returns the user's status, or an *ExceededError if any limit is used up
+func Check(app core.App, userID string, now time.Time) (Status, error) {
+	status, err := GetStatus(app, userID, now)
+	if err != nil {
+		return status, err
+	}
+	if status.TweaksPerDay > 0 && status.TweaksUsed >= status.TweaksPerDay {
+		return status, &ExceededError{
+			Kind:    KindTweaksPerDay,
+			Limit:   status.TweaksPerDay,
+			Used:    status.TweaksUsed,
+			ResetAt: status.TweaksReset,
+		}
+	}
+	if status.TokensPerMonth > 0 && status.TokensUsed >= status.TokensPerMonth {
+		return status, &ExceededError{
+			Kind:    KindTokensPerMonth,
+			Limit:   status.TokensPerMonth,
+			Used:    status.TokensUsed,
+			ResetAt: status.TokensReset,
+		}
+	}
+	return status, nil
+}
+
+// Record logs one LLM request for a user. Call Finish on the returned
+// event once the token count is known.
+func Record(app core.App, userID, endpoint string) (*Event, error) {
+	collection, err := app.FindCollectionByNameOrId(UsageCollection)
+	if err != nil {
+		return nil, err
+	}
+	record := core.NewRecord(collection)
+	record.Set("user", userID)
+	record.Set("endpoint", endpoint)
+	record.Set("tokens", 0)
+	if err := app.Save(record); err != nil {
+		return nil, err
+	}
+	return &Event{app: app, record: record}, nil
+}
+
+// Reserve checks the user's quota and records one request against it in a
+// single transaction, so concurrent requests can't both take the last
+// tweak. It returns an *ExceededError, and records nothing, if any limit is
+// used up.
+func Reserve(app core.App, userID, endpoint string, now time.Time) (*Event, Status, error) {
+	var event *Event
+	var status Status
+	err := app.RunInTransaction(func(txApp core.App) error {
+		var err error
+		status, err = Check(txApp, userID, now)
+		if err != nil {
+			return err
+		}
+		ev, err := Record(txApp, userID, endpoint)
+		if err != nil {
+			return err
+		}
+		// The event outlives the transaction
+		event = &Event{app: app, record: ev.record}
+		status.TweaksUsed++
+		return nil
+	})
+	if err != nil {
+		return nil, status, err
+	}
+	return event, status, nil
+}
+
+// Event is a recorded LLM request awaiting its token count
+type Event struct {
+	app    core.App
+	record *core.Record
+}
+
+// Finish stores the number of tokens the request consumed
+func (ev *Event) Finish(tokens int64) {
+	if ev == nil || tokens <= 0 {
+		return
+	}
+	ev.record.Set("tokens", tokens)
+	if err := ev.app.Save(ev.record); err != nil {
+		log.Printf("[Quota] Warning: failed to record %d tokens: %v", tokens, err)
+	}
+}
+
+// Cancel removes the event, refunding a request that never reached the LLM
+func (ev *Event) Cancel() {
+	if ev == nil {
+		return
+	}
+	if err := ev.app.Delete(ev.record); err != nil {
+		log.Printf("[Quota] Warning: failed to cancel usage event: %v", err)
+	}
+}
+
+// SetOverride creates or replaces a user's quota override
+func SetOverride(app core.App, userID string, limits Limits, note string) error {
+	record, err := app.FindFirstRecordByData(OverridesCollection, "user", userID)
+	if err != nil {
+		collection, err := app.FindCollectionByNameOrId(OverridesCollection)
+		if err != nil {
+			return err
+		}
+		record = core.NewRecord(collection)
+		record.Set("user", userID)
+	}
+	record.Set("tweaks_per_day", limits.TweaksPerDay)
+	record.Set("tokens_per_month", limits.TokensPerMonth)
+	record.Set("note", note)
+	return app.Save(record)
+}
+
+// ClearOverride removes a user's quota override, restoring the defaults
+func ClearOverride(app core.App, userID string) error {
+	record, err := app.FindFirstRecordByData(OverridesCollection, "user", userID)
+	if err != nil {
+		return nil
+	}
+	return app.Delete(record)
+}
+
+func envInt(key string, fallback int64) int64 {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || n < 0 {
+		log.Printf("[Quota] Invalid %s %q, using %d", key, raw, fallback)
+		return fallback
+	}
+	return n
+}
@@ -0,0 +1,93 @@
+package quota
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/johnhkchen/resume-tweaker/testapp"
+	"github.com/pocketbase/dbx"
+)
+
+func TestReserveIsAtomic(t *testing.T) {
+	app := testapp.New(t, SetupCollections)
+	userID := testapp.User(t, app).Id
+
+	// Reservations only interleave with several threads running
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(runtime.GOMAXPROCS(0), 4)))
+
+	const n = 16
+	if err := SetOverride(app, userID, Limits{TweaksPerDay: n - 1}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reserved, exceeded := 0, 0
+	start := make(chan struct{})
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, _, err := Reserve(app, userID, "tweak", time.Now())
+			var ex *ExceededError
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				reserved++
+			case errors.As(err, &ex):
+				exceeded++
+			default:
+				t.Errorf("Reserve: %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if reserved != n-1 || exceeded != 1 {
+		t.Fatalf("reserved %d, exceeded %d; want %d and 1", reserved, exceeded, n-1)
+	}
+	used, err := app.CountRecords(UsageCollection, dbx.HashExp{"user": userID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used != n-1 {
+		t.Fatalf("recorded %d usage events, want %d", used, n-1)
+	}
+}
+
+func TestReserveEventOutlivesTransaction(t *testing.T) {
+	app := testapp.New(t, SetupCollections)
+	userID := testapp.User(t, app).Id
+
+	event, status, err := Reserve(app, userID, "tweak", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.TweaksUsed != 1 {
+		t.Fatalf("TweaksUsed = %d, want 1", status.TweaksUsed)
+	}
+
+	event.Finish(1234)
+	status, err = GetStatus(app, userID, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.TokensUsed != 1234 {
+		t.Fatalf("TokensUsed = %d, want 1234", status.TokensUsed)
+	}
+
+	event.Cancel()
+	status, err = GetStatus(app, userID, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.TweaksUsed != 0 {
+		t.Fatalf("TweaksUsed after Cancel = %d, want 0", status.TweaksUsed)
+	}
+}
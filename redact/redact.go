@@ -0,0 +1,225 @@
+// Package redact swaps personally identifiable information for stable
+// placeholder tokens before text is sent to the LLM, and restores the
+// original values in the model's output.
+//
+// Tokens look like [[EMAIL_1]]. The same value always maps to the same token
+// within a Redactor, so the model sees a consistent document and every token
+// it echoes back can be restored.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Kinds of PII the redactor detects
+const (
+	KindName    = "NAME"
+	KindEmail   = "EMAIL"
+	KindPhone   = "PHONE"
+	KindAddress = "ADDRESS"
+	KindProfile = "PROFILE_URL"
+)
+
+var (
+	emailPattern   = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	profilePattern = regexp.MustCompile(`(?i)(?:https?://)?(?:[a-z]{2,3}\.)?(?:linkedin\.com/(?:in|pub)|github\.com|twitter\.com|x\.com)/[A-Za-z0-9_%-]+/?`)
+	// A phone number starts with "+" or an area code in parentheses, or has
+	// its groups split by separators; bare digit runs are ids, not phones
+	phonePattern = regexp.MustCompile(`\+\d{1,3}[ .-]?(?:\(\d{1,4}\)[ .-]?)?\d{1,4}(?:[ .-]?\d{2,4}){1,4}\b` +
+		`|\(\d{2,4}\)[ .-]?\d{3,4}[ .-]?\d{3,4}\b` +
+		`|\b\d{2,4}[ .-]\d{3,4}[ .-]\d{3,4}\b`)
+	addressPattern = regexp.MustCompile(`\b\d{1,5}\s+(?:[A-Z][A-Za-z]+\.?\s+){1,4}(?:Street|St|Avenue|Ave|Road|Rd|Boulevard|Blvd|Lane|Ln|Drive|Dr|Court|Ct|Way|Place|Pl|Terrace|Circle|Parkway|Pkwy|Highway|Hwy)\b\.?(?:,?\s+(?:Apt|Suite|Unit|#)\.?\s*[A-Za-z0-9-]+)?`)
+	tokenPattern   = regexp.MustCompile(`\[\[([A-Z_]+)_(\d+)\]\]`)
+	namePattern    = regexp.MustCompile(`^[A-Z][A-Za-z'’.-]*(?:\s+[A-Z][A-Za-z'’.-]*){1,3}$`)
+)
+
+// notNameWords rule out first lines that are headings, job titles or skills
+var notNameWords = map[string]bool{
+	"resume": true, "résumé": true, "curriculum": true, "vitae": true, "cv": true,
+	"summary": true, "profile": true, "experience": true, "objective": true,
+	"skills": true, "contact": true, "education": true, "about": true,
+
+	"engineer": true, "developer": true, "manager": true, "designer": true,
+	"analyst": true, "scientist": true, "consultant": true, "director": true,
+	"senior": true, "junior": true, "lead": true, "software": true,
+	"architect": true, "intern": true, "specialist": true, "administrator": true,
+	"coordinator": true, "principal": true, "staff": true, "head": true,
+	"founder": true, "officer": true, "product": true, "data": true,
+	"full-stack": true, "frontend": true, "backend": true, "devops": true,
+
+	"go": true, "golang": true, "rust": true, "python": true, "java": true,
+	"javascript": true, "typescript": true, "ruby": true, "rails": true,
+	"kotlin": true, "swift": true, "scala": true, "php": true, "perl": true,
+	"react": true, "vue": true, "angular": true, "node": true, "node.js": true,
+	"sql": true, "postgresql": true, "mysql": true, "aws": true, "gcp": true,
+	"azure": true, "docker": true, "kubernetes": true, "linux": true,
+	"terraform": true, "django": true, "flask": true, "spring": true,
+}
+
+// Entry is one redacted value
+type Entry struct {
+	Kind  string `json:"kind"`
+	Token string `json:"token"`
+	Value string `json:"value"`
+}
+
+// Redactor holds the value-to-token mapping for one request
+type Redactor struct {
+	byValue map[string]string
+	byToken map[string]string
+	counts  map[string]int
+	entries []Entry
+}
+
+// New creates an empty redactor. A redactor that never redacts anything
+// restores text unchanged, so it is safe to use when redaction is disabled.
+func New() *Redactor {
+	return &Redactor{
+		byValue: make(map[string]string),
+		byToken: make(map[string]string),
+		counts:  make(map[string]int),
+	}
+}
+
+// RedactResume redacts a resume, additionally treating a name-like first
+// line as the candidate's name
+func (r *Redactor) RedactResume(text string) string {
+	if name := leadingName(text); name != "" {
+		r.tokenFor(KindName, name)
+	}
+	return r.Redact(text)
+}
+
+// Redact replaces detected PII in text with placeholder tokens
+func (r *Redactor) Redact(text string) string {
+	// Order matters: URLs and emails first so their digits and words aren't
+	// picked up as phone numbers or names
+	text = r.replace(text, profilePattern, KindProfile, nil)
+	text = r.replace(text, emailPattern, KindEmail, nil)
+	text = r.replace(text, addressPattern, KindAddress, nil)
+	text = r.replace(text, phonePattern, KindPhone, isPhone)
+
+	// Replace every known name occurrence, longest first
+	var names []string
+	for _, e := range r.entries {
+		if e.Kind == KindName {
+			names = append(names, e.Value)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	for _, name := range names {
+		text = strings.ReplaceAll(text, name, r.byValue[name])
+	}
+	return text
+}
+
+// Restore swaps placeholder tokens in model output back to original values.
+// Unknown tokens are left as-is.
+func (r *Redactor) Restore(text string) string {
+	if len(r.entries) == 0 {
+		return text
+	}
+	return tokenPattern.ReplaceAllStringFunc(text, func(token string) string {
+		if value, ok := r.byToken[token]; ok {
+			return value
+		}
+		return token
+	})
+}
+
+// RestorePartial restores a partial streamed output. A token cut off at the
+// end of the stream (e.g. "[[EMA", or just "[") is held back until it is
+// complete, so the user never sees half a placeholder.
+func (r *Redactor) RestorePartial(text string) string {
+	if len(r.entries) == 0 {
+		return text
+	}
+	if i := strings.LastIndex(text, "[["); i >= 0 && !strings.Contains(text[i:], "]]") {
+		text = text[:i]
+	}
+	text = strings.TrimSuffix(text, "[")
+	return r.Restore(text)
+}
+
+// Entries returns everything that was redacted, in detection order
+func (r *Redactor) Entries() []Entry {
+	return append([]Entry(nil), r.entries...)
+}
+
+func (r *Redactor) replace(text string, pattern *regexp.Regexp, kind string, accept func(string) bool) string {
+	return pattern.ReplaceAllStringFunc(text, func(match string) string {
+		if tokenPattern.MatchString(match) {
+			return match
+		}
+		trimmed := strings.TrimRight(match, ".,;:)")
+		if accept != nil && !accept(trimmed) {
+			return match
+		}
+		return r.tokenFor(kind, trimmed) + match[len(trimmed):]
+	})
+}
+
+func (r *Redactor) tokenFor(kind, value string) string {
+	if token, ok := r.byValue[value]; ok {
+		return token
+	}
+	r.counts[kind]++
+	token := fmt.Sprintf("[[%s_%d]]", kind, r.counts[kind])
+	r.byValue[value] = token
+	r.byToken[token] = value
+	r.entries = append(r.entries, Entry{Kind: kind, Token: token, Value: value})
+	return token
+}
+
+// isPhone rejects matches that are more likely dates, years or ids
+func isPhone(s string) bool {
+	digits := 0
+	for _, c := range s {
+		if unicode.IsDigit(c) {
+			digits++
+		}
+	}
+	if strings.HasPrefix(s, "+") {
+		return digits >= 8 && digits <= 15
+	}
+	return digits >= 10 && digits <= 15 && !isYearRun(s)
+}
+
+// isYearRun reports whether every digit group in s is a year, e.g.
+// "2019 2020 2021"
+func isYearRun(s string) bool {
+	groups := strings.FieldsFunc(s, func(c rune) bool { return !unicode.IsDigit(c) })
+	for _, g := range groups {
+		if len(g) != 4 || (g[:2] != "19" && g[:2] != "20") {
+			return false
+		}
+	}
+	return len(groups) > 0
+}
+
+// leadingName returns the first non-empty line if it looks like a person's
+// name, which is how nearly every resume starts: two to four capitalised
+// words, none of them a heading, title or skill. Anything else gets no name
+// redaction.
+func leadingName(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(line, "# "))
+		if line == "" {
+			continue
+		}
+		if !namePattern.MatchString(line) || len(line) > 60 {
+			return ""
+		}
+		for _, word := range strings.Fields(strings.ToLower(line)) {
+			if notNameWords[word] {
+				return ""
+			}
+		}
+		return line
+	}
+	return ""
+}
@@ -0,0 +1,157 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactPhones(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Call +1 415 555 1234 today", "Call [[PHONE_1]] today"},
+		{"Call +44 20 7946 0958.", "Call [[PHONE_1]]."},
+		{"Call (415) 555-1234", "Call [[PHONE_1]]"},
+		{"Call 415-555-1234 or 415.555.1235", "Call [[PHONE_1]] or [[PHONE_2]]"},
+		{"Call 415 555 1234", "Call [[PHONE_1]]"},
+		// Not phones
+		{"Acme 2019 2020 2021", "Acme 2019 2020 2021"},
+		{"Order ID 1234567890", "Order ID 1234567890"},
+		{"2019-2021 at Acme", "2019-2021 at Acme"},
+		{"Served 192.168.100.200", "Served 192.168.100.200"},
+		{"Grew revenue 1,234,567 dollars", "Grew revenue 1,234,567 dollars"},
+	}
+	for _, tt := range tests {
+		if got := New().Redact(tt.in); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRedactContacts(t *testing.T) {
+	r := New()
+	got := r.Redact("jane@example.com, linkedin.com/in/janedoe, 12 Main Street, jane@example.com")
+	want := "[[EMAIL_1]], [[PROFILE_URL_1]], [[ADDRESS_1]], [[EMAIL_1]]"
+	if got != want {
+		t.Fatalf("Redact = %q, want %q", got, want)
+	}
+	if n := len(r.Entries()); n != 3 {
+		t.Fatalf("%d entries, want 3 (repeats share a token)", n)
+	}
+}
+
+func TestLeadingName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Jane Doe\nBackend engineer", "Jane Doe"},
+		{"# Jane Q. Doe\n", "Jane Q. Doe"},
+		{"\n\nMary-Jane O'Neil\n", "Mary-Jane O'Neil"},
+		{"JANE DOE\n", "JANE DOE"},
+		// Single words, skills, titles and headings are not names
+		{"Jane\n", ""},
+		{"Go Rust\nJane Doe", ""},
+		{"Python Django Developer\n", ""},
+		{"Senior Software Engineer\n", ""},
+		{"Curriculum Vitae\n", ""},
+		{"Jane Doe, Go Developer\n", ""},
+		{"Jane Alice Beth Carol Doe\n", ""},
+	}
+	for _, tt := range tests {
+		if got := leadingName(tt.in); got != tt.want {
+			t.Errorf("leadingName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRedactResumeName(t *testing.T) {
+	r := New()
+	got := r.RedactResume("Jane Doe\nJane Doe led the Go team.")
+	if want := "[[NAME_1]]\n[[NAME_1]] led the Go team."; got != want {
+		t.Fatalf("RedactResume = %q, want %q", got, want)
+	}
+
+	// A skills line isn't taken for a name, so nothing is redacted
+	r = New()
+	if got := r.RedactResume("Go Rust\nBuilt Go services"); got != "Go Rust\nBuilt Go services" {
+		t.Fatalf("RedactResume redacted skills: %q", got)
+	}
+}
+
+const roundTripResume = `Jane Doe
+jane@example.com | +1 415 555 1234 | github.com/janedoe
+
+- Built billing for Acme, 2019 2020 2021
+- Order ID 1234567890 processing`
+
+func TestRoundTrip(t *testing.T) {
+	r := New()
+	redacted := r.RedactResume(roundTripResume)
+	for _, e := range r.Entries() {
+		if strings.Contains(redacted, e.Value) {
+			t.Errorf("redacted text still contains %s %q", e.Kind, e.Value)
+		}
+	}
+	if got := r.Restore(redacted); got != roundTripResume {
+		t.Fatalf("Restore(Redact(x)) = %q, want %q", got, roundTripResume)
+	}
+
+	// Unknown tokens the model invents are left alone
+	if got := r.Restore("[[EMAIL_9]]"); got != "[[EMAIL_9]]" {
+		t.Fatalf("Restore of unknown token = %q", got)
+	}
+}
+
+func TestRestorePartialStream(t *testing.T) {
+	r := New()
+	redacted := r.RedactResume(roundTripResume)
+	want := r.Restore(redacted)
+
+	// Stream the redacted text a few bytes at a time, as the model would,
+	// so tokens are split across chunks
+	var shown string
+	for end := 1; end <= len(redacted); end += 3 {
+		got := r.RestorePartial(redacted[:end])
+		if strings.Contains(got, "[[") {
+			t.Fatalf("partial output shows a placeholder: %q", got)
+		}
+		if !strings.HasPrefix(want, got) {
+			t.Fatalf("partial output %q is not a prefix of %q", got, want)
+		}
+		if len(got) < len(shown) {
+			t.Fatalf("partial output went backwards: %q after %q", got, shown)
+		}
+		shown = got
+	}
+	if got := r.RestorePartial(redacted); got != want {
+		t.Fatalf("RestorePartial(full) = %q, want %q", got, want)
+	}
+}
+
+func TestRestorePartialHoldsSplitToken(t *testing.T) {
+	r := New()
+	r.Redact("jane@example.com")
+
+	if got := r.RestorePartial("Email: [[EMA"); got != "Email: " {
+		t.Fatalf("RestorePartial held nothing back: %q", got)
+	}
+	// The held-back token comes through once the next chunk completes it
+	if got := r.RestorePartial("Email: [[EMAIL_1]] today"); got != "Email: jane@example.com today" {
+		t.Fatalf("RestorePartial dropped the token: %q", got)
+	}
+	// A closed token earlier in the text doesn't stop holding a later one
+	if got := r.RestorePartial("[[EMAIL_1]] and [["); got != "jane@example.com and " {
+		t.Fatalf("RestorePartial = %q", got)
+	}
+}
+
+func TestEmptyRedactorIsNoop(t *testing.T) {
+	r := New()
+	in := "Tokens like [[EMA stay as they are"
+	if got := r.RestorePartial(in); got != in {
+		t.Fatalf("RestorePartial = %q, want unchanged", got)
+	}
+	if got := r.Restore(in); got != in {
+		t.Fatalf("Restore = %q, want unchanged", got)
+	}
+}
@@ -0,0 +1,259 @@
+// Package scheduler bounds the number of in-flight LLM calls and queues the
+// rest fairly across users.
+//
+// Each user gets their own FIFO queue and free slots are handed out
+// round-robin between users, so one user submitting a burst of requests
+// cannot starve everyone else.
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Stats is a point-in-time snapshot of the scheduler for metrics
+type Stats struct {
+	Limit        int     `json:"limit"`
+	InFlight     int     `json:"in_flight"`
+	QueueDepth   int     `json:"queue_depth"`
+	QueuedUsers  int     `json:"queued_users"`
+	Acquired     uint64  `json:"acquired_total"`
+	Abandoned    uint64  `json:"abandoned_total"`
+	AvgWaitMs    float64 `json:"avg_wait_ms"`
+	MaxWaitMs    float64 `json:"max_wait_ms"`
+	LastWaitMs   float64 `json:"last_wait_ms"`
+	MaxQueueSeen int     `json:"max_queue_depth"`
+}
+
+type waiter struct {
+	user     string
+	enqueued time.Time
+	ready    chan struct{}
+	position chan int
+	granted  bool
+}
+
+// Scheduler caps concurrent LLM calls and queues the rest per user
+type Scheduler struct {
+	mu       sync.Mutex
+	limit    int
+	inFlight int
+
+	// queues holds each user's waiters in arrival order; order is the
+	// round-robin rotation of users that currently have waiters
+	queues map[string][]*waiter
+	order  []string
+
+	acquired     uint64
+	abandoned    uint64
+	totalWait    time.Duration
+	maxWait      time.Duration
+	lastWait     time.Duration
+	maxQueueSeen int
+}
+
+// New creates a scheduler allowing at most limit concurrent calls
+func New(limit int) *Scheduler {
+	if limit < 1 {
+		limit = 1
+	}
+	return &Scheduler{
+		limit:  limit,
+		queues: make(map[string][]*waiter),
+	}
+}
+
+// Acquire blocks until a slot is free for user or ctx is done.
+// While queued, onPosition is called with the 1-based queue position
+// whenever it changes. The returned release func must be called exactly
+// once when the LLM call finishes.
+func (s *Scheduler) Acquire(ctx context.Context, user string, onPosition func(int)) (func(), error) {
+	s.mu.Lock()
+	if s.inFlight < s.limit && len(s.order) == 0 {
+		s.inFlight++
+		s.recordWait(0)
+		s.mu.Unlock()
+		return s.releaseFunc(), nil
+	}
+
+	w := &waiter{
+		user:     user,
+		enqueued: time.Now(),
+		ready:    make(chan struct{}),
+		position: make(chan int, 1),
+	}
+	if _, ok := s.queues[user]; !ok {
+		s.order = append(s.order, user)
+	}
+	s.queues[user] = append(s.queues[user], w)
+	if depth := s.depth(); depth > s.maxQueueSeen {
+		s.maxQueueSeen = depth
+	}
+	s.notifyPositions()
+	s.mu.Unlock()
+
+	for {
+		select {
+		case <-w.ready:
+			return s.releaseFunc(), nil
+		case pos := <-w.position:
+			if onPosition != nil {
+				onPosition(pos)
+			}
+		case <-ctx.Done():
+			s.mu.Lock()
+			if w.granted {
+				// Lost the race with dispatch - hand the slot back
+				s.mu.Unlock()
+				s.release()
+				return nil, ctx.Err()
+			}
+			s.remove(w)
+			s.abandoned++
+			s.notifyPositions()
+			s.mu.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Stats returns a snapshot of queue depth, in-flight calls and wait times
+func (s *Scheduler) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := Stats{
+		Limit:        s.limit,
+		InFlight:     s.inFlight,
+		QueueDepth:   s.depth(),
+		QueuedUsers:  len(s.order),
+		Acquired:     s.acquired,
+		Abandoned:    s.abandoned,
+		MaxWaitMs:    durationMs(s.maxWait),
+		LastWaitMs:   durationMs(s.lastWait),
+		MaxQueueSeen: s.maxQueueSeen,
+	}
+	if s.acquired > 0 {
+		st.AvgWaitMs = durationMs(s.totalWait) / float64(s.acquired)
+	}
+	return st
+}
+
+func (s *Scheduler) releaseFunc() func() {
+	var once sync.Once
+	return func() {
+		once.Do(s.release)
+	}
+}
+
+func (s *Scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	s.dispatch()
+}
+
+// dispatch hands free slots to waiters, one user at a time in rotation.
+// Must be called with s.mu held.
+func (s *Scheduler) dispatch() {
+	changed := false
+	for s.inFlight < s.limit && len(s.order) > 0 {
+		user := s.order[0]
+		q := s.queues[user]
+		w := q[0]
+
+		s.queues[user] = q[1:]
+		if len(s.queues[user]) == 0 {
+			delete(s.queues, user)
+			s.order = s.order[1:]
+		} else {
+			// Move this user to the back of the rotation
+			s.order = append(s.order[1:], user)
+		}
+
+		s.inFlight++
+		s.recordWait(time.Since(w.enqueued))
+		w.granted = true
+		close(w.ready)
+		changed = true
+	}
+	if changed {
+		s.notifyPositions()
+	}
+}
+
+// remove drops an abandoned waiter from its user's queue.
+// Must be called with s.mu held.
+func (s *Scheduler) remove(w *waiter) {
+	q := s.queues[w.user]
+	for i, other := range q {
+		if other == w {
+			q = append(q[:i], q[i+1:]...)
+			break
+		}
+	}
+	if len(q) > 0 {
+		s.queues[w.user] = q
+		return
+	}
+	delete(s.queues, w.user)
+	for i, u := range s.order {
+		if u == w.user {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// notifyPositions pushes the current position to every waiter.
+// A waiter at index i of its user's queue is served in round i, after every
+// user earlier in the rotation that still has a waiter in that round.
+// Must be called with s.mu held.
+func (s *Scheduler) notifyPositions() {
+	for u, user := range s.order {
+		for i, w := range s.queues[user] {
+			ahead := i
+			for v, other := range s.order {
+				if v == u {
+					continue
+				}
+				n := len(s.queues[other])
+				rounds := i
+				if v < u {
+					rounds = i + 1
+				}
+				ahead += min(n, rounds)
+			}
+			// Keep only the latest position in the buffer
+			select {
+			case <-w.position:
+			default:
+			}
+			w.position <- ahead + 1
+		}
+	}
+}
+
+// depth is the total number of queued waiters. Must be called with s.mu held.
+func (s *Scheduler) depth() int {
+	n := 0
+	for _, q := range s.queues {
+		n += len(q)
+	}
+	return n
+}
+
+// recordWait updates wait-time metrics. Must be called with s.mu held.
+func (s *Scheduler) recordWait(d time.Duration) {
+	s.acquired++
+	s.totalWait += d
+	s.lastWait = d
+	if d > s.maxWait {
+		s.maxWait = d
+	}
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,247 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// queued is a background Acquire: granted yields its release func, err its
+// error, and position holds the last position reported to it
+type queued struct {
+	granted  chan func()
+	position atomic.Int64
+	cancel   context.CancelFunc
+	err      chan error
+}
+
+// enqueue starts an Acquire for user and waits until it is in the queue
+func enqueue(t *testing.T, s *Scheduler, user string) *queued {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &queued{granted: make(chan func(), 1), cancel: cancel, err: make(chan error, 1)}
+	depth := s.Stats().QueueDepth
+	go func() {
+		release, err := s.Acquire(ctx, user, func(pos int) { q.position.Store(int64(pos)) })
+		if err != nil {
+			q.err <- err
+			return
+		}
+		q.granted <- release
+	}()
+	waitFor(t, user+" to queue", func() bool { return s.Stats().QueueDepth == depth+1 })
+	return q
+}
+
+func mustAcquire(t *testing.T, s *Scheduler, user string) func() {
+	t.Helper()
+	release, err := s.Acquire(context.Background(), user, nil)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	return release
+}
+
+func TestAcquireImmediateUnderLimit(t *testing.T) {
+	s := New(2)
+	r1 := mustAcquire(t, s, "a")
+	r2 := mustAcquire(t, s, "a")
+	if got := s.Stats().InFlight; got != 2 {
+		t.Fatalf("InFlight = %d, want 2", got)
+	}
+	r1()
+	r1() // releasing twice must not free a second slot
+	r2()
+	if got := s.Stats().InFlight; got != 0 {
+		t.Fatalf("InFlight = %d, want 0", got)
+	}
+}
+
+func TestRoundRobinAcrossUsers(t *testing.T) {
+	s := New(1)
+	hold := mustAcquire(t, s, "x")
+
+	// A bursts three requests before B sends two
+	var waiters []*queued
+	var users []string
+	for _, u := range []string{"a", "a", "a", "b", "b"} {
+		waiters = append(waiters, enqueue(t, s, u))
+		users = append(users, u)
+	}
+
+	var order []string
+	release := hold
+	for range waiters {
+		release()
+		var next func()
+		var who string
+		select {
+		case next = <-waiters[0].granted:
+			who = users[0]
+			waiters, users = waiters[1:], users[1:]
+		default:
+			// Find whichever waiter was granted
+			deadline := time.After(2 * time.Second)
+		find:
+			for {
+				for i, w := range waiters {
+					select {
+					case next = <-w.granted:
+						who = users[i]
+						waiters = append(waiters[:i], waiters[i+1:]...)
+						users = append(users[:i:i], users[i+1:]...)
+						break find
+					default:
+					}
+				}
+				select {
+				case <-deadline:
+					t.Fatal("no waiter granted after release")
+				case <-time.After(time.Millisecond):
+				}
+			}
+		}
+		order = append(order, who)
+		release = next
+	}
+	release()
+
+	want := []string{"a", "b", "a", "b", "a"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("grant order = %v, want %v", order, want)
+		}
+	}
+	if st := s.Stats(); st.InFlight != 0 || st.QueueDepth != 0 {
+		t.Fatalf("after draining: %+v", st)
+	}
+}
+
+func TestQueuePositions(t *testing.T) {
+	s := New(1)
+	hold := mustAcquire(t, s, "x")
+
+	a1 := enqueue(t, s, "a")
+	a2 := enqueue(t, s, "a")
+	a3 := enqueue(t, s, "a")
+	b1 := enqueue(t, s, "b")
+	b2 := enqueue(t, s, "b")
+
+	// Served a1, b1, a2, b2, a3
+	check := func(want map[*queued]int64) {
+		t.Helper()
+		waitFor(t, "positions", func() bool {
+			for q, pos := range want {
+				if q.position.Load() != pos {
+					return false
+				}
+			}
+			return true
+		})
+	}
+	check(map[*queued]int64{a1: 1, b1: 2, a2: 3, b2: 4, a3: 5})
+
+	// Granting a1 moves everyone up one
+	hold()
+	r := <-a1.granted
+	check(map[*queued]int64{b1: 1, a2: 2, b2: 3, a3: 4})
+
+	// A cancelled waiter drops out of the count; b keeps its turn in the
+	// rotation, so b2 moves to the front
+	b1.cancel()
+	<-b1.err
+	check(map[*queued]int64{b2: 1, a2: 2, a3: 3})
+
+	r()
+	r = <-b2.granted
+	r()
+	r = <-a2.granted
+	r()
+	r = <-a3.granted
+	r()
+}
+
+func TestCancelQueuedDoesNotLeakSlot(t *testing.T) {
+	s := New(1)
+	hold := mustAcquire(t, s, "x")
+
+	q := enqueue(t, s, "a")
+	q.cancel()
+	if err := <-q.err; err != context.Canceled {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	st := s.Stats()
+	if st.QueueDepth != 0 || st.QueuedUsers != 0 || st.Abandoned != 1 {
+		t.Fatalf("after cancel: %+v", st)
+	}
+
+	hold()
+	if got := s.Stats().InFlight; got != 0 {
+		t.Fatalf("InFlight = %d, want 0", got)
+	}
+	// The slot is free for the next caller straight away
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	release, err := s.Acquire(ctx, "b", nil)
+	if err != nil {
+		t.Fatalf("Acquire after cancel: %v", err)
+	}
+	release()
+}
+
+func TestConcurrentCancelAndRelease(t *testing.T) {
+	const limit = 3
+	s := New(limit)
+
+	var wg sync.WaitGroup
+	var active, peak atomic.Int64
+	for i := range 200 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Every other request gives up quickly, racing dispatch
+			timeout := time.Second
+			if i%2 == 0 {
+				timeout = time.Duration(i%7) * 100 * time.Microsecond
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			release, err := s.Acquire(ctx, string(rune('a'+i%5)), nil)
+			if err != nil {
+				return
+			}
+			n := active.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(50 * time.Microsecond)
+			active.Add(-1)
+			release()
+		}()
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p > limit {
+		t.Fatalf("peak concurrency %d exceeds limit %d", p, limit)
+	}
+	if st := s.Stats(); st.InFlight != 0 || st.QueueDepth != 0 {
+		t.Fatalf("slots leaked: %+v", st)
+	}
+}
@@ -0,0 +1,174 @@
+// Package screen detects prompt-injection attempts in pasted resumes and job
+// descriptions.
+//
+// Job descriptions are copied from the web and may carry text aimed at AI
+// systems ("ignore previous instructions..."). Scan flags instruction-like
+// content with heuristics; Neutralize defuses it before the text is placed
+// inside the delimited blocks of the TweakResume prompt.
+package screen
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Modes for handling flagged content
+const (
+	// ModeNeutralize rewrites flagged content before it reaches the model
+	ModeNeutralize = "neutralize"
+	// ModeFlag only warns the user and relies on the prompt delimiters
+	ModeFlag = "flag"
+)
+
+// removedMarker replaces instruction-like phrases in neutralize mode
+const removedMarker = "[instruction-like text removed]"
+
+// Finding is one suspicious span of input
+type Finding struct {
+	// Source is the input the finding came from ("resume" or "job_description")
+	Source string `json:"source"`
+	Reason string `json:"reason"`
+	// Excerpt is the matched text, truncated for display
+	Excerpt string `json:"excerpt"`
+}
+
+type rule struct {
+	reason  string
+	pattern *regexp.Regexp
+	// context, if set, must also match somewhere in the text for the
+	// pattern to count
+	context *regexp.Regexp
+}
+
+// applies reports whether the rule's context, if any, is present in text
+func (r rule) applies(text string) bool {
+	return r.context == nil || r.context.MatchString(text)
+}
+
+var rules = []rule{
+	{"Asks the AI to ignore its instructions", regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override|bypass)\b[^.\n]{0,40}\b(?:previous|prior|above|earlier|all|any|your|the|system)\b[^.\n]{0,20}\b(?:instructions?|prompts?|rules|directions|guidelines|context)\b`), nil},
+	{"Tries to assign the AI a new role", regexp.MustCompile(`(?i)\b(?:you are now|from now on,? you|act as (?:an?|the) (?:ai|assistant|language model|chatbot)|pretend (?:to be|you are)|roleplay as)\b`), nil},
+	{"Gives the AI new instructions", regexp.MustCompile(`(?i)\b(?:new|updated|real|actual|hidden) (?:instructions?|system prompt|task)\s*:`), nil},
+	{"Addresses an AI model directly", regexp.MustCompile(`(?i)\b(?:dear|hey|attention|note to)\s+(?:ai|chatgpt|gpt|claude|llm|language model|assistant|screening (?:bot|tool|system))\b`), nil},
+	{"Asks for the system prompt", regexp.MustCompile(`(?i)\b(?:reveal|print|show|repeat|output)\b[^.\n]{0,30}\b(?:system prompt|your (?:prompt|instructions))\b`), nil},
+	{"Tries to dictate the verdict or output", regexp.MustCompile(`(?i)\b(?:rate|rank|score|mark|recommend)\b[^.\n]{0,30}\b(?:this candidate|me|this (?:resume|applicant))\b[^.\n]{0,30}\b(?:highest|top|10/10|100|perfect|best|strong(?:ly)? hire)\b`), nil},
+	{"Contains chat role markers", regexp.MustCompile(`(?im)(?:<\|im_(?:start|end)\|>|\[/?INST\]|<</?SYS>>|^\s*#{1,3}\s*(?:system|assistant|user)\s*:?\s*$|^\s*(?:system|assistant|user)\s*:\s*$)`), nil},
+	// "Assistant: Office Manager" is a job title, unless the text also has
+	// a user turn, as a pasted chat transcript does
+	{
+		reason:  "Contains chat role markers",
+		pattern: regexp.MustCompile(`(?im)^\s*(?:system|assistant)\s*:`),
+		context: regexp.MustCompile(`(?im)^\s*(?:user|human)\s*:`),
+	},
+	{"Contains prompt delimiter tags", regexp.MustCompile(`(?i)</?\s*(?:resume|job_description|instructions|system)\s*>`), nil},
+}
+
+// hiddenChars are invisible characters often used to smuggle text past humans
+var hiddenChars = regexp.MustCompile(`[\x{200B}-\x{200D}\x{2060}\x{FEFF}\x{202A}-\x{202E}\x{2066}-\x{2069}]`)
+
+// Mode returns the configured handling mode from PROMPT_INJECTION_MODE
+func Mode() string {
+	switch mode := os.Getenv("PROMPT_INJECTION_MODE"); mode {
+	case "", ModeNeutralize:
+		return ModeNeutralize
+	case ModeFlag:
+		return ModeFlag
+	default:
+		log.Printf("[Screen] Invalid PROMPT_INJECTION_MODE %q, using %s", mode, ModeNeutralize)
+		return ModeNeutralize
+	}
+}
+
+// ClassifierEnabled reports whether the optional LLM classifier pass is on
+// (PROMPT_INJECTION_CLASSIFIER=true)
+func ClassifierEnabled() bool {
+	switch strings.ToLower(os.Getenv("PROMPT_INJECTION_CLASSIFIER")) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// Scan returns heuristic findings for text from the named source
+func Scan(source, text string) []Finding {
+	var findings []Finding
+	for _, r := range rules {
+		if !r.applies(text) {
+			continue
+		}
+		for _, match := range r.pattern.FindAllString(text, 3) {
+			findings = append(findings, Finding{
+				Source:  source,
+				Reason:  r.reason,
+				Excerpt: excerpt(match),
+			})
+		}
+	}
+	if n := len(hiddenChars.FindAllString(text, -1)); n > 0 {
+		findings = append(findings, Finding{
+			Source:  source,
+			Reason:  "Contains hidden characters",
+			Excerpt: fmt.Sprintf("%d invisible character(s)", n),
+		})
+	}
+	return findings
+}
+
+// Neutralize strips hidden characters, role markers and delimiter tags, and
+// replaces instruction-like phrases (plus any extra exact phrases, such as
+// classifier excerpts) so they read as inert text
+func Neutralize(text string, extra ...string) string {
+	text = hiddenChars.ReplaceAllString(text, "")
+	for _, r := range rules {
+		if r.applies(text) {
+			text = r.pattern.ReplaceAllString(text, removedMarker)
+		}
+	}
+	for _, phrase := range extra {
+		if phrase = strings.TrimSpace(phrase); len(phrase) >= 8 {
+			text = strings.ReplaceAll(text, phrase, removedMarker)
+		}
+	}
+	return text
+}
+
+// Summary builds the warning shown to the user, or "" if nothing was found
+func Summary(findings []Finding, mode string) string {
+	if len(findings) == 0 {
+		return ""
+	}
+
+	sources := map[string]bool{}
+	for _, f := range findings {
+		sources[f.Source] = true
+	}
+	var where []string
+	if sources["job_description"] {
+		where = append(where, "job description")
+	}
+	if sources["resume"] {
+		where = append(where, "resume")
+	}
+
+	msg := fmt.Sprintf("Your %s contains text that looks like instructions to an AI (%q).", strings.Join(where, " and "), findings[0].Excerpt)
+	if mode == ModeNeutralize {
+		return msg + " We removed it and treated the rest as plain content."
+	}
+	return msg + " We treated it as plain content, but review the result carefully."
+}
+
+// maxExcerptRunes caps a finding's excerpt, including the ellipsis
+const maxExcerptRunes = 80
+
+// excerpt collapses whitespace in s and truncates it, on a rune boundary,
+// for display
+func excerpt(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > maxExcerptRunes {
+		return string(runes[:maxExcerptRunes-3]) + "..."
+	}
+	return s
+}
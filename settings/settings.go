@@ -0,0 +1,100 @@
+// Package settings stores per-user preferences in the user_settings
+// collection. Users without a record get the defaults.
+package settings
+
+import (
+	"log"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Collection is the PocketBase collection holding user preferences
+const Collection = "user_settings"
+
+// Settings are a user's preferences
+type Settings struct {
+	// RedactPII hides personal details from the LLM (on by default)
+	RedactPII bool `json:"redact_pii"`
+	// LaTeXTemplate is the LaTeX export template last chosen; "" is the
+	// default
+	LaTeXTemplate string `json:"latex_template"`
+}
+
+// Defaults returns the settings for a user who hasn't saved any
+func Defaults() Settings {
+	return Settings{
+		RedactPII: true,
+	}
+}
+
+// Get loads a user's settings, falling back to the defaults
+func Get(app core.App, userID string) Settings {
+	record, err := app.FindFirstRecordByData(Collection, "user", userID)
+	if err != nil {
+		return Defaults()
+	}
+	return Settings{
+		RedactPII:     record.GetBool("redact_pii"),
+		LaTeXTemplate: record.GetString("latex_template"),
+	}
+}
+
+// Save creates or updates a user's settings
+func Save(app core.App, userID string, s Settings) error {
+	record, err := app.FindFirstRecordByData(Collection, "user", userID)
+	if err != nil {
+		collection, err := app.FindCollectionByNameOrId(Collection)
+		if err != nil {
+			return err
+		}
+		record = core.NewRecord(collection)
+		record.Set("user", userID)
+	}
+	record.Set("redact_pii", s.RedactPII)
+	record.Set("latex_template", s.LaTeXTemplate)
+	return app.Save(record)
+}
+
+// SetupCollections creates the user_settings collection if it doesn't exist
+func SetupCollections(app core.App) error {
+	if existing, err := app.FindCollectionByNameOrId(Collection); err == nil {
+		// For collections created before the LaTeX export existed
+		if existing.Fields.GetByName("latex_template") == nil {
+			existing.Fields.Add(&core.TextField{Name: "latex_template"})
+			return app.Save(existing)
+		}
+		return nil
+	}
+
+	log.Printf("[Setup] Creating %s collection...", Collection)
+
+	usersCollection, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		return err
+	}
+
+	collection := core.NewBaseCollection(Collection)
+	collection.Fields.Add(&core.RelationField{
+		Name:          "user",
+		Required:      true,
+		CollectionId:  usersCollection.Id,
+		MaxSelect:     1,
+		CascadeDelete: true,
+	})
+	collection.Fields.Add(&core.BoolField{
+		Name: "redact_pii",
+	})
+	collection.Fields.Add(&core.TextField{
+		Name: "latex_template",
+	})
+	collection.AddIndex("idx_user_settings_user", true, "user", "")
+
+	// Users manage their own settings
+	collection.ListRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+	collection.ViewRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id`)
+	collection.CreateRule = types.Pointer(`@request.auth.id != "" && @request.body.user = @request.auth.id`)
+	collection.UpdateRule = types.Pointer(`@request.auth.id != "" && user = @request.auth.id && (@request.body.user:isset = false || @request.body.user = @request.auth.id)`)
+
+	return app.Save(collection)
+}
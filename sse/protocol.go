@@ -0,0 +1,102 @@
+package sse
+
+import (
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Protocol is the Datastar SSE event format spoken to a client
+type Protocol int
+
+const (
+	// ProtocolBeta is the 1.0.0-beta.8 to beta.11 format
+	// (datastar-merge-signals, datastar-merge-fragments, ...)
+	ProtocolBeta Protocol = iota
+	// ProtocolV1 is the 1.0 format
+	// (datastar-patch-signals, datastar-patch-elements)
+	ProtocolV1
+)
+
+func (p Protocol) String() string {
+	if p == ProtocolV1 {
+		return "v1"
+	}
+	return "beta"
+}
+
+// VersionHeader carries the client's Datastar version on @post/@get requests
+const VersionHeader = "Datastar-Version"
+
+// defaultVersion is the Datastar release served when DATASTAR_VERSION is unset
+const defaultVersion = "1.0.0-beta.11"
+
+// Version returns the Datastar release the frontend loads, from DATASTAR_VERSION
+func Version() string {
+	if v := strings.TrimSpace(os.Getenv("DATASTAR_VERSION")); v != "" {
+		return v
+	}
+	return defaultVersion
+}
+
+// ScriptURL returns the CDN URL of the configured Datastar release
+func ScriptURL() string {
+	return "https://cdn.jsdelivr.net/npm/@starfederation/datastar@" + Version() + "/dist/datastar.min.js"
+}
+
+// ProtocolFor maps a Datastar release to the event format it understands.
+// Pre-1.0 and 1.0.0-beta releases use the beta format; release candidates
+// and later use the 1.0 format.
+func ProtocolFor(version string) Protocol {
+	v := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(version), "v"))
+	if strings.HasPrefix(v, "0.") || strings.Contains(v, "beta") {
+		return ProtocolBeta
+	}
+	return ProtocolV1
+}
+
+// Negotiate picks the protocol for a request. The version the client
+// reports in VersionHeader wins, so pages rendered before a frontend upgrade
+// keep working; otherwise the configured version is assumed.
+func Negotiate(r *http.Request) Protocol {
+	if r != nil {
+		if v := r.Header.Get(VersionHeader); v != "" {
+			return ProtocolFor(v)
+		}
+	}
+	return ProtocolFor(Version())
+}
+
+// mergeModeV1 maps beta fragment merge modes to 1.0 element patch modes
+var mergeModeV1 = map[string]string{
+	MergeMorph:   "outer",
+	MergeInner:   "inner",
+	MergeOuter:   "replace",
+	MergePrepend: "prepend",
+	MergeAppend:  "append",
+	MergeBefore:  "before",
+	MergeAfter:   "after",
+}
+
+// removalSignals turns dotted signal paths into a 1.0 patch that sets each
+// one to null, which is how 1.0 removes signals
+func removalSignals(paths []string) Signals {
+	root := Signals{}
+	for _, p := range paths {
+		parts := strings.Split(p, ".")
+		node := root
+		for i, part := range parts {
+			if i == len(parts)-1 {
+				node[part] = nil
+				break
+			}
+			child, ok := node[part].(Signals)
+			if !ok {
+				child = Signals{}
+				node[part] = child
+			}
+			node = child
+		}
+	}
+	return root
+}
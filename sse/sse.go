@@ -0,0 +1,338 @@
+// Package sse writes Datastar server-sent events.
+//
+// Values are encoded with encoding/json, and multi-line payloads are split
+// across data lines, so arbitrary text (upstream errors, model output) can
+// never produce an event the browser silently drops. Each Writer speaks
+// either the beta or the 1.0 event format, negotiated per request, so
+// handlers don't change when the frontend script is upgraded.
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Datastar beta event types
+const (
+	EventMergeSignals    = "datastar-merge-signals"
+	EventRemoveSignals   = "datastar-remove-signals"
+	EventMergeFragments  = "datastar-merge-fragments"
+	EventRemoveFragments = "datastar-remove-fragments"
+	EventExecuteScript   = "datastar-execute-script"
+)
+
+// Datastar 1.0 event types
+const (
+	EventPatchSignals  = "datastar-patch-signals"
+	EventPatchElements = "datastar-patch-elements"
+)
+
+// Fragment merge modes
+const (
+	MergeMorph   = "morph"
+	MergeInner   = "inner"
+	MergeOuter   = "outer"
+	MergePrepend = "prepend"
+	MergeAppend  = "append"
+	MergeBefore  = "before"
+	MergeAfter   = "after"
+)
+
+// DefaultRetry is the reconnect delay Datastar assumes when none is sent
+const DefaultRetry = time.Second
+
+// ErrUnsupported is returned when the response can't be flushed
+var ErrUnsupported = errors.New("sse: streaming not supported")
+
+// Signals is a set of signal values to merge
+type Signals map[string]any
+
+// Writer sends Datastar events over a single response. It is safe for
+// concurrent use.
+type Writer struct {
+	mu        sync.Mutex
+	w         http.ResponseWriter
+	flusher   http.Flusher
+	protocol  Protocol
+	err       error
+	lastWrite time.Time
+}
+
+// New sets the SSE headers on w and returns a Writer speaking the protocol
+// negotiated for r
+func New(w http.ResponseWriter, r *http.Request) (*Writer, error) {
+	return NewWithProtocol(w, Negotiate(r))
+}
+
+// NewWithProtocol is New with an explicit protocol
+func NewWithProtocol(w http.ResponseWriter, protocol Protocol) (*Writer, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	return &Writer{w: w, flusher: flusher, protocol: protocol, lastWrite: time.Now()}, nil
+}
+
+// Protocol returns the event format this Writer speaks
+func (s *Writer) Protocol() Protocol {
+	return s.protocol
+}
+
+// Err returns the first write error, usually a client disconnect
+func (s *Writer) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Option customises a single event
+type Option func(*event)
+
+// WithID sets the event id, which the browser echoes as Last-Event-ID
+func WithID(id string) Option {
+	return func(e *event) { e.id = id }
+}
+
+// WithRetry sets the client's reconnect delay
+func WithRetry(d time.Duration) Option {
+	return func(e *event) { e.retry = d }
+}
+
+// WithSelector targets fragment merges and removals at a CSS selector
+func WithSelector(selector string) Option {
+	return func(e *event) { e.selector = selector }
+}
+
+// WithMergeMode sets how fragments are merged (default morph)
+func WithMergeMode(mode string) Option {
+	return func(e *event) { e.mergeMode = mode }
+}
+
+// WithViewTransition wraps the DOM update in a view transition
+func WithViewTransition() Option {
+	return func(e *event) { e.viewTransition = true }
+}
+
+// WithOnlyIfMissing merges signals only where they aren't already set
+func WithOnlyIfMissing() Option {
+	return func(e *event) { e.onlyIfMissing = true }
+}
+
+// WithKeepScript leaves an executed script element in the DOM
+func WithKeepScript() Option {
+	return func(e *event) { e.keepScript = true }
+}
+
+// event holds per-event options
+type event struct {
+	id             string
+	retry          time.Duration
+	selector       string
+	mergeMode      string
+	viewTransition bool
+	onlyIfMissing  bool
+	keepScript     bool
+}
+
+func buildEvent(opts []Option) event {
+	var e event
+	for _, opt := range opts {
+		opt(&e)
+	}
+	return e
+}
+
+// MergeSignals merges signals into the page's signal store. signals may be
+// any value that encodes to a JSON object, typically Signals or a struct.
+func (s *Writer) MergeSignals(signals any, opts ...Option) error {
+	data, err := json.Marshal(signals)
+	if err != nil {
+		return fmt.Errorf("sse: encode signals: %w", err)
+	}
+
+	e := buildEvent(opts)
+	var lines []string
+	if e.onlyIfMissing {
+		lines = append(lines, "onlyIfMissing true")
+	}
+	lines = append(lines, "signals "+string(data))
+	if s.protocol == ProtocolV1 {
+		return s.send(EventPatchSignals, e, lines)
+	}
+	return s.send(EventMergeSignals, e, lines)
+}
+
+// RemoveSignals removes signals by dotted path
+func (s *Writer) RemoveSignals(paths []string, opts ...Option) error {
+	if s.protocol == ProtocolV1 {
+		return s.MergeSignals(removalSignals(paths), opts...)
+	}
+	lines := make([]string, 0, len(paths))
+	for _, p := range paths {
+		lines = append(lines, "paths "+singleLine(p))
+	}
+	return s.send(EventRemoveSignals, buildEvent(opts), lines)
+}
+
+// MergeFragments morphs HTML into the page. Without a selector, elements are
+// matched by id.
+func (s *Writer) MergeFragments(html string, opts ...Option) error {
+	e := buildEvent(opts)
+	if s.protocol == ProtocolV1 {
+		mode := ""
+		if e.mergeMode != "" && e.mergeMode != MergeMorph {
+			mode = mergeModeV1[e.mergeMode]
+		}
+		return s.patchElements(e, e.selector, mode, html)
+	}
+
+	var lines []string
+	if e.selector != "" {
+		lines = append(lines, "selector "+singleLine(e.selector))
+	}
+	if e.mergeMode != "" && e.mergeMode != MergeMorph {
+		lines = append(lines, "mergeMode "+e.mergeMode)
+	}
+	if e.viewTransition {
+		lines = append(lines, "useViewTransition true")
+	}
+	lines = append(lines, prefixLines("fragments ", html)...)
+	return s.send(EventMergeFragments, e, lines)
+}
+
+// RemoveFragments removes every element matching selector
+func (s *Writer) RemoveFragments(selector string, opts ...Option) error {
+	e := buildEvent(opts)
+	if s.protocol == ProtocolV1 {
+		return s.patchElements(e, selector, "remove", "")
+	}
+
+	lines := []string{"selector " + singleLine(selector)}
+	if e.viewTransition {
+		lines = append(lines, "useViewTransition true")
+	}
+	return s.send(EventRemoveFragments, e, lines)
+}
+
+// ExecuteScript runs JavaScript in the browser
+func (s *Writer) ExecuteScript(script string, opts ...Option) error {
+	e := buildEvent(opts)
+	if s.protocol == ProtocolV1 {
+		// 1.0 runs scripts by appending a script element to the body
+		effect := ` data-effect="el.remove()"`
+		if e.keepScript {
+			effect = ""
+		}
+		return s.patchElements(e, "body", "append", "<script"+effect+">"+scriptEnd.ReplaceAllString(script, `<\/$1`)+"</script>")
+	}
+
+	var lines []string
+	if e.keepScript {
+		lines = append(lines, "autoRemove false")
+	}
+	lines = append(lines, prefixLines("script ", script)...)
+	return s.send(EventExecuteScript, e, lines)
+}
+
+// scriptEnd matches a closing script tag, which would end a script element
+// early; "<\/script" means the same in JavaScript
+var scriptEnd = regexp.MustCompile(`(?i)</(script)`)
+
+// Comment writes an SSE comment line, which clients ignore. Use it as a
+// heartbeat to keep proxies from closing an idle stream.
+func (s *Writer) Comment(text string) error {
+	return s.write(": " + singleLine(text) + "\n\n")
+}
+
+// LastWrite returns when anything was last written to the stream
+func (s *Writer) LastWrite() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastWrite
+}
+
+// Error reports a user-facing error and stops the loading state
+func (s *Writer) Error(message string) error {
+	return s.MergeSignals(Signals{"error": message, "loading": false})
+}
+
+// patchElements writes a 1.0 datastar-patch-elements event. An empty mode
+// means the default, morphing outer HTML.
+func (s *Writer) patchElements(e event, selector, mode, html string) error {
+	var lines []string
+	if selector != "" {
+		lines = append(lines, "selector "+singleLine(selector))
+	}
+	if mode != "" {
+		lines = append(lines, "mode "+mode)
+	}
+	if e.viewTransition {
+		lines = append(lines, "useViewTransition true")
+	}
+	if html != "" {
+		lines = append(lines, prefixLines("elements ", html)...)
+	}
+	return s.send(EventPatchElements, e, lines)
+}
+
+// send writes one event and flushes it
+func (s *Writer) send(eventType string, e event, lines []string) error {
+	var b strings.Builder
+	b.WriteString("event: " + eventType + "\n")
+	if e.id != "" {
+		b.WriteString("id: " + singleLine(e.id) + "\n")
+	}
+	if e.retry > 0 && e.retry != DefaultRetry {
+		b.WriteString("retry: " + strconv.FormatInt(e.retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range lines {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	return s.write(b.String())
+}
+
+// write sends raw SSE text and flushes it
+func (s *Writer) write(text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if _, err := s.w.Write([]byte(text)); err != nil {
+		s.err = err
+		return err
+	}
+	s.flusher.Flush()
+	s.lastWrite = time.Now()
+	return nil
+}
+
+// prefixLines splits text into data lines, each starting with prefix
+func prefixLines(prefix, text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	parts := strings.Split(text, "\n")
+	lines := make([]string, len(parts))
+	for i, part := range parts {
+		lines[i] = prefix + part
+	}
+	return lines
+}
+
+// singleLine strips line breaks, which would end an SSE field early
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
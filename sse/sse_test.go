@@ -0,0 +1,396 @@
+package sse
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestWriter returns a Writer speaking protocol and the recorder behind it
+func newTestWriter(t *testing.T, protocol Protocol) (*Writer, *httptest.ResponseRecorder) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	w, err := NewWithProtocol(rec, protocol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w, rec
+}
+
+// sentEvent is one parsed event
+type sentEvent struct {
+	event, id, retry string
+	data             []string
+}
+
+// parseEvents reads events the way a browser does, failing on any line
+// that isn't a known field
+func parseEvents(t *testing.T, stream string) []sentEvent {
+	t.Helper()
+	var events []sentEvent
+	var cur sentEvent
+	scanner := bufio.NewScanner(strings.NewReader(stream))
+	for scanner.Scan() {
+		line := scanner.Text()
+		field, value, _ := strings.Cut(line, ": ")
+		switch {
+		case line == "":
+			events = append(events, cur)
+			cur = sentEvent{}
+		case field == "event":
+			cur.event = value
+		case field == "id":
+			cur.id = value
+		case field == "retry":
+			cur.retry = value
+		case field == "data":
+			cur.data = append(cur.data, value)
+		default:
+			t.Fatalf("stray line %q in stream:\n%s", line, stream)
+		}
+	}
+	if cur.event != "" || len(cur.data) > 0 {
+		t.Fatalf("unterminated event in stream:\n%s", stream)
+	}
+	return events
+}
+
+func TestMergeSignals(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals any
+		opts    []Option
+		want    string
+	}{
+		{
+			name:    "plain",
+			signals: Signals{"loading": true},
+			want:    "event: datastar-merge-signals\ndata: signals {\"loading\":true}\n\n",
+		},
+		{
+			name:    "quotes and newlines in an error",
+			signals: Signals{"error": "upstream said \"no\"\nline two\r\nline three"},
+			want:    "event: datastar-merge-signals\ndata: signals {\"error\":\"upstream said \\\"no\\\"\\nline two\\r\\nline three\"}\n\n",
+		},
+		{
+			name:    "closing script tag",
+			signals: Signals{"error": "</script><script>alert(1)</script>"},
+			want:    "event: datastar-merge-signals\ndata: signals {\"error\":\"\\u003c/script\\u003e\\u003cscript\\u003ealert(1)\\u003c/script\\u003e\"}\n\n",
+		},
+		{
+			name:    "only if missing",
+			signals: Signals{"step": 0},
+			opts:    []Option{WithOnlyIfMissing()},
+			want:    "event: datastar-merge-signals\ndata: onlyIfMissing true\ndata: signals {\"step\":0}\n\n",
+		},
+		{
+			name: "struct",
+			signals: struct {
+				Name string `json:"name"`
+			}{"Ada"},
+			want: "event: datastar-merge-signals\ndata: signals {\"name\":\"Ada\"}\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, rec := newTestWriter(t, ProtocolBeta)
+			if err := w.MergeSignals(tt.signals, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if got := rec.Body.String(); got != tt.want {
+				t.Fatalf("MergeSignals wrote\n%q\nwant\n%q", got, tt.want)
+			}
+
+			// The data line is the exact JSON, whatever the text held
+			events := parseEvents(t, rec.Body.String())
+			if len(events) != 1 {
+				t.Fatalf("%d events, want 1", len(events))
+			}
+			payload := strings.TrimPrefix(events[0].data[len(events[0].data)-1], "signals ")
+			var decoded map[string]any
+			if err := json.Unmarshal([]byte(payload), &decoded); err != nil {
+				t.Fatalf("signals aren't valid JSON: %v", err)
+			}
+		})
+	}
+}
+
+func TestMergeSignalsRejectsNonJSON(t *testing.T) {
+	w, rec := newTestWriter(t, ProtocolBeta)
+	if err := w.MergeSignals(Signals{"f": func() {}}); err == nil {
+		t.Fatal("no error for an unencodable value")
+	}
+	if rec.Body.Len() != 0 {
+		t.Fatalf("wrote %q for an unencodable value", rec.Body.String())
+	}
+}
+
+func TestMergeFragments(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		opts []Option
+		want string
+	}{
+		{
+			name: "one line",
+			html: `<div id="a">Hi</div>`,
+			want: "event: datastar-merge-fragments\ndata: fragments <div id=\"a\">Hi</div>\n\n",
+		},
+		{
+			name: "each line prefixed",
+			html: "<ul id=\"list\">\n  <li>One</li>\r\n  <li>Two</li>\r</ul>",
+			want: "event: datastar-merge-fragments\n" +
+				"data: fragments <ul id=\"list\">\n" +
+				"data: fragments   <li>One</li>\n" +
+				"data: fragments   <li>Two</li>\n" +
+				"data: fragments </ul>\n\n",
+		},
+		{
+			name: "blank line inside",
+			html: "<p>One</p>\n\n<p>Two</p>",
+			want: "event: datastar-merge-fragments\ndata: fragments <p>One</p>\ndata: fragments \ndata: fragments <p>Two</p>\n\n",
+		},
+		{
+			name: "selector, mode and transition",
+			html: "<li>New</li>",
+			opts: []Option{WithSelector("#list\n"), WithMergeMode(MergeAppend), WithViewTransition()},
+			want: "event: datastar-merge-fragments\n" +
+				"data: selector #list\n" +
+				"data: mergeMode append\n" +
+				"data: useViewTransition true\n" +
+				"data: fragments <li>New</li>\n\n",
+		},
+		{
+			name: "morph is the default",
+			html: "<p id=\"p\"></p>",
+			opts: []Option{WithMergeMode(MergeMorph)},
+			want: "event: datastar-merge-fragments\ndata: fragments <p id=\"p\"></p>\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, rec := newTestWriter(t, ProtocolBeta)
+			if err := w.MergeFragments(tt.html, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if got := rec.Body.String(); got != tt.want {
+				t.Fatalf("MergeFragments wrote\n%q\nwant\n%q", got, tt.want)
+			}
+			parseEvents(t, rec.Body.String())
+		})
+	}
+}
+
+func TestExecuteScript(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol Protocol
+		script   string
+		opts     []Option
+		want     string
+	}{
+		{
+			name:     "beta",
+			protocol: ProtocolBeta,
+			script:   "console.log(\"a\")\nconsole.log('b')",
+			want:     "event: datastar-execute-script\ndata: script console.log(\"a\")\ndata: script console.log('b')\n\n",
+		},
+		{
+			name:     "beta keeps the script",
+			protocol: ProtocolBeta,
+			script:   "x()",
+			opts:     []Option{WithKeepScript()},
+			want:     "event: datastar-execute-script\ndata: autoRemove false\ndata: script x()\n\n",
+		},
+		{
+			name:     "v1",
+			protocol: ProtocolV1,
+			script:   `window.location.href = "/app"`,
+			want: "event: datastar-patch-elements\n" +
+				"data: selector body\n" +
+				"data: mode append\n" +
+				"data: elements <script data-effect=\"el.remove()\">window.location.href = \"/app\"</script>\n\n",
+		},
+		{
+			name:     "v1 closing script tag",
+			protocol: ProtocolV1,
+			script:   `alert("</script><b>")` + "\n" + `alert("</SCRIPT>")`,
+			opts:     []Option{WithKeepScript()},
+			want: "event: datastar-patch-elements\n" +
+				"data: selector body\n" +
+				"data: mode append\n" +
+				"data: elements <script>alert(\"<\\/script><b>\")\n" +
+				"data: elements alert(\"<\\/SCRIPT>\")</script>\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, rec := newTestWriter(t, tt.protocol)
+			if err := w.ExecuteScript(tt.script, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if got := rec.Body.String(); got != tt.want {
+				t.Fatalf("ExecuteScript wrote\n%q\nwant\n%q", got, tt.want)
+			}
+			parseEvents(t, rec.Body.String())
+		})
+	}
+}
+
+func TestEventOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"id", []Option{WithID("42")}, "event: datastar-merge-signals\nid: 42\ndata: signals {}\n\n"},
+		{"id with a newline", []Option{WithID("4\r\n2")}, "event: datastar-merge-signals\nid: 42\ndata: signals {}\n\n"},
+		{"retry", []Option{WithRetry(2500 * time.Millisecond)}, "event: datastar-merge-signals\nretry: 2500\ndata: signals {}\n\n"},
+		{"default retry is left out", []Option{WithRetry(DefaultRetry)}, "event: datastar-merge-signals\ndata: signals {}\n\n"},
+		{"id and retry", []Option{WithRetry(5 * time.Second), WithID("a-1")}, "event: datastar-merge-signals\nid: a-1\nretry: 5000\ndata: signals {}\n\n"},
+	}
+	for _, tt := range tests {
+		w, rec := newTestWriter(t, ProtocolBeta)
+		if err := w.MergeSignals(Signals{}, tt.opts...); err != nil {
+			t.Fatal(err)
+		}
+		if got := rec.Body.String(); got != tt.want {
+			t.Errorf("%s: wrote\n%q\nwant\n%q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProtocolEventNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		send     func(*Writer) error
+		beta, v1 string
+		betaData string
+		v1Data   string
+	}{
+		{
+			name:     "merge signals",
+			send:     func(w *Writer) error { return w.MergeSignals(Signals{"a": 1}) },
+			beta:     EventMergeSignals,
+			v1:       EventPatchSignals,
+			betaData: "signals {\"a\":1}",
+			v1Data:   "signals {\"a\":1}",
+		},
+		{
+			name:     "remove signals",
+			send:     func(w *Writer) error { return w.RemoveSignals([]string{"form.name"}) },
+			beta:     EventRemoveSignals,
+			v1:       EventPatchSignals,
+			betaData: "paths form.name",
+			v1Data:   "signals {\"form\":{\"name\":null}}",
+		},
+		{
+			name:     "merge fragments",
+			send:     func(w *Writer) error { return w.MergeFragments("<p id=\"p\"></p>", WithMergeMode(MergeOuter)) },
+			beta:     EventMergeFragments,
+			v1:       EventPatchElements,
+			betaData: "mergeMode outer|fragments <p id=\"p\"></p>",
+			v1Data:   "mode replace|elements <p id=\"p\"></p>",
+		},
+		{
+			name:     "remove fragments",
+			send:     func(w *Writer) error { return w.RemoveFragments("#gone") },
+			beta:     EventRemoveFragments,
+			v1:       EventPatchElements,
+			betaData: "selector #gone",
+			v1Data:   "selector #gone|mode remove",
+		},
+		{
+			name:     "execute script",
+			send:     func(w *Writer) error { return w.ExecuteScript("go()") },
+			beta:     EventExecuteScript,
+			v1:       EventPatchElements,
+			betaData: "script go()",
+			v1Data:   "selector body|mode append|elements <script data-effect=\"el.remove()\">go()</script>",
+		},
+	}
+	for _, tt := range tests {
+		for _, p := range []struct {
+			protocol    Protocol
+			event, data string
+		}{{ProtocolBeta, tt.beta, tt.betaData}, {ProtocolV1, tt.v1, tt.v1Data}} {
+			w, rec := newTestWriter(t, p.protocol)
+			if w.Protocol() != p.protocol {
+				t.Fatalf("Protocol() = %v, want %v", w.Protocol(), p.protocol)
+			}
+			if err := tt.send(w); err != nil {
+				t.Fatal(err)
+			}
+			events := parseEvents(t, rec.Body.String())
+			if len(events) != 1 || events[0].event != p.event || strings.Join(events[0].data, "|") != p.data {
+				t.Errorf("%s over %v = %+v, want event %s with data %q", tt.name, p.protocol, events, p.event, p.data)
+			}
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	t.Setenv("DATASTAR_VERSION", "")
+	tests := []struct {
+		header string
+		want   Protocol
+	}{
+		{"", ProtocolBeta},
+		{"1.0.0-beta.11", ProtocolBeta},
+		{"0.21.4", ProtocolBeta},
+		{"1.0.0-RC.5", ProtocolV1},
+		{"v1.0.0", ProtocolV1},
+		{"1.1.0", ProtocolV1},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.header != "" {
+			r.Header.Set(VersionHeader, tt.header)
+		}
+		if got := Negotiate(r); got != tt.want {
+			t.Errorf("Negotiate(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+
+	t.Setenv("DATASTAR_VERSION", "1.0.0")
+	if got := Negotiate(httptest.NewRequest(http.MethodGet, "/", nil)); got != ProtocolV1 {
+		t.Errorf("Negotiate with DATASTAR_VERSION 1.0.0 = %v, want v1", got)
+	}
+}
+
+func TestNewSetsHeaders(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if _, err := New(rec, httptest.NewRequest(http.MethodGet, "/", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+}
+
+// noFlush is a ResponseWriter that can't stream
+type noFlush struct{ http.ResponseWriter }
+
+func TestNewNeedsFlusher(t *testing.T) {
+	if _, err := NewWithProtocol(noFlush{httptest.NewRecorder()}, ProtocolBeta); err != ErrUnsupported {
+		t.Fatalf("NewWithProtocol = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestComment(t *testing.T) {
+	w, rec := newTestWriter(t, ProtocolBeta)
+	if err := w.Comment("heart\nbeat"); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Body.String(); got != ": heartbeat\n\n" {
+		t.Fatalf("Comment wrote %q", got)
+	}
+}
@@ -0,0 +1,71 @@
+package templates
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func renderBatchesPage(t *testing.T) string {
+	t.Helper()
+	var b strings.Builder
+	bases := []BaseResume{{ID: "profile", Label: "Profile"}}
+	if err := BatchesPage(bases, nil, 10).Render(context.Background(), &b); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestDatastarAttributesFollowVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    []string
+		not     []string
+	}{
+		{
+			version: "1.0.0-beta.11",
+			want:    []string{"data-on-submit__prevent=", "data-bind-batch_base", `data-attr-disabled="$batch_creating"`, "@1.0.0-beta.11/"},
+			not:     []string{"data-on:", "data-bind:", "data-attr:"},
+		},
+		{
+			version: "1.0.0",
+			want:    []string{"data-on:submit__prevent=", "data-bind:batch_base", `data-attr:disabled="$batch_creating"`, "@1.0.0/"},
+			not:     []string{"data-on-", "data-bind-", "data-attr-"},
+		},
+	}
+	for _, tt := range tests {
+		t.Setenv("DATASTAR_VERSION", tt.version)
+		html := renderBatchesPage(t)
+		for _, want := range tt.want {
+			if !strings.Contains(html, want) {
+				t.Errorf("%s: page has no %q", tt.version, want)
+			}
+		}
+		for _, not := range tt.not {
+			if strings.Contains(html, not) {
+				t.Errorf("%s: page has %q", tt.version, not)
+			}
+		}
+	}
+}
+
+func TestDsOnRenamedEvents(t *testing.T) {
+	t.Setenv("DATASTAR_VERSION", "1.0.0-beta.11")
+	if got := dsOn("load", "x"); got["data-on-load"] != "x" {
+		t.Errorf("beta load = %v", got)
+	}
+	if got := dsOn("datastar-sse", "x"); got["data-on-datastar-sse"] != "x" {
+		t.Errorf("beta datastar-sse = %v", got)
+	}
+
+	t.Setenv("DATASTAR_VERSION", "1.0.0")
+	if got := dsOn("load", "x"); got["data-init"] != "x" {
+		t.Errorf("1.0 load = %v", got)
+	}
+	if got := dsOn("datastar-sse", "x"); got["data-on:datastar-fetch"] != "x" {
+		t.Errorf("1.0 datastar-sse = %v", got)
+	}
+	if got := dsClass("completed", "$step >= 1"); got["data-class:completed"] != "$step >= 1" {
+		t.Errorf("1.0 class = %v", got)
+	}
+}
@@ -8,6 +8,12 @@ package templates
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
+import (
+	"fmt"
+
+	"github.com/johnhkchen/resume-tweaker/sse"
+)
+
 // Layout is the base layout - use for public pages
 func Layout(title string) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
@@ -37,13 +43,26 @@ func Layout(title string) templ.Component {
 		var templ_7745c5c3_Var2 string
 		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(title)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/layout.templ`, Line: 10, Col: 17}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/layout.templ`, Line: 16, Col: 17}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, " | Resume Tweaker</title><link rel=\"stylesheet\" href=\"/static/css/output.css\"><script type=\"module\" src=\"https://cdn.jsdelivr.net/npm/@starfederation/datastar@1.0.0-beta.11/dist/datastar.min.js\"></script></head><body><header class=\"border-b\" style=\"border-color: var(--color-grey-light);\"><div class=\"container\" style=\"display: flex; align-items: center; justify-content: space-between; padding-top: var(--spacing-md); padding-bottom: var(--spacing-md);\"><a href=\"/\" style=\"font-family: var(--font-serif); font-size: 1.25rem; font-weight: 600; color: var(--color-slate); text-decoration: none;\">Resume Tweaker</a><nav style=\"display: flex; gap: var(--spacing-lg); align-items: center;\"><a href=\"/login\" class=\"btn-primary\" style=\"padding: var(--spacing-xs) var(--spacing-md); font-size: 0.875rem;\">Sign In</a></nav></div></header><main>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, " | Resume Tweaker</title><link rel=\"stylesheet\" href=\"/static/css/output.css\"><script type=\"module\" src=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var3 string
+		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(sse.ScriptURL())
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/layout.templ`, Line: 18, Col: 46}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "\"></script></head><body><header class=\"border-b\" style=\"border-color: var(--color-grey-light);\"><div class=\"container\" style=\"display: flex; align-items: center; justify-content: space-between; padding-top: var(--spacing-md); padding-bottom: var(--spacing-md);\"><a href=\"/\" style=\"font-family: var(--font-serif); font-size: 1.25rem; font-weight: 600; color: var(--color-slate); text-decoration: none;\">Resume Tweaker</a><nav style=\"display: flex; gap: var(--spacing-lg); align-items: center;\"><a href=\"/login\" class=\"btn-primary\" style=\"padding: var(--spacing-xs) var(--spacing-md); font-size: 0.875rem;\">Sign In</a></nav></div></header><main>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -51,7 +70,7 @@ func Layout(title string) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "</main><footer class=\"border-t\" style=\"border-color: var(--color-grey-light); margin-top: auto;\"><div class=\"container\" style=\"padding-top: var(--spacing-md); padding-bottom: var(--spacing-md); text-align: center;\"><p style=\"font-size: 0.875rem; color: var(--color-grey);\">Part of the <a href=\"https://tweaking.app\" style=\"color: var(--color-sage); text-decoration: underline;\">tweaking.app</a> family</p></div></footer></body></html>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</main><footer class=\"border-t\" style=\"border-color: var(--color-grey-light); margin-top: auto;\"><div class=\"container\" style=\"padding-top: var(--spacing-md); padding-bottom: var(--spacing-md); text-align: center;\"><p style=\"font-size: 0.875rem; color: var(--color-grey);\">Part of the <a href=\"https://tweaking.app\" style=\"color: var(--color-sage); text-decoration: underline;\">tweaking.app</a> family</p></div></footer></body></html>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -59,6 +78,74 @@ func Layout(title string) templ.Component {
 	})
 }
 
+// datastarPost builds a Datastar @post action that reports the client's
+// Datastar version, so the server answers in the matching SSE format
+func datastarPost(url string) string {
+	return datastarAction("post", "'"+url+"'")
+}
+
+// datastarGet is datastarPost for @get
+func datastarGet(url string) string {
+	return datastarAction("get", "'"+url+"'")
+}
+
+// datastarGetExpr is datastarGet for a URL built by a Datastar expression
+func datastarGetExpr(urlExpr string) string {
+	return datastarAction("get", urlExpr)
+}
+
+// datastarPostForm is datastarPost sending the form matched by selector as
+// multipart data instead of signals, for file uploads
+func datastarPostForm(url, selector string) string {
+	return fmt.Sprintf("@post('%s', {contentType: 'form', selector: '%s', headers: {'%s': '%s'}})", url, selector, sse.VersionHeader, sse.Version())
+}
+
+func datastarAction(method, urlExpr string) string {
+	return fmt.Sprintf("@%s(%s, {headers: {'%s': '%s'}})", method, urlExpr, sse.VersionHeader, sse.Version())
+}
+
+// Datastar 1.0 renamed its attributes: keys follow a colon instead of a
+// hyphen (data-on:click, data-bind:x), data-on-load became data-init and
+// the fetch lifecycle event is datastar-fetch rather than datastar-sse.
+// The ds* helpers below spell an attribute for the release the pages load.
+
+// dsOn runs expr on event; event may carry modifiers ("submit__prevent")
+func dsOn(event, expr string) templ.Attributes {
+	if sse.ProtocolFor(sse.Version()) == sse.ProtocolV1 {
+		switch event {
+		case "load":
+			return templ.Attributes{"data-init": expr}
+		case "datastar-sse":
+			event = "datastar-fetch"
+		}
+	}
+	return templ.Attributes{dsKey("on", event): expr}
+}
+
+// dsBind two-way binds an input to signal
+func dsBind(signal string) templ.Attributes {
+	return templ.Attributes{dsKey("bind", signal): true}
+}
+
+// dsAttr sets the HTML attribute name from expr
+func dsAttr(name, expr string) templ.Attributes {
+	return templ.Attributes{dsKey("attr", name): expr}
+}
+
+// dsClass toggles class name on expr
+func dsClass(name, expr string) templ.Attributes {
+	return templ.Attributes{dsKey("class", name): expr}
+}
+
+// dsKey names a keyed Datastar attribute, e.g. data-on-click (beta) or
+// data-on:click (1.0)
+func dsKey(plugin, key string) string {
+	if sse.ProtocolFor(sse.Version()) == sse.ProtocolV1 {
+		return "data-" + plugin + ":" + key
+	}
+	return "data-" + plugin + "-" + key
+}
+
 // LayoutAuth is the layout for authenticated pages - shows logout instead of sign in
 func LayoutAuth(title string) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
@@ -76,33 +163,46 @@ func LayoutAuth(title string) templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var3 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var3 == nil {
-			templ_7745c5c3_Var3 = templ.NopComponent
+		templ_7745c5c3_Var4 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var4 == nil {
+			templ_7745c5c3_Var4 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<!doctype html><html lang=\"en\"><head><meta charset=\"UTF-8\"><meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\"><title>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "<!doctype html><html lang=\"en\"><head><meta charset=\"UTF-8\"><meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\"><title>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var5 string
+		templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(title)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/layout.templ`, Line: 122, Col: 17}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, " | Resume Tweaker</title><link rel=\"stylesheet\" href=\"/static/css/output.css\"><script type=\"module\" src=\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var4 string
-		templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(title)
+		var templ_7745c5c3_Var6 string
+		templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(sse.ScriptURL())
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/layout.templ`, Line: 48, Col: 17}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/layout.templ`, Line: 124, Col: 46}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, " | Resume Tweaker</title><link rel=\"stylesheet\" href=\"/static/css/output.css\"><script type=\"module\" src=\"https://cdn.jsdelivr.net/npm/@starfederation/datastar@1.0.0-beta.11/dist/datastar.min.js\"></script></head><body><header class=\"border-b\" style=\"border-color: var(--color-grey-light);\"><div class=\"container\" style=\"display: flex; align-items: center; justify-content: space-between; padding-top: var(--spacing-md); padding-bottom: var(--spacing-md);\"><a href=\"/\" style=\"font-family: var(--font-serif); font-size: 1.25rem; font-weight: 600; color: var(--color-slate); text-decoration: none;\">Resume Tweaker</a><nav style=\"display: flex; gap: var(--spacing-lg); align-items: center;\"><a href=\"/app/tweak\" style=\"color: var(--color-slate-light); text-decoration: none; font-size: 0.9375rem;\">Tweak</a> <a href=\"/logout\" style=\"color: var(--color-slate-light); text-decoration: none; font-size: 0.9375rem;\">Sign Out</a></nav></div></header><main>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "\"></script></head><body><header class=\"border-b\" style=\"border-color: var(--color-grey-light);\"><div class=\"container\" style=\"display: flex; align-items: center; justify-content: space-between; padding-top: var(--spacing-md); padding-bottom: var(--spacing-md);\"><a href=\"/\" style=\"font-family: var(--font-serif); font-size: 1.25rem; font-weight: 600; color: var(--color-slate); text-decoration: none;\">Resume Tweaker</a><nav style=\"display: flex; gap: var(--spacing-lg); align-items: center;\"><a href=\"/app/tweak\" style=\"color: var(--color-slate-light); text-decoration: none; font-size: 0.9375rem;\">Tweak</a> <a href=\"/app/batches\" style=\"color: var(--color-slate-light); text-decoration: none; font-size: 0.9375rem;\">Batch</a> <a href=\"/logout\" style=\"color: var(--color-slate-light); text-decoration: none; font-size: 0.9375rem;\">Sign Out</a></nav></div></header><main>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templ_7745c5c3_Var3.Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = templ_7745c5c3_Var4.Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</main><footer class=\"border-t\" style=\"border-color: var(--color-grey-light); margin-top: auto;\"><div class=\"container\" style=\"padding-top: var(--spacing-md); padding-bottom: var(--spacing-md); text-align: center;\"><p style=\"font-size: 0.875rem; color: var(--color-grey);\">Part of the <a href=\"https://tweaking.app\" style=\"color: var(--color-sage); text-decoration: underline;\">tweaking.app</a> family</p></div></footer></body></html>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "</main><footer class=\"border-t\" style=\"border-color: var(--color-grey-light); margin-top: auto;\"><div class=\"container\" style=\"padding-top: var(--spacing-md); padding-bottom: var(--spacing-md); text-align: center;\"><p style=\"font-size: 0.875rem; color: var(--color-grey);\">Part of the <a href=\"https://tweaking.app\" style=\"color: var(--color-sage); text-decoration: underline;\">tweaking.app</a> family</p></div></footer></body></html>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
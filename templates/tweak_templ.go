@@ -8,7 +8,41 @@ package templates
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
-func TweakPage() templ.Component {
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johnhkchen/resume-tweaker/export"
+	"github.com/johnhkchen/resume-tweaker/extract"
+	"github.com/johnhkchen/resume-tweaker/jobboard"
+	"github.com/johnhkchen/resume-tweaker/lang"
+	"github.com/johnhkchen/resume-tweaker/quota"
+	"github.com/johnhkchen/resume-tweaker/settings"
+)
+
+// quotaSignals seeds the remaining-quota signals from the server-side status
+func quotaSignals(status quota.Status) string {
+	return fmt.Sprintf("{ quota_remaining: %d, quota_limit: %d, quota_reset_at: '%s' }",
+		status.TweaksRemaining(), status.TweaksPerDay, status.TweaksReset.Format("Jan 2, 15:04 MST"))
+}
+
+// settingsSignals seeds preference signals from the user's saved settings
+func settingsSignals(prefs settings.Settings) string {
+	return fmt.Sprintf("{ redact_pii: %t }", prefs.RedactPII)
+}
+
+// exportSignals seeds the export choices with the default template and size
+// and the user's last LaTeX template
+func exportSignals(prefs settings.Settings) string {
+	latex, ok := export.LaTeXTemplateByName(prefs.LaTeXTemplate)
+	if !ok {
+		latex = export.LaTeXTemplates[0]
+	}
+	return fmt.Sprintf("{ export_template: '%s', export_size: '%s', latex_template: '%s' }",
+		export.Templates[0].Name, export.PageSizes[0].Name, latex.Name)
+}
+
+func TweakPage(status quota.Status, prefs settings.Settings, activeJobID string, savedJobs []jobboard.Saved, hasProfile bool) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -41,7 +75,483 @@ func TweakPage() templ.Component {
 				}()
 			}
 			ctx = templ.InitializeContext(ctx)
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"container\" style=\"padding-top: var(--spacing-xl); padding-bottom: var(--spacing-2xl);\"><div data-signals=\"{ result: '', loading: false, error: '', step: 0, resume: '', job_description: '' }\"><!-- Header --><div style=\"text-align: center; margin-bottom: var(--spacing-2xl);\"><h1 style=\"font-family: var(--font-serif); font-size: 2rem; font-weight: 600; margin-bottom: var(--spacing-sm);\">Tweak Your Resume</h1><p style=\"color: var(--color-slate-light); max-width: 500px; margin: 0 auto;\">Paste your resume and job description below. Watch as we suggest improvements in real-time.</p></div><!-- Form Card --><div class=\"card\" style=\"margin-bottom: var(--spacing-xl);\"><form data-on-submit__prevent=\"@post('/app/tweak/stream')\" style=\"display: flex; flex-direction: column; gap: var(--spacing-lg);\"><div><label for=\"resume\" style=\"display: block; font-weight: 600; margin-bottom: var(--spacing-xs); color: var(--color-slate);\">Your Resume</label> <textarea id=\"resume\" name=\"resume\" data-bind-resume rows=\"8\" class=\"input-field\" placeholder=\"Paste your current resume here...\" style=\"resize: vertical;\"></textarea></div><div><label for=\"job_description\" style=\"display: block; font-weight: 600; margin-bottom: var(--spacing-xs); color: var(--color-slate);\">Target Job Description</label> <textarea id=\"job_description\" name=\"job_description\" data-bind-job_description rows=\"5\" class=\"input-field\" placeholder=\"Paste the job description you're applying to...\" style=\"resize: vertical;\"></textarea></div><div style=\"display: flex; gap: var(--spacing-md); align-items: center;\"><button type=\"submit\" class=\"btn-primary\" data-bind-disabled=\"$loading\"><span data-show=\"!$loading\">Analyze & Tweak</span> <span data-show=\"$loading\" style=\"display: flex; align-items: center; gap: var(--spacing-xs);\"><span class=\"spinner\"></span> Processing...</span></button> <button type=\"button\" class=\"btn-secondary\" data-on-click=\"$result = ''; $error = ''; $step = 0;\" data-show=\"$result || $error\">Clear</button></div></form></div><!-- Error Display --><div data-show=\"$error\" class=\"card\" style=\"background-color: var(--color-bg-error); border-left: 3px solid var(--color-text-error); margin-bottom: var(--spacing-xl);\"><p style=\"font-weight: 600; color: var(--color-text-error); margin-bottom: var(--spacing-xs);\">Something went wrong</p><p style=\"color: var(--color-text-error);\" data-text=\"$error\"></p></div><!-- Progress Steps --><div data-show=\"$loading || $result\" style=\"margin-bottom: var(--spacing-xl);\"><h3 style=\"font-family: var(--font-serif); font-size: 1.125rem; margin-bottom: var(--spacing-md);\">Progress</h3><div style=\"display: flex; flex-direction: column; gap: var(--spacing-sm);\"><div class=\"progress-item\" data-class-completed=\"$step >= 1\"><span class=\"progress-icon\"><span data-show=\"$step < 1\">○</span> <span data-show=\"$step >= 1\">✓</span></span> <span>Analyzing your resume</span></div><div class=\"progress-item\" data-class-completed=\"$step >= 2\"><span class=\"progress-icon\"><span data-show=\"$step < 2\">○</span> <span data-show=\"$step >= 2\">✓</span></span> <span>Parsing job requirements</span></div><div class=\"progress-item\" data-class-completed=\"$step >= 3\"><span class=\"progress-icon\"><span data-show=\"$step < 3\">○</span> <span data-show=\"$step >= 3\">✓</span></span> <span>Identifying alignment opportunities</span></div><div class=\"progress-item\" data-class-completed=\"$step >= 4\"><span class=\"progress-icon\"><span data-show=\"$step < 4\">○</span> <span data-show=\"$step >= 4\">✓</span></span> <span>Generating suggestions</span></div></div></div><!-- Streaming Result --><div data-show=\"$result\" class=\"card\"><div style=\"display: flex; align-items: center; justify-content: space-between; margin-bottom: var(--spacing-md);\"><h3 style=\"font-family: var(--font-serif); font-size: 1.125rem;\">Suggestions</h3><div style=\"display: flex; gap: var(--spacing-sm);\"><span class=\"badge badge-success\" data-show=\"!$loading\">Complete</span> <span class=\"badge badge-warning\" data-show=\"$loading\">Streaming...</span> <button class=\"btn-secondary\" style=\"padding: var(--spacing-xs) var(--spacing-sm); font-size: 0.875rem;\" data-on-click=\"navigator.clipboard.writeText($result); this.textContent = 'Copied!'; setTimeout(() => this.textContent = 'Copy', 2000)\">Copy</button></div></div><div style=\"background-color: var(--color-bg-neutral); border-radius: var(--border-radius); padding: var(--spacing-md);\"><div class=\"streaming-output\" data-text=\"$result\"></div><span class=\"streaming-cursor\" data-show=\"$loading\"></span></div></div></div></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"container\" style=\"padding-top: var(--spacing-xl); padding-bottom: var(--spacing-2xl);\"><div data-signals=\"{ result: '', loading: false, error: '', step: 0, queue_position: 0, served_by: '', notice: '', redactions: [], injection_warning: '', trims: [], result_seq: 0, job_id: '', stream_status: '', resume: '', job_description: '', mode: 'tweak', target_language: '', resume_language: '', job_language: '', resume_before_upload: '', uploading: false, upload_error: '', upload_name: '', upload_id: '', resume_id: '', job_url: '', job_importing: false, job_import_error: '', job_import_source: '' }\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsOn("datastar-sse", "evt.detail.type == 'finished' && $stream_status == 'streaming' && ($stream_status = 'interrupted')"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, ">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if activeJobID != "" {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<!-- Pick up a tweak still running from before a reload --> <div")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsOn("load", datastarGet("/app/tweak/jobs/"+activeJobID+"/stream")))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "<!-- Header --><div style=\"text-align: center; margin-bottom: var(--spacing-2xl);\"><h1 style=\"font-family: var(--font-serif); font-size: 2rem; font-weight: 600; margin-bottom: var(--spacing-sm);\">Tweak Your Resume</h1><p style=\"color: var(--color-slate-light); max-width: 500px; margin: 0 auto;\">Paste your resume and job description below. Watch as we suggest improvements in real-time.</p></div><!-- Form Card --><div class=\"card\" style=\"margin-bottom: var(--spacing-xl);\"><!-- The resume file input belongs to this form, so uploads don't submit the tweak form --><form id=\"resume-upload\" enctype=\"multipart/form-data\" method=\"post\" hidden></form><form id=\"board-jobs-form\" method=\"post\" hidden></form><form")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsOn("submit__prevent", datastarPost("/app/tweak/stream")))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, " style=\"display: flex; flex-direction: column; gap: var(--spacing-lg);\"><div style=\"display: flex; gap: var(--spacing-md); flex-wrap: wrap;\"><div style=\"flex: 1; min-width: 200px;\"><label for=\"mode\" style=\"display: block; font-weight: 600; margin-bottom: var(--spacing-xs); color: var(--color-slate);\">What should we do?</label> <select id=\"mode\" name=\"mode\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsBind("mode"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, " class=\"input-field\"><option value=\"tweak\">Tailor to a job description</option> <option value=\"translate\">Translate and localize</option></select></div><div style=\"flex: 1; min-width: 200px;\"><label for=\"target_language\" style=\"display: block; font-weight: 600; margin-bottom: var(--spacing-xs); color: var(--color-slate);\">Output Language</label> <select id=\"target_language\" name=\"target_language\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsBind("target_language"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, " class=\"input-field\"><option value=\"\">Same as resume</option> ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			for _, l := range lang.Supported {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "<option value=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var3 string
+				templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(l.Code)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/tweak.templ`, Line: 81, Col: 32}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var4 string
+				templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(l.NativeName)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/tweak.templ`, Line: 81, Col: 49}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "</option>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "</select></div></div><div><div style=\"display: flex; justify-content: space-between; align-items: baseline; gap: var(--spacing-sm); margin-bottom: var(--spacing-xs);\"><label for=\"resume\" style=\"font-weight: 600; color: var(--color-slate);\">Your Resume</label> ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if hasProfile {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "<button type=\"button\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsOn("click", "$resume_before_upload = $resume; "+datastarPost("/app/profile/resume")))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsAttr("disabled", "$uploading || $loading"))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, " style=\"margin-left: auto; background: none; border: none; padding: 0; font-size: 0.875rem; color: var(--color-slate-light); text-decoration: underline; cursor: pointer;\">start from your profile</button> ")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "<label style=\"font-size: 0.875rem; color: var(--color-slate-light); cursor: pointer;\"><span data-show=\"!$uploading\">or upload a PDF, Word or JSON Resume file, or a LinkedIn data export</span> <span data-show=\"$uploading\">Reading file...</span> <input type=\"file\" name=\"file\" form=\"resume-upload\" accept=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var5 string
+			templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(strings.Join(extract.MimeTypes, ","))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/tweak.templ`, Line: 109, Col: 55}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsOn("change", "$resume_before_upload = $resume; $uploading = true; $upload_error = ''; "+datastarPostForm("/app/resumes/upload", "#resume-upload")))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsAttr("disabled", "$uploading || $loading"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, " style=\"display: none;\"></label></div><textarea id=\"resume\" name=\"resume\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsBind("resume"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, " rows=\"8\" class=\"input-field\" placeholder=\"Paste your current resume here...\" style=\"resize: vertical;\"></textarea><p data-show=\"$upload_error\" data-text=\"$upload_error\" style=\"font-size: 0.875rem; color: var(--color-text-error); margin-top: var(--spacing-xs);\"></p><div data-show=\"$upload_name\"><div id=\"upload-preview\"></div></div></div><div data-show=\"$mode != 'translate'\"><label for=\"job_description\" style=\"display: block; font-weight: 600; margin-bottom: var(--spacing-xs); color: var(--color-slate);\">Target Job Description</label><div style=\"display: flex; gap: var(--spacing-sm); margin-bottom: var(--spacing-xs);\"><input type=\"url\" id=\"job_url\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsBind("job_url"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, " class=\"input-field\" placeholder=\"or paste a link to the job posting\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsOn("keydown", "evt.key == 'Enter' && (evt.preventDefault(), $job_url && !$job_importing && ($job_importing = true, $job_import_error = '', $job_import_source = '', "+datastarPost("/app/job-descriptions/import")+"))"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, " style=\"flex: 1;\"> <button type=\"button\" class=\"btn-secondary\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsOn("click", "$job_importing = true; $job_import_error = ''; $job_import_source = ''; "+datastarPost("/app/job-descriptions/import")))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsAttr("disabled", "!$job_url || $job_importing || $loading"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 21, "><span data-show=\"!$job_importing\">Import</span> <span data-show=\"$job_importing\">Importing...</span></button></div><textarea id=\"job_description\" name=\"job_description\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsBind("job_description"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 22, " rows=\"5\" class=\"input-field\" placeholder=\"Paste the job description you're applying to...\" style=\"resize: vertical;\"></textarea><p data-show=\"$job_import_error\" data-text=\"$job_import_error\" style=\"font-size: 0.875rem; color: var(--color-text-error); margin-top: var(--spacing-xs);\"></p><p data-show=\"$job_import_source && !$job_import_error\" data-text=\"$job_import_source\" style=\"font-size: 0.875rem; color: var(--color-slate-light); margin-top: var(--spacing-xs); overflow-wrap: anywhere;\"></p>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = JobBoardImport(savedJobs).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "<p data-show=\"$resume_language && $job_language && $resume_language != $job_language && !$target_language\" style=\"font-size: 0.875rem; color: var(--color-slate-light); margin-top: var(--spacing-xs);\">This job description looks like it's in a different language from your resume. Pick an output language above to write the tailored resume in it.</p></div><label data-signals=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var6 string
+			templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(settingsSignals(prefs))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/tweak.templ`, Line: 176, Col: 44}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "\" style=\"display: flex; align-items: center; gap: var(--spacing-xs); font-size: 0.875rem; color: var(--color-slate-light);\"><input type=\"checkbox\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsBind("redact_pii"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "> Hide personal details (name, email, phone, address, profile links) from the AI</label><div style=\"display: flex; gap: var(--spacing-md); align-items: center;\"><button type=\"submit\" class=\"btn-primary\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsAttr("disabled", "$loading || $quota_remaining == 0"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 26, "><span data-show=\"!$loading && $mode != 'translate'\">Analyze & Tweak</span> <span data-show=\"!$loading && $mode == 'translate'\">Translate</span> <span data-show=\"$loading\" style=\"display: flex; align-items: center; gap: var(--spacing-xs);\"><span class=\"spinner\"></span> Processing...</span></button> <button type=\"button\" class=\"btn-secondary\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsOn("click", "$result = ''; $result_seq = 0; $error = ''; $step = 0;"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 27, " data-show=\"$result || $result_seq || $error\">Clear</button><!-- Remaining Quota --><span data-signals=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var7 string
+			templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(quotaSignals(status))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/tweak.templ`, Line: 206, Col: 43}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 28, "\" data-show=\"$quota_limit > 0\" style=\"margin-left: auto; font-size: 0.875rem; color: var(--color-slate-light);\"><span data-text=\"$quota_remaining + ' of ' + $quota_limit + ' tweaks left today'\"></span> <span data-show=\"$quota_remaining == 0\" data-text=\"' · resets ' + $quota_reset_at\"></span></span></div></form></div><!-- Error Display --><div data-show=\"$error\" class=\"card\" style=\"background-color: var(--color-bg-error); border-left: 3px solid var(--color-text-error); margin-bottom: var(--spacing-xl);\"><p style=\"font-weight: 600; color: var(--color-text-error); margin-bottom: var(--spacing-xs);\">Something went wrong</p><p style=\"color: var(--color-text-error);\" data-text=\"$error\"></p></div><!-- Queue Position --><div data-show=\"$loading && $queue_position > 0\" class=\"card\" style=\"margin-bottom: var(--spacing-xl); display: flex; align-items: center; gap: var(--spacing-sm);\"><span class=\"spinner\"></span> <span style=\"color: var(--color-slate-light);\">High demand right now. You're <strong data-text=\"'#' + $queue_position\"></strong> in the queue.</span></div><!-- Prompt Injection Warning --><div data-show=\"$injection_warning\" class=\"card\" style=\"background-color: var(--color-bg-warning); border-left: 3px solid var(--color-text-warning); margin-bottom: var(--spacing-xl);\"><p style=\"font-weight: 600; color: var(--color-text-warning); margin-bottom: var(--spacing-xs);\">Suspicious content detected</p><p style=\"color: var(--color-text-warning);\" data-text=\"$injection_warning\"></p></div><!-- Connection Lost --><div data-show=\"$stream_status == 'interrupted'\" class=\"card\" style=\"background-color: var(--color-bg-warning); margin-bottom: var(--spacing-xl); display: flex; align-items: center; justify-content: space-between; gap: var(--spacing-md);\"><p style=\"color: var(--color-text-warning);\">The connection dropped before your tweak finished. It's still running - reconnect to pick up where you left off.</p><button type=\"button\" class=\"btn-secondary\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsOn("click", datastarGetExpr("'/app/tweak/jobs/' + $job_id + '/stream?offset=' + $result_seq")))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 29, ">Reconnect</button></div><!-- Fallback Notice --><div data-show=\"$notice\" class=\"card\" style=\"background-color: var(--color-bg-neutral); border-left: 3px solid var(--color-sage); margin-bottom: var(--spacing-xl);\"><p style=\"color: var(--color-slate-light);\" data-text=\"$notice\"></p></div><!-- Trimmed Input --><details data-show=\"$trims.length > 0\" class=\"card\" style=\"margin-bottom: var(--spacing-xl);\"><summary style=\"cursor: pointer; color: var(--color-slate-light);\"><span data-text=\"'Your input was longer than the AI can read at once, so we trimmed ' + $trims.length + ' part(s)'\"></span></summary><p style=\"white-space: pre-line; font-size: 0.875rem; margin-top: var(--spacing-sm); color: var(--color-slate);\" data-text=\"$trims.map(t => (t.source == 'resume' ? 'Resume: ' : 'Job description: ') + t.description + ' (~' + t.tokens_saved + ' tokens)').join('\\n')\"></p></details><!-- Redacted Details --><details data-show=\"$redactions.length > 0\" class=\"card\" style=\"margin-bottom: var(--spacing-xl);\"><summary style=\"cursor: pointer; color: var(--color-slate-light);\"><span data-text=\"$redactions.length + ' personal detail(s) were hidden from the AI and restored in your result'\"></span></summary><p style=\"white-space: pre-line; font-size: 0.875rem; margin-top: var(--spacing-sm); color: var(--color-slate);\" data-text=\"$redactions.map(r => r.value + '  →  ' + r.token).join('\\n')\"></p></details><!-- Progress Steps --><div data-show=\"$loading || $result || $result_seq\" style=\"margin-bottom: var(--spacing-xl);\"><h3 style=\"font-family: var(--font-serif); font-size: 1.125rem; margin-bottom: var(--spacing-md);\">Progress</h3><div style=\"display: flex; flex-direction: column; gap: var(--spacing-sm);\"><div class=\"progress-item\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsClass("completed", "$step >= 1"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 30, "><span class=\"progress-icon\"><span data-show=\"$step < 1\">○</span> <span data-show=\"$step >= 1\">✓</span></span> <span>Analyzing your resume</span></div><div class=\"progress-item\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsClass("completed", "$step >= 2"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 31, "><span class=\"progress-icon\"><span data-show=\"$step < 2\">○</span> <span data-show=\"$step >= 2\">✓</span></span> <span>Parsing job requirements</span></div><div class=\"progress-item\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsClass("completed", "$step >= 3"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 32, "><span class=\"progress-icon\"><span data-show=\"$step < 3\">○</span> <span data-show=\"$step >= 3\">✓</span></span> <span>Identifying alignment opportunities</span></div><div class=\"progress-item\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsClass("completed", "$step >= 4"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 33, "><span class=\"progress-icon\"><span data-show=\"$step < 4\">○</span> <span data-show=\"$step >= 4\">✓</span></span> <span>Generating suggestions</span></div></div></div><!-- Streaming Result --><div data-show=\"$result || $result_seq\" class=\"card\"><div style=\"display: flex; align-items: center; justify-content: space-between; margin-bottom: var(--spacing-md);\"><h3 style=\"font-family: var(--font-serif); font-size: 1.125rem;\">Suggestions</h3><div style=\"display: flex; gap: var(--spacing-sm);\"><span class=\"badge\" data-show=\"!$loading && $served_by\" data-text=\"$served_by == 'claude-sonnet' ? 'Claude Sonnet' : $served_by == 'claude-haiku' ? 'Claude Haiku (backup)' : $served_by == 'offline' ? 'Offline suggestions' : 'Demo'\"></span> <span class=\"badge badge-success\" data-show=\"!$loading\">Complete</span> <span class=\"badge badge-warning\" data-show=\"$loading\">Streaming...</span> <button class=\"btn-secondary\" style=\"padding: var(--spacing-xs) var(--spacing-sm); font-size: 0.875rem;\" data-show=\"!$loading\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsOn("click", "navigator.clipboard.writeText($result); this.textContent = 'Copied!'; setTimeout(() => this.textContent = 'Copy', 2000)"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 34, ">Copy</button></div></div><div style=\"background-color: var(--color-bg-neutral); border-radius: var(--border-radius); padding: var(--spacing-md);\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = ResultView("", 0).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 35, "<span class=\"streaming-cursor\" data-show=\"$loading\"></span></div><!-- Export --><div data-signals=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var8 string
+			templ_7745c5c3_Var8, templ_7745c5c3_Err = templ.JoinStringErrs(exportSignals(prefs))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/tweak.templ`, Line: 377, Col: 41}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var8))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 36, "\" data-show=\"$resume_id && !$loading\" style=\"display: flex; gap: var(--spacing-sm); align-items: center; flex-wrap: wrap; margin-top: var(--spacing-md);\"><select")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsBind("export_template"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 37, " class=\"input-field\" style=\"width: auto;\" aria-label=\"Template\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			for _, t := range export.Templates {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 38, "<option value=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var9 string
+				templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(t.Name)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/tweak.templ`, Line: 383, Col: 30}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 39, "\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var10 string
+				templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(t.Label)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/tweak.templ`, Line: 383, Col: 42}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 40, "</option>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 41, "</select> <select")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsBind("export_size"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 42, " class=\"input-field\" style=\"width: auto;\" aria-label=\"Page size\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			for _, size := range export.PageSizes {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 43, "<option value=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var11 string
+				templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.JoinStringErrs(size.Name)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/tweak.templ`, Line: 388, Col: 33}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 44, "\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var12 string
+				templ_7745c5c3_Var12, templ_7745c5c3_Err = templ.JoinStringErrs(size.Label)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/tweak.templ`, Line: 388, Col: 48}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var12))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 45, "</option>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 46, "</select> <a class=\"btn-secondary\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsAttr("href", "'/app/resumes/' + $resume_id + '/export.pdf?template=' + $export_template + '&size=' + $export_size"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 47, ">Download PDF</a> <a class=\"btn-secondary\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsAttr("href", "'/app/resumes/' + $resume_id + '/export.docx?template=' + $export_template + '&size=' + $export_size"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 48, ">Download Word</a> <a class=\"btn-secondary\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsAttr("href", "'/app/resumes/' + $resume_id + '/export.json'"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 49, " title=\"JSON Resume (jsonresume.org)\">Download JSON Resume</a></div><div data-show=\"$resume_id && !$loading\" style=\"display: flex; gap: var(--spacing-sm); align-items: center; flex-wrap: wrap; margin-top: var(--spacing-sm);\"><select")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsBind("latex_template"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 50, " class=\"input-field\" style=\"width: auto;\" aria-label=\"LaTeX template\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			for _, t := range export.LaTeXTemplates {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 51, "<option value=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var13 string
+				templ_7745c5c3_Var13, templ_7745c5c3_Err = templ.JoinStringErrs(t.Name)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/tweak.templ`, Line: 417, Col: 30}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var13))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 52, "\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var14 string
+				templ_7745c5c3_Var14, templ_7745c5c3_Err = templ.JoinStringErrs(t.Label)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/tweak.templ`, Line: 417, Col: 42}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var14))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 53, "</option>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 54, "</select> <a class=\"btn-secondary\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsAttr("href", "'/app/resumes/' + $resume_id + '/export.tex?template=' + $latex_template + '&size=' + $export_size"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 55, ">Download LaTeX</a> <a class=\"btn-secondary\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, dsAttr("href", "'/app/resumes/' + $resume_id + '/export.zip?template=' + $latex_template + '&size=' + $export_size"))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 56, " title=\"The .tex file with its class file alongside\">LaTeX + class (.zip)</a></div></div></div></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
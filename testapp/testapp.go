@@ -0,0 +1,48 @@
+// Package testapp builds PocketBase apps for tests. It is only imported
+// from _test.go files.
+package testapp
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+// users numbers the users created across tests, keeping emails unique
+var users atomic.Int64
+
+// New returns a test app, removed when the test ends, after running each
+// setup on it (usually a package's SetupCollections)
+func New(t testing.TB, setups ...func(core.App) error) *tests.TestApp {
+	t.Helper()
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(app.Cleanup)
+	for _, setup := range setups {
+		if err := setup(app); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return app
+}
+
+// User creates a user in app
+func User(t testing.TB, app core.App) *core.Record {
+	t.Helper()
+	collection, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := core.NewRecord(collection)
+	user.SetEmail(fmt.Sprintf("user%d@example.com", users.Add(1)))
+	user.SetPassword("password123")
+	if err := app.Save(user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}